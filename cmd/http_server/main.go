@@ -32,6 +32,34 @@ func main() {
 
 	go application.HTTPSrv.MustRun(ctx)
 
+	if application.GRPCSrv != nil {
+		go application.GRPCSrv.MustRun()
+	}
+
+	if application.Autoscaler != nil {
+		go application.Autoscaler.Run(ctx)
+	}
+
+	go application.RunKeyRefresh(ctx)
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	go func() {
+		for range reload {
+			log.Info("received reload signal")
+
+			newCfg, err := cfg.Reload()
+			if err != nil {
+				log.Error("failed to reload config", "error", err)
+				continue
+			}
+
+			application.Reload(newCfg)
+		}
+	}()
+
 	<-ctx.Done()
 
 	log.Info("received signal stop signal")
@@ -39,7 +67,7 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	application.HTTPSrv.Stop(shutdownCtx)
+	application.Stop(shutdownCtx)
 
 	log.Info("stopped Task Manager API application")
 }
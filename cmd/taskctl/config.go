@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// config holds the connection settings. They are read from the config file,
+// overridden by the environment and then by the global flags.
+type config struct {
+	Server    string `yaml:"server" env:"TASKCTL_SERVER"`
+	APIKey    string `yaml:"api_key" env:"TASKCTL_API_KEY"`
+	Token     string `yaml:"token" env:"TASKCTL_TOKEN"`
+	Namespace string `yaml:"namespace" env:"TASKCTL_NAMESPACE"`
+}
+
+// globalFlags are accepted by every command.
+type globalFlags struct {
+	configPath string
+	server     string
+	apiKey     string
+	token      string
+	namespace  string
+}
+
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.configPath, "config", "", "config file (env TASKCTL_CONFIG, default "+displayConfigPath()+")")
+	fs.StringVar(&g.server, "server", "", "server URL (env TASKCTL_SERVER, default "+defaultServer+")")
+	fs.StringVar(&g.apiKey, "api-key", "", "API key (env TASKCTL_API_KEY)")
+	fs.StringVar(&g.token, "token", "", "bearer token (env TASKCTL_TOKEN)")
+	fs.StringVar(&g.namespace, "namespace", "", "namespace of the tasks (env TASKCTL_NAMESPACE)")
+}
+
+// loadConfig reads the config file and applies the environment and the flags.
+// A missing config file is only an error if its path was given explicitly.
+func loadConfig(flags globalFlags) (config, error) {
+	const op = "loadConfig"
+
+	path, explicit := flags.configPath, true
+	if path == "" {
+		path = os.Getenv("TASKCTL_CONFIG")
+	}
+	if path == "" {
+		path, explicit = defaultConfigPath(), false
+	}
+
+	if _, err := os.Stat(path); !explicit && errors.Is(err, fs.ErrNotExist) {
+		path = ""
+	}
+
+	var cfg config
+	var err error
+	if path != "" {
+		// Reads the environment too, it takes precedence over the file.
+		err = cleanenv.ReadConfig(path, &cfg)
+	} else {
+		err = cleanenv.ReadEnv(&cfg)
+	}
+	if err != nil {
+		return config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if flags.server != "" {
+		cfg.Server = flags.server
+	}
+	if flags.apiKey != "" {
+		cfg.APIKey = flags.apiKey
+	}
+	if flags.token != "" {
+		cfg.Token = flags.token
+	}
+	if flags.namespace != "" {
+		cfg.Namespace = flags.namespace
+	}
+	if cfg.Server == "" {
+		cfg.Server = defaultServer
+	}
+
+	return cfg, nil
+}
+
+// defaultConfigPath returns the path of the config file in the user config directory,
+// or an empty string if the directory is unknown.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "taskctl", "config.yml")
+}
+
+func displayConfigPath() string {
+	if path := defaultConfigPath(); path != "" {
+		return path
+	}
+	return "none"
+}
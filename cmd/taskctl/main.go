@@ -0,0 +1,210 @@
+package main
+
+// Command taskctl operates the task manager through its HTTP API.
+//
+//	taskctl <command> [flags] [arguments]
+//
+// The exit code of commands waiting for a task follows its outcome:
+// 0 if it completed, 3 if it failed, 4 if it was canceled
+// and 5 if waiting timed out. Other errors exit with 1, usage errors with 2.
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/passwordhash/task-manager-api/pkg/client"
+)
+
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitFailed   = 3
+	exitCanceled = 4
+	exitTimeout  = 5
+)
+
+// errInvalidFlags is returned for flags the flag package has already reported.
+var errInvalidFlags = errors.New("invalid flags")
+
+// usageError is returned for invalid arguments of a command.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// outcomeError is returned when a waited task did not complete.
+type outcomeError struct {
+	uuid   string
+	status client.Status
+}
+
+func (e *outcomeError) Error() string {
+	return fmt.Sprintf("task %s is %s", e.uuid, e.status)
+}
+
+// cli holds the streams of a run of the command.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, cli *cli, args []string) error
+}
+
+var commands []command
+
+func init() {
+	// Assigned in init, since the commands refer to the list through newFlagSet.
+	commands = []command{
+		{"create", "[--type TYPE] [--payload JSON|@FILE|-] [--wait]", "create a task and print its UUID", runCreate},
+		{"get", "UUID", "print a task", runGet},
+		{"list", "[--status STATUS] [-l SELECTOR]", "list tasks", runList},
+		{"cancel", "UUID... | -l SELECTOR", "cancel tasks", runCancel},
+		{"wait", "UUID [--timeout DURATION]", "wait for a task to finish", runWait},
+		{"logs", "UUID [-f]", "print log lines of a running task", runLogs},
+		{"pool", "[pause|resume|resize WORKERS] [--group GROUP]", "show or control worker pools (admin)", runPool},
+	}
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	os.Exit(run(ctx, os.Args[1:], &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run runs the command line and returns the exit code.
+func run(ctx context.Context, args []string, cli *cli) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(cli.stdout)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return exitCode(cli, cmd.run(ctx, cli, args[1:]))
+		}
+	}
+
+	fmt.Fprintf(cli.stderr, "taskctl: unknown command %q\n\n", args[0])
+	printUsage(cli.stderr)
+	return exitUsage
+}
+
+// exitCode reports the error of the command and returns the exit code for it.
+func exitCode(cli *cli, err error) int {
+	var (
+		usageErr   *usageError
+		outcomeErr *outcomeError
+	)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errInvalidFlags):
+		return exitUsage
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(cli.stderr, "taskctl: %v\n", err)
+		return exitUsage
+	case errors.As(err, &outcomeErr):
+		if outcomeErr.status == client.StatusCanceled {
+			return exitCanceled
+		}
+		return exitFailed
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintln(cli.stderr, "taskctl: timed out")
+		return exitTimeout
+	default:
+		fmt.Fprintf(cli.stderr, "taskctl: %v\n", err)
+		return exitError
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, "Usage: taskctl <command> [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(w, "\nRun 'taskctl <command> -h' for the flags of a command.\n")
+	fmt.Fprint(w, "\nExit codes: 0 success, 1 error, 2 usage error, 3 task failed, 4 task canceled, 5 timed out.\n")
+}
+
+// newFlagSet returns the flag set of the command with the global flags registered.
+func newFlagSet(cli *cli, name string, global *globalFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(cli.stderr)
+	global.register(fs)
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			fs.Usage = func() {
+				fmt.Fprintf(cli.stderr, "Usage: taskctl %s %s\n\nFlags:\n", cmd.name, cmd.args)
+				fs.PrintDefaults()
+			}
+		}
+	}
+
+	return fs
+}
+
+// parseFlags parses flags mixed with positional arguments
+// and returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errInvalidFlags
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// newClient returns the client configured by the config file, the environment and the flags.
+func newClient(global globalFlags) (*client.Client, error) {
+	cfg, err := loadConfig(global)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []client.Option
+	if cfg.APIKey != "" {
+		opts = append(opts, client.WithAPIKey(cfg.APIKey))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, client.WithBearerToken(cfg.Token))
+	}
+	if cfg.Namespace != "" {
+		opts = append(opts, client.WithNamespace(cfg.Namespace))
+	}
+
+	return client.New(cfg.Server, opts...)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	data := "server: http://file:8080\napi_key: file-key\nnamespace: file-ns\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TASKCTL_CONFIG", path)
+	t.Setenv("TASKCTL_API_KEY", "env-key")
+	t.Setenv("TASKCTL_NAMESPACE", "env-ns")
+
+	cfg, err := loadConfig(globalFlags{namespace: "flag-ns"})
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	want := config{Server: "http://file:8080", APIKey: "env-key", Namespace: "flag-ns"}
+	if cfg != want {
+		t.Errorf("loadConfig = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	cfg, err := loadConfig(globalFlags{})
+	if err != nil {
+		t.Fatalf("loadConfig without a config file: %v", err)
+	}
+	if cfg.Server != defaultServer {
+		t.Errorf("server is %q, want %q", cfg.Server, defaultServer)
+	}
+
+	if _, err := loadConfig(globalFlags{configPath: filepath.Join(t.TempDir(), "missing.yml")}); err == nil {
+		t.Error("loadConfig accepted a missing config file given explicitly")
+	}
+}
+
+func TestReadPayload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payload.json")
+	if err := os.WriteFile(path, []byte(`{"day":"2025-01-01"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value   string
+		stdin   string
+		want    string
+		wantErr bool
+	}{
+		{value: `{"a":1}`, want: `{"a":1}`},
+		{value: "@" + path, want: `{"day":"2025-01-01"}`},
+		{value: "-", stdin: `[1,2]`, want: `[1,2]`},
+		{value: "{not json", wantErr: true},
+		{value: "@" + path + ".missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := readPayload(tt.value, strings.NewReader(tt.stdin))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("readPayload(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && string(got) != tt.want {
+			t.Errorf("readPayload(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	// The server finishes tasks named after the status they should get.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.URL.Path, "/")
+		uuid := parts[4]
+		if uuid == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code":"not_found"}`)
+			return
+		}
+
+		switch parts[len(parts)-1] {
+		case "events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: status\ndata: {\"type\":\"status\",\"status\":%q}\n\n", uuid)
+		case "status":
+			fmt.Fprintf(w, `{"uuid":%q,"status":%q}`, uuid, uuid)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("TASKCTL_CONFIG", "")
+	t.Setenv("TASKCTL_SERVER", srv.URL)
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{args: []string{"wait", "completed"}, want: exitOK},
+		{args: []string{"wait", "failed"}, want: exitFailed},
+		{args: []string{"wait", "canceled", "-o", "json"}, want: exitCanceled},
+		{args: []string{"logs", "failed"}, want: exitFailed},
+		{args: []string{"get", "failed"}, want: exitOK},
+		{args: []string{"get", "missing"}, want: exitError},
+		{args: []string{"get"}, want: exitUsage},
+		{args: []string{"get", "completed", "-o", "yaml"}, want: exitUsage},
+		{args: []string{"unknown"}, want: exitUsage},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		got := run(context.Background(), tt.args, &cli{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr})
+		if got != tt.want {
+			t.Errorf("taskctl %s exited with %d, want %d; stderr: %s", strings.Join(tt.args, " "), got, tt.want, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/pkg/client"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func checkOutput(output string) error {
+	if output != outputTable && output != outputJSON {
+		return usagef("unknown output format %q, want table or json", output)
+	}
+	return nil
+}
+
+// taskJSON is a task in the json output format, it matches
+// the status response of the API.
+type taskJSON struct {
+	UUID      string        `json:"uuid"`
+	Namespace string        `json:"namespace,omitempty"`
+	Type      string        `json:"type"`
+	Tenant    string        `json:"tenant"`
+	Owner     string        `json:"owner,omitempty"`
+	Status    client.Status `json:"status"`
+	CreatedAt time.Time     `json:"created_at"`
+	Duration  string        `json:"duration,omitempty"`
+	Result    any           `json:"result,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	RetryOf   string        `json:"retry_of,omitempty"`
+	Retries   []string      `json:"retries,omitempty"`
+}
+
+func newTaskJSON(task client.Task) taskJSON {
+	out := taskJSON{
+		UUID:      task.UUID,
+		Namespace: task.Namespace,
+		Type:      task.Type,
+		Tenant:    task.Tenant,
+		Owner:     task.Owner,
+		Status:    task.Status,
+		CreatedAt: task.CreatedAt,
+		Result:    task.Result,
+		Error:     task.Error,
+		RetryOf:   task.RetryOf,
+		Retries:   task.Retries,
+	}
+	if task.Duration > 0 {
+		out.Duration = task.Duration.String()
+	}
+	return out
+}
+
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printTask prints the fields of the task one per line, or as a JSON object.
+func printTask(w io.Writer, task *client.Task, output string) error {
+	if output == outputJSON {
+		return printJSON(w, newTaskJSON(*task))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", name, value)
+		}
+	}
+	field("UUID", task.UUID)
+	field("Namespace", task.Namespace)
+	field("Type", task.Type)
+	field("Tenant", task.Tenant)
+	field("Owner", task.Owner)
+	field("Status", string(task.Status))
+	field("Created", task.CreatedAt.Format(time.RFC3339))
+	if task.Duration > 0 {
+		field("Duration", task.Duration.String())
+	}
+	if task.Result != nil {
+		result, err := json.Marshal(task.Result)
+		if err != nil {
+			return err
+		}
+		field("Result", string(result))
+	}
+	field("Error", task.Error)
+	field("Retry of", task.RetryOf)
+	field("Retries", strings.Join(task.Retries, ", "))
+
+	return tw.Flush()
+}
+
+// printTasks prints a table of the tasks, or a JSON array.
+func printTasks(w io.Writer, tasks []client.Task, output string) error {
+	if output == outputJSON {
+		out := make([]taskJSON, 0, len(tasks))
+		for _, task := range tasks {
+			out = append(out, newTaskJSON(task))
+		}
+		return printJSON(w, out)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "UUID\tTYPE\tTENANT\tSTATUS\tCREATED")
+	for _, task := range tasks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			task.UUID, task.Type, task.Tenant, task.Status, task.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+// printPools prints a table of the pool states, or a JSON array.
+func printPools(w io.Writer, states []client.PoolState, output string) error {
+	if output == outputJSON {
+		return printJSON(w, states)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tPAUSED\tWORKERS\tBUSY\tQUEUED")
+	for _, state := range states {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\n",
+			state.Group, strconv.FormatBool(state.Paused), state.Workers, state.BusyWorkers, state.Queued)
+	}
+	return tw.Flush()
+}
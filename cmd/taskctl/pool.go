@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/passwordhash/task-manager-api/pkg/client"
+)
+
+func runPool(ctx context.Context, cli *cli, args []string) error {
+	var global globalFlags
+	fs := newFlagSet(cli, "pool", &global)
+	group := fs.String("group", "", "worker group, all groups or the default one if empty")
+	output := fs.String("o", "table", "output format: table or json")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	action := ""
+	if len(positional) > 0 {
+		action, positional = positional[0], positional[1:]
+	}
+
+	var workers int
+	switch action {
+	case "", "pause", "resume":
+		if len(positional) > 0 {
+			return usagef("pool %s takes no arguments", action)
+		}
+	case "resize":
+		if len(positional) != 1 {
+			return usagef("pool resize takes the number of workers")
+		}
+		if workers, err = strconv.Atoi(positional[0]); err != nil || workers < 1 {
+			return usagef("number of workers must be a positive integer")
+		}
+	default:
+		return usagef("unknown pool action %q, want pause, resume or resize", action)
+	}
+
+	c, err := newClient(global)
+	if err != nil {
+		return err
+	}
+
+	var state *client.PoolState
+	switch action {
+	case "":
+		if *group == "" {
+			states, err := c.Pools(ctx)
+			if err != nil {
+				return err
+			}
+			return printPools(cli.stdout, states, *output)
+		}
+		state, err = c.Pool(ctx, *group)
+	case "pause":
+		state, err = c.PausePool(ctx, *group)
+	case "resume":
+		state, err = c.ResumePool(ctx, *group)
+	case "resize":
+		state, err = c.ResizePool(ctx, *group, workers)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printPools(cli.stdout, []client.PoolState{*state}, *output)
+}
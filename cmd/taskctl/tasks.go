@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/pkg/client"
+)
+
+// listPageSize is the number of tasks list fetches per request.
+const listPageSize = 500
+
+// reconnectDelay is the delay before logs -f follows the events again.
+const reconnectDelay = time.Second
+
+func runCreate(ctx context.Context, cli *cli, args []string) error {
+	var global globalFlags
+	fs := newFlagSet(cli, "create", &global)
+	taskType := fs.String("type", "", "type of the task, routes it to a worker group")
+	payload := fs.String("payload", "", "JSON payload, @FILE to read it from a file or - from stdin")
+	tenant := fs.String("tenant", "", "tenant of the task when authentication is disabled")
+	idempotencyKey := fs.String("idempotency-key", "", "makes repeating the command safe")
+	wait := fs.Bool("wait", false, "wait for the task to finish and exit with its outcome")
+	timeout := fs.Duration("timeout", 0, "how long to wait with --wait, forever if zero")
+	output := fs.String("o", "table", "output format of --wait: table or json")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("create takes no arguments")
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	req := client.CreateRequest{Type: *taskType, Tenant: *tenant, IdempotencyKey: *idempotencyKey}
+	if *payload != "" {
+		if req.Payload, err = readPayload(*payload, cli.stdin); err != nil {
+			return err
+		}
+	}
+
+	c, err := newClient(global)
+	if err != nil {
+		return err
+	}
+
+	uuid, err := c.Create(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !*wait {
+		fmt.Fprintln(cli.stdout, uuid)
+		return nil
+	}
+
+	return waitTask(ctx, cli, c, uuid, *timeout, *output)
+}
+
+// readPayload returns the JSON payload of the flag value:
+// inline JSON, @FILE or - for stdin.
+func readPayload(value string, stdin io.Reader) (json.RawMessage, error) {
+	var data []byte
+	var err error
+	switch {
+	case value == "-":
+		data, err = io.ReadAll(stdin)
+	case strings.HasPrefix(value, "@"):
+		data, err = os.ReadFile(strings.TrimPrefix(value, "@"))
+	default:
+		data = []byte(value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read payload: %w", err)
+	}
+	if !json.Valid(data) {
+		return nil, usagef("payload is not valid JSON")
+	}
+
+	return json.RawMessage(data), nil
+}
+
+func runGet(ctx context.Context, cli *cli, args []string) error {
+	var global globalFlags
+	fs := newFlagSet(cli, "get", &global)
+	output := fs.String("o", "table", "output format: table or json")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usagef("get takes the UUID of a task")
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	c, err := newClient(global)
+	if err != nil {
+		return err
+	}
+
+	task, err := c.Get(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+
+	return printTask(cli.stdout, task, *output)
+}
+
+func runList(ctx context.Context, cli *cli, args []string) error {
+	var global globalFlags
+	fs := newFlagSet(cli, "list", &global)
+	status := fs.String("status", "", "list only tasks with the status")
+	selector := fs.String("l", "", "list only tasks matching the label selector, e.g. env=prod,!draft")
+	output := fs.String("o", "table", "output format: table or json")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("list takes no arguments")
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	c, err := newClient(global)
+	if err != nil {
+		return err
+	}
+
+	var tasks []client.Task
+	opts := client.ListOptions{Selector: *selector, Limit: listPageSize}
+	for {
+		page, err := c.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for _, task := range page.Tasks {
+			if *status == "" || task.Status == client.Status(*status) {
+				tasks = append(tasks, task)
+			}
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = page.NextPageToken
+	}
+
+	return printTasks(cli.stdout, tasks, *output)
+}
+
+func runCancel(ctx context.Context, cli *cli, args []string) error {
+	var global globalFlags
+	fs := newFlagSet(cli, "cancel", &global)
+	selector := fs.String("l", "", "cancel the tasks matching the label selector instead")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if (len(positional) == 0) == (*selector == "") {
+		return usagef("cancel takes UUIDs of tasks or a label selector")
+	}
+
+	c, err := newClient(global)
+	if err != nil {
+		return err
+	}
+
+	if *selector != "" {
+		uuids, err := c.CancelAll(ctx, *selector)
+		for _, uuid := range uuids {
+			fmt.Fprintln(cli.stdout, uuid)
+		}
+		return err
+	}
+
+	// Cancel all tasks even if some of them fail.
+	var errs []error
+	for _, uuid := range positional {
+		if err := c.Cancel(ctx, uuid); err != nil {
+			errs = append(errs, fmt.Errorf("cancel %s: %w", uuid, err))
+			continue
+		}
+		fmt.Fprintln(cli.stdout, uuid)
+	}
+
+	return errors.Join(errs...)
+}
+
+func runWait(ctx context.Context, cli *cli, args []string) error {
+	var global globalFlags
+	fs := newFlagSet(cli, "wait", &global)
+	timeout := fs.Duration("timeout", 0, "how long to wait, forever if zero")
+	output := fs.String("o", "table", "output format: table or json")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usagef("wait takes the UUID of a task")
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	c, err := newClient(global)
+	if err != nil {
+		return err
+	}
+
+	return waitTask(ctx, cli, c, positional[0], *timeout, *output)
+}
+
+// waitTask waits for the task, prints it and returns
+// an *outcomeError if it did not complete.
+func waitTask(ctx context.Context, cli *cli, c *client.Client, uuid string, timeout time.Duration, output string) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	task, err := c.Wait(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if err := printTask(cli.stdout, task, output); err != nil {
+		return err
+	}
+
+	return taskOutcome(task.UUID, task.Status)
+}
+
+func taskOutcome(uuid string, status client.Status) error {
+	if status == client.StatusCompleted {
+		return nil
+	}
+	return &outcomeError{uuid: uuid, status: status}
+}
+
+func runLogs(ctx context.Context, cli *cli, args []string) error {
+	var global globalFlags
+	fs := newFlagSet(cli, "logs", &global)
+	follow := fs.Bool("f", false, "keep following the task if the event stream breaks")
+	progress := fs.Bool("progress", false, "print progress events too")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usagef("logs takes the UUID of a task")
+	}
+
+	c, err := newClient(global)
+	if err != nil {
+		return err
+	}
+
+	// The server does not keep log lines, they are printed
+	// as the task writes them until it finishes.
+	uuid := positional[0]
+	for {
+		status, err := printLogs(ctx, cli.stdout, c, uuid, *progress)
+		if err != nil {
+			return err
+		}
+		if status.IsTerminal() {
+			return taskOutcome(uuid, status)
+		}
+		if !*follow {
+			return fmt.Errorf("event stream of task %s ended before the task finished", uuid)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// printLogs prints the log lines of the task until the event
+// stream ends and returns the last status of the task.
+func printLogs(ctx context.Context, w io.Writer, c *client.Client, uuid string, progress bool) (client.Status, error) {
+	stream, err := c.Watch(ctx, uuid)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var status client.Status
+	for stream.Next() {
+		event := stream.Event()
+		switch event.Type {
+		case client.EventStatus:
+			status = event.Status
+		case client.EventLog:
+			fmt.Fprintf(w, "%s %s\n", event.Time.Format(time.RFC3339), event.Message)
+		case client.EventProgress:
+			if progress {
+				fmt.Fprintf(w, "%s progress %d%%\n", event.Time.Format(time.RFC3339), event.Progress)
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	return status, nil
+}
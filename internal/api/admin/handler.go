@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+type handler struct {
+	pools worker.PoolGroups
+}
+
+func NewHandler(
+	pools worker.PoolGroups,
+) *handler {
+	return &handler{
+		pools: pools,
+	}
+}
+
+func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
+	// /pool is a shortcut for the default worker group.
+	poolGroup := router.Group("/pool")
+	{
+		h.registerPoolRoutes(poolGroup)
+	}
+
+	groupsGroup := router.Group("/groups")
+	{
+		groupsGroup.GET("", h.listGroups)
+
+		groupGroup := groupsGroup.Group("/:group")
+		{
+			h.registerPoolRoutes(groupGroup)
+		}
+	}
+}
+
+func (h *handler) registerPoolRoutes(router *gin.RouterGroup) {
+	router.GET("", h.poolState)
+	router.PUT("", h.resize)
+	router.POST("/pause", h.pause)
+	router.POST("/resume", h.resume)
+}
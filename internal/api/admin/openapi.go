@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/passwordhash/task-manager-api/internal/api/openapi"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+)
+
+const tag = "admin"
+
+// Routes describes the routes registered by RegisterRoutes
+// on the group with the base path.
+func Routes(base string) []openapi.Route {
+	routes := poolRoutes(base+"/pool", "DefaultPool", nil)
+	routes = append(routes, openapi.Route{
+		Method: http.MethodGet, Path: base + "/groups", ID: "listGroups", Tag: tag,
+		Summary:  "List worker groups",
+		Scope:    string(auth.ScopeAdmin),
+		Response: listGroupsResponse{},
+	})
+
+	groupParam := openapi.Parameter{
+		Name:     "group",
+		In:       "path",
+		Required: true,
+		Schema:   &openapi.Schema{Type: "string"},
+	}
+	return append(routes, poolRoutes(base+"/groups/:group", "Group", &groupParam)...)
+}
+
+// poolRoutes describes the routes of registerPoolRoutes. The suffix
+// makes operation IDs unique, groupParam is nil for the default group.
+func poolRoutes(base, suffix string, groupParam *openapi.Parameter) []openapi.Route {
+	var params []openapi.Parameter
+	var problems []response.ProblemType
+	if groupParam != nil {
+		params = append(params, *groupParam)
+		problems = append(problems, response.ProblemNotFound)
+	}
+
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: base, ID: "getPoolState" + suffix, Tag: tag,
+			Summary:    "Get the state of the worker pool",
+			Scope:      string(auth.ScopeAdmin),
+			Parameters: params,
+			Response:   poolStateResponse{},
+			Problems:   problems,
+		},
+		{
+			Method: http.MethodPut, Path: base, ID: "resizePool" + suffix, Tag: tag,
+			Summary:     "Change the number of workers",
+			Description: "Extra workers stop after finishing their current task.",
+			Scope:       string(auth.ScopeAdmin),
+			Parameters:  params,
+			Request:     resizeRequest{},
+			Response:    poolStateResponse{},
+			Problems:    append([]response.ProblemType{response.ProblemBadRequest, response.ProblemValidation}, problems...),
+		},
+		{
+			Method: http.MethodPost, Path: base + "/pause", ID: "pausePool" + suffix, Tag: tag,
+			Summary:     "Pause taking tasks from the queue",
+			Description: "Running tasks are not interrupted and new tasks are still accepted.",
+			Scope:       string(auth.ScopeAdmin),
+			Parameters:  params,
+			Response:    poolStateResponse{},
+			Problems:    problems,
+		},
+		{
+			Method: http.MethodPost, Path: base + "/resume", ID: "resumePool" + suffix, Tag: tag,
+			Summary:    "Resume taking tasks from the queue",
+			Scope:      string(auth.ScopeAdmin),
+			Parameters: params,
+			Response:   poolStateResponse{},
+			Problems:   problems,
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/request"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+type poolStateResponse struct {
+	Group         string         `json:"group"`
+	Paused        bool           `json:"paused"`
+	Workers       int            `json:"workers"`
+	BusyWorkers   int            `json:"busy_workers"`
+	Queued        int            `json:"queued"`
+	TypeLimits    map[string]int `json:"type_limits"`
+	RunningByType map[string]int `json:"running_by_type"`
+
+	QueuedByTenant  map[string]int `json:"queued_by_tenant"`
+	RunningByTenant map[string]int `json:"running_by_tenant"`
+}
+
+func newPoolStateResponse(group string, state worker.PoolState) poolStateResponse {
+	return poolStateResponse{
+		Group:         group,
+		Paused:        state.Paused,
+		Workers:       state.Workers,
+		BusyWorkers:   state.Busy,
+		Queued:        state.Queued,
+		TypeLimits:    nonNil(state.TypeLimits),
+		RunningByType: nonNil(state.RunningByType),
+
+		QueuedByTenant:  nonNil(state.QueuedByTenant),
+		RunningByTenant: nonNil(state.RunningByTenant),
+	}
+}
+
+func nonNil(m map[string]int) map[string]int {
+	if m == nil {
+		return map[string]int{}
+	}
+	return m
+}
+
+type listGroupsResponse struct {
+	Groups []poolStateResponse `json:"groups"`
+}
+
+func (h *handler) listGroups(c *gin.Context) {
+	names := h.pools.Names()
+
+	groups := make([]poolStateResponse, 0, len(names))
+	for _, name := range names {
+		groupPool, _ := h.pools.Get(name)
+		groups = append(groups, newPoolStateResponse(name, groupPool.State()))
+	}
+
+	response.NewOk(c, listGroupsResponse{Groups: groups})
+}
+
+// groupPool returns the pool of the group from the path,
+// or of the default group for the /pool shortcut.
+// It responds with 404 and returns false if there is no such group.
+func (h *handler) groupPool(c *gin.Context) (string, worker.TaskPool, bool) {
+	name := c.Param("group")
+	if name == "" {
+		name = worker.DefaultGroup
+	}
+
+	groupPool, ok := h.pools.Get(name)
+	if !ok {
+		response.NewErr(c, response.ProblemNotFound, "Worker group not found")
+		return "", nil, false
+	}
+
+	return name, groupPool, true
+}
+
+func (h *handler) poolState(c *gin.Context) {
+	name, groupPool, ok := h.groupPool(c)
+	if !ok {
+		return
+	}
+
+	response.NewOk(c, newPoolStateResponse(name, groupPool.State()))
+}
+
+func (h *handler) pause(c *gin.Context) {
+	name, groupPool, ok := h.groupPool(c)
+	if !ok {
+		return
+	}
+
+	groupPool.Pause()
+
+	response.NewOk(c, newPoolStateResponse(name, groupPool.State()))
+}
+
+func (h *handler) resume(c *gin.Context) {
+	name, groupPool, ok := h.groupPool(c)
+	if !ok {
+		return
+	}
+
+	groupPool.Resume()
+
+	response.NewOk(c, newPoolStateResponse(name, groupPool.State()))
+}
+
+type resizeRequest struct {
+	Workers int `json:"workers" binding:"required,min=1"`
+}
+
+func (h *handler) resize(c *gin.Context) {
+	name, groupPool, ok := h.groupPool(c)
+	if !ok {
+		return
+	}
+
+	var req resizeRequest
+	if !request.BindJSON(c, &req) {
+		return
+	}
+
+	err := groupPool.Resize(req.Workers)
+	if errors.Is(err, worker.ErrInvalidSize) {
+		response.NewErr(c, response.ProblemBadRequest, "Workers must be a positive number")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, newPoolStateResponse(name, groupPool.State()))
+}
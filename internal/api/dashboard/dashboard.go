@@ -0,0 +1,30 @@
+package dashboard
+
+// Package dashboard serves the embedded web UI for tasks and worker pools.
+// The UI is static: it calls the JSON API of the same server with the
+// credentials entered by the user, so serving it needs no authentication.
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the files of the dashboard.
+// The handler expects paths relative to the dashboard root, see [http.StripPrefix].
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic("dashboard files are not embedded: " + err.Error())
+	}
+	fileServer := http.FileServerFS(files)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The files change with the binary, not with the URL.
+		w.Header().Set("Cache-Control", "no-cache")
+		fileServer.ServeHTTP(w, r)
+	})
+}
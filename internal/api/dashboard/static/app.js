@@ -0,0 +1,472 @@
+// Dashboard of the task manager. It polls the JSON API for pools and tasks
+// and follows the event stream of the selected task. The event stream is read
+// with fetch instead of EventSource, which cannot send the credentials headers.
+"use strict";
+
+const POLL_INTERVAL = 3000;
+const MAX_ROWS = 500;
+const RECONNECT_DELAY = 2000;
+
+const $ = (id) => document.getElementById(id);
+
+const state = {
+  credType: sessionStorage.getItem("credType") || "key",
+  cred: sessionStorage.getItem("cred") || "",
+  namespace: sessionStorage.getItem("namespace") || "",
+  tasks: [],
+  selected: null,
+  stream: null,
+  // history and logs of the selected task make up its timeline.
+  history: [],
+  logs: [],
+};
+
+// API calls
+
+class APIError extends Error {
+  constructor(status, problem) {
+    super(problem.detail || problem.title || `HTTP ${status}`);
+    this.status = status;
+    this.code = problem.code;
+  }
+}
+
+function headers(extra) {
+  const h = new Headers(extra);
+  if (state.cred && state.credType === "token") {
+    h.set("Authorization", "Bearer " + state.cred);
+  } else if (state.cred) {
+    h.set("X-API-Key", state.cred);
+  }
+  return h;
+}
+
+async function api(method, path, signal) {
+  const init = { method, headers: headers({ Accept: "application/json" }), signal };
+  if (method !== "GET") {
+    init.headers.set("Idempotency-Key", newKey());
+  }
+  const resp = await fetch(path, init);
+  if (!resp.ok) {
+    let problem = {};
+    try {
+      problem = await resp.json();
+    } catch {
+      // Not a response of the API.
+    }
+    throw new APIError(resp.status, problem);
+  }
+  return resp.json();
+}
+
+function newKey() {
+  if (crypto.randomUUID) {
+    return crypto.randomUUID();
+  }
+  return Date.now().toString(36) + Math.random().toString(36).slice(2);
+}
+
+function tasksPath() {
+  const ns = state.namespace.trim();
+  if (!ns || ns === "default") {
+    return "/api/v1/tasks";
+  }
+  return "/api/v1/namespaces/" + encodeURIComponent(ns) + "/tasks";
+}
+
+function taskPath(uuid) {
+  return tasksPath() + "/" + encodeURIComponent(uuid);
+}
+
+function showError(err) {
+  const banner = $("error");
+  if (!err) {
+    banner.hidden = true;
+    return;
+  }
+  banner.textContent = err.message;
+  banner.hidden = false;
+}
+
+// Rendering helpers
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    if (name === "class") {
+      node.className = value;
+    } else {
+      node.setAttribute(name, value);
+    }
+  }
+  for (const child of children) {
+    if (child !== null && child !== undefined) {
+      node.append(child);
+    }
+  }
+  return node;
+}
+
+function statusBadge(status) {
+  return el("span", { class: "status status-" + status }, status);
+}
+
+function formatTime(value) {
+  if (!value) {
+    return "";
+  }
+  const date = new Date(value);
+  return isNaN(date) ? value : date.toLocaleString();
+}
+
+function isTerminal(status) {
+  return status === "completed" || status === "failed" || status === "canceled";
+}
+
+// Worker pools
+
+async function refreshPools() {
+  const note = $("pools-note");
+  try {
+    const { groups } = await api("GET", "/admin/groups");
+    note.hidden = true;
+    $("pool-cards").replaceChildren(...groups.map(poolCard));
+  } catch (err) {
+    $("pool-cards").replaceChildren();
+    note.textContent = err.status === 401 || err.status === 403
+      ? "Pool state requires credentials with the admin scope."
+      : "Pool state is unavailable: " + err.message;
+    note.hidden = false;
+  }
+}
+
+function poolCard(pool) {
+  const utilisation = pool.workers ? Math.round((pool.busy_workers / pool.workers) * 100) : 0;
+  const bar = el("div", { class: "progress-bar" });
+  bar.style.width = utilisation + "%";
+
+  return el("div", { class: "card" },
+    el("h3", null, pool.group, pool.paused ? el("span", { class: "note" }, " paused") : null),
+    el("div", null, `Workers: ${pool.busy_workers} of ${pool.workers} busy (${utilisation}%)`),
+    el("div", { class: "progress" }, bar),
+    el("div", null, `Queue depth: ${pool.queued}`),
+  );
+}
+
+// Task table
+
+async function refreshTasks() {
+  const { tasks } = await api("GET", tasksPath() + "/");
+  state.tasks = tasks;
+  renderTasks();
+}
+
+function renderTasks() {
+  const status = $("status-filter").value;
+  const text = $("text-filter").value.trim().toLowerCase();
+  const matching = state.tasks
+    .filter((t) => !status || t.status === status)
+    .filter((t) => !text || [t.uuid, t.type, t.tenant].some((v) => v && v.toLowerCase().includes(text)))
+    .reverse(); // Newest first.
+
+  const counts = {};
+  for (const t of state.tasks) {
+    counts[t.status] = (counts[t.status] || 0) + 1;
+  }
+  $("task-counts").textContent = Object.entries(counts).map(([s, n]) => `${s}: ${n}`).join(", ");
+
+  const rows = matching.slice(0, MAX_ROWS).map((t) => {
+    const row = el("tr", { "data-uuid": t.uuid },
+      el("td", { class: "uuid" }, t.uuid),
+      el("td", null, t.type),
+      el("td", null, t.tenant),
+      el("td", null, statusBadge(t.status)),
+      el("td", null, formatTime(t.created_at)),
+    );
+    if (t.uuid === state.selected) {
+      row.classList.add("selected");
+    }
+    row.addEventListener("click", () => selectTask(t.uuid));
+    return row;
+  });
+  $("task-rows").replaceChildren(...rows);
+
+  const truncated = $("tasks-truncated");
+  truncated.hidden = matching.length <= MAX_ROWS;
+  truncated.textContent = `Showing the newest ${MAX_ROWS} of ${matching.length} tasks.`;
+}
+
+// Task detail
+
+async function selectTask(uuid) {
+  stopStream();
+  state.selected = uuid;
+  state.history = [];
+  state.logs = [];
+  renderTimeline();
+  $("timing").textContent = "";
+  setProgress(null);
+  $("detail").hidden = false;
+  renderTasks();
+
+  try {
+    const [task] = await Promise.all([refreshDetail(), refreshHistory()]);
+    if (!isTerminal(task.status)) {
+      followTask(uuid);
+    } else {
+      $("stream-state").textContent = "";
+    }
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function refreshDetail() {
+  const task = await api("GET", taskPath(state.selected) + "/status");
+  renderDetail(task);
+  return task;
+}
+
+function renderDetail(task) {
+  const fields = $("detail-fields");
+  fields.replaceChildren();
+  const field = (name, value) => {
+    if (value !== undefined && value !== null && value !== "") {
+      fields.append(el("dt", null, name), el("dd", null, value));
+    }
+  };
+
+  field("UUID", el("span", { class: "uuid" }, task.uuid));
+  field("Namespace", task.namespace);
+  field("Type", task.type);
+  field("Tenant", task.tenant);
+  field("Owner", task.owner);
+  field("Status", statusBadge(task.status));
+  field("Created", formatTime(task.created_at));
+  field("Duration", task.duration);
+  if (task.result !== undefined) {
+    field("Result", el("pre", null, JSON.stringify(task.result, null, 2)));
+  }
+  field("Error", task.error);
+  if (task.retry_of) {
+    field("Retry of", taskLink(task.retry_of));
+  }
+  if (task.retries && task.retries.length) {
+    field("Retries", el("span", null, ...task.retries.flatMap((r, i) => (i ? [", ", taskLink(r)] : [taskLink(r)]))));
+  }
+
+  $("cancel-task").disabled = isTerminal(task.status);
+  $("retry-task").disabled = !isTerminal(task.status);
+}
+
+function taskLink(uuid) {
+  const link = el("a", { href: "#", class: "uuid" }, uuid);
+  link.addEventListener("click", (e) => {
+    e.preventDefault();
+    selectTask(uuid);
+  });
+  return link;
+}
+
+async function refreshHistory() {
+  const history = await api("GET", taskPath(state.selected) + "/history");
+  state.history = history.history;
+  $("timing").textContent = `Queue wait ${history.queue_wait}, execution ${history.execution_time}`;
+  renderTimeline();
+}
+
+function addLog(time, message) {
+  state.logs.push({ at: time, message });
+  renderTimeline();
+}
+
+// renderTimeline shows the status transitions and log lines of the selected task by time.
+function renderTimeline() {
+  const entries = [
+    ...state.history.map((t) => ({
+      at: t.at,
+      title: t.from ? `${t.from} → ${t.to}` : `created as ${t.to}`,
+      text: `by ${t.actor}` + (t.reason ? `: ${t.reason}` : ""),
+    })),
+    ...state.logs.map((l) => ({ at: l.at, title: "log", text: l.message })),
+  ].sort((a, b) => new Date(a.at) - new Date(b.at));
+
+  $("timeline").replaceChildren(...entries.map((e) => el("li", null,
+    el("time", null, formatTime(e.at)),
+    el("strong", null, e.title),
+    " " + e.text,
+  )));
+}
+
+function setProgress(percent) {
+  const progress = document.querySelector("#detail .progress");
+  progress.hidden = percent === null;
+  progress.firstElementChild.style.width = (percent || 0) + "%";
+}
+
+// Event stream
+
+function stopStream() {
+  if (state.stream) {
+    state.stream.abort();
+    state.stream = null;
+  }
+}
+
+// followTask reads the server-sent events of the task until it finishes,
+// reconnecting while the task is selected if the stream breaks.
+async function followTask(uuid) {
+  const controller = new AbortController();
+  state.stream = controller;
+  const streamState = $("stream-state");
+
+  while (!controller.signal.aborted) {
+    streamState.textContent = "live";
+    try {
+      const resp = await fetch(taskPath(uuid) + "/events", {
+        headers: headers({ Accept: "text/event-stream" }),
+        signal: controller.signal,
+      });
+      if (!resp.ok) {
+        throw new APIError(resp.status, await resp.json().catch(() => ({})));
+      }
+
+      for await (const event of readEvents(resp.body)) {
+        if (event.type === "status") {
+          if (isTerminal(event.status)) {
+            setProgress(null);
+            streamState.textContent = "";
+            await Promise.all([refreshDetail(), refreshHistory()]);
+            refreshTasks().catch(showError);
+            return;
+          }
+          refreshDetail().catch(showError);
+          refreshHistory().catch(showError);
+        } else if (event.type === "progress") {
+          setProgress(event.progress);
+        } else if (event.type === "log") {
+          addLog(event.time, event.message);
+        }
+      }
+    } catch (err) {
+      if (controller.signal.aborted) {
+        return;
+      }
+      if (err instanceof APIError && err.status < 500 && err.status !== 429) {
+        streamState.textContent = "";
+        showError(err);
+        return;
+      }
+    }
+
+    streamState.textContent = "reconnecting…";
+    await new Promise((resolve) => setTimeout(resolve, RECONNECT_DELAY));
+  }
+}
+
+// readEvents yields the data of the server-sent events of the body.
+async function* readEvents(body) {
+  const reader = body.pipeThrough(new TextDecoderStream()).getReader();
+  let buffer = "";
+  let data = [];
+
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) {
+      return;
+    }
+    buffer += value;
+
+    let newline;
+    while ((newline = buffer.indexOf("\n")) >= 0) {
+      const line = buffer.slice(0, newline).replace(/\r$/, "");
+      buffer = buffer.slice(newline + 1);
+
+      if (line === "") {
+        if (data.length) {
+          yield JSON.parse(data.join("\n"));
+          data = [];
+        }
+      } else if (line.startsWith("data:")) {
+        data.push(line.slice(5).replace(/^ /, ""));
+      }
+      // Event names repeat the type of the data, comments keep the stream alive.
+    }
+  }
+}
+
+// Actions
+
+async function cancelSelected() {
+  try {
+    await api("POST", taskPath(state.selected) + "/cancel");
+    showError(null);
+    await refreshDetail();
+    refreshTasks().catch(showError);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function retrySelected() {
+  try {
+    const { task_uuid: retryUUID } = await api("POST", taskPath(state.selected) + "/retry");
+    showError(null);
+    await refreshTasks();
+    selectTask(retryUUID);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+// Polling
+
+async function refresh() {
+  if (document.hidden) {
+    return;
+  }
+  try {
+    await Promise.all([refreshPools(), refreshTasks()]);
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+function init() {
+  $("cred-type").value = state.credType;
+  $("cred").value = state.cred;
+  $("namespace").value = state.namespace;
+
+  $("settings").addEventListener("submit", (e) => {
+    e.preventDefault();
+    state.credType = $("cred-type").value;
+    state.cred = $("cred").value;
+    state.namespace = $("namespace").value;
+    // Session storage keeps the credentials for reloads, not across browser sessions.
+    sessionStorage.setItem("credType", state.credType);
+    sessionStorage.setItem("cred", state.cred);
+    sessionStorage.setItem("namespace", state.namespace);
+
+    stopStream();
+    state.selected = null;
+    $("detail").hidden = true;
+    refresh();
+  });
+
+  $("status-filter").addEventListener("change", renderTasks);
+  $("text-filter").addEventListener("input", renderTasks);
+  $("close-detail").addEventListener("click", () => {
+    stopStream();
+    state.selected = null;
+    $("detail").hidden = true;
+    renderTasks();
+  });
+  $("cancel-task").addEventListener("click", cancelSelected);
+  $("retry-task").addEventListener("click", retrySelected);
+
+  refresh();
+  setInterval(refresh, POLL_INTERVAL);
+}
+
+init();
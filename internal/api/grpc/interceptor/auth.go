@@ -0,0 +1,124 @@
+package interceptor
+
+// Package interceptor contains gRPC interceptors shared by the gRPC services.
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys carrying the credentials of the client.
+const (
+	APIKeyMetadata        = "x-api-key"
+	AuthorizationMetadata = "authorization"
+)
+
+const bearerPrefix = "Bearer "
+
+// Authenticator authenticates calls like the HTTP API does: by the key
+// from [APIKeyMetadata] or by the bearer token from [AuthorizationMetadata].
+// The principal is stored in the call context. Either keys or tokens
+// may be nil to disable the corresponding method.
+type Authenticator struct {
+	keys   *auth.APIKeys
+	tokens *auth.JWTVerifier
+	// scopes maps full method names to the scopes they require.
+	// Methods without a scope are available to every authenticated client.
+	scopes map[string]auth.Scope
+}
+
+func NewAuthenticator(keys *auth.APIKeys, tokens *auth.JWTVerifier, scopes map[string]auth.Scope) *Authenticator {
+	return &Authenticator{
+		keys:   keys,
+		tokens: tokens,
+		scopes: scopes,
+	}
+}
+
+// Unary returns the interceptor authenticating unary calls.
+func (a *Authenticator) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the interceptor authenticating streaming calls.
+func (a *Authenticator) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate returns ctx with the principal of the call. It fails with
+// Unauthenticated if the credentials are missing or invalid and with
+// PermissionDenied if the principal lacks the scope of the method.
+func (a *Authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	key := first(md.Get(APIKeyMetadata))
+	authorization := first(md.Get(AuthorizationMetadata))
+
+	var (
+		principal auth.Principal
+		err       error
+	)
+	switch {
+	case key != "" && a.keys != nil:
+		principal, err = a.keys.Authenticate(key)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+	case authorization != "" && a.tokens != nil:
+		token, ok := strings.CutPrefix(authorization, bearerPrefix)
+		if !ok || token == "" {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata must be a bearer token")
+		}
+
+		principal, err = a.tokens.Authenticate(token)
+		if errors.Is(err, auth.ErrTokenExpired) {
+			return nil, status.Error(codes.Unauthenticated, "bearer token has expired")
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+		}
+	default:
+		return nil, status.Error(codes.Unauthenticated, "authentication is required")
+	}
+
+	if scope, ok := a.scopes[fullMethod]; ok && !principal.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "credentials lack the %s scope", scope)
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
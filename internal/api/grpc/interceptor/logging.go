@@ -0,0 +1,93 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDMetadata carries the ID of the call. A valid ID sent
+// by the client is kept, otherwise a new one is generated.
+const RequestIDMetadata = "x-request-id"
+
+// UnaryAccessLog assigns a request ID to unary calls, recovers from panics
+// of the handlers and logs one line per call. It must be the first interceptor.
+func UnaryAccessLog(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		ctx = withRequestID(ctx)
+		start := time.Now()
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.ErrorContext(ctx, "Panic in gRPC handler", slog.String("panic", fmt.Sprint(recovered)))
+				err = status.Error(codes.Internal, "unexpected error occurred")
+			}
+			logCall(ctx, log, info.FullMethod, start, err)
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAccessLog is like [UnaryAccessLog] for streaming calls.
+func StreamAccessLog(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx := withRequestID(ss.Context())
+		start := time.Now()
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.ErrorContext(ctx, "Panic in gRPC handler", slog.String("panic", fmt.Sprint(recovered)))
+				err = status.Error(codes.Internal, "unexpected error occurred")
+			}
+			logCall(ctx, log, info.FullMethod, start, err)
+		}()
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// withRequestID stores the request ID of the call in ctx
+// and sends it back in the response header.
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := first(md.Get(RequestIDMetadata))
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id))
+	return requestid.WithContext(ctx, id)
+}
+
+func logCall(ctx context.Context, log *slog.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+
+	attrs := []slog.Attr{
+		slog.String("request_id", requestid.FromContext(ctx)),
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	log.LogAttrs(ctx, level, "gRPC request", attrs...)
+}
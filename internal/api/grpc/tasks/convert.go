@@ -0,0 +1,113 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	taskv1 "github.com/passwordhash/task-manager-api/api/task/v1"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var toProtoStatus = map[domain.TaskStatus]taskv1.TaskStatus{
+	domain.StatusPending:   taskv1.TaskStatus_TASK_STATUS_PENDING,
+	domain.StatusRunning:   taskv1.TaskStatus_TASK_STATUS_RUNNING,
+	domain.StatusCompleted: taskv1.TaskStatus_TASK_STATUS_COMPLETED,
+	domain.StatusFailed:    taskv1.TaskStatus_TASK_STATUS_FAILED,
+	domain.StatusCanceled:  taskv1.TaskStatus_TASK_STATUS_CANCELED,
+}
+
+var fromProtoStatus = map[taskv1.TaskStatus]domain.TaskStatus{
+	taskv1.TaskStatus_TASK_STATUS_PENDING:   domain.StatusPending,
+	taskv1.TaskStatus_TASK_STATUS_RUNNING:   domain.StatusRunning,
+	taskv1.TaskStatus_TASK_STATUS_COMPLETED: domain.StatusCompleted,
+	taskv1.TaskStatus_TASK_STATUS_FAILED:    domain.StatusFailed,
+	taskv1.TaskStatus_TASK_STATUS_CANCELED:  domain.StatusCanceled,
+}
+
+func toProto(task domain.Task) (*taskv1.Task, error) {
+	payload, err := toValue(task.Payload)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "task payload cannot be encoded")
+	}
+	result, err := toValue(task.Result)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "task result cannot be encoded")
+	}
+
+	var taskErr string
+	if task.Error != nil {
+		taskErr = task.Error.Error()
+	}
+
+	return &taskv1.Task{
+		Uuid:        task.UUID,
+		Namespace:   task.Namespace,
+		Type:        task.Type,
+		Tenant:      task.Tenant,
+		Owner:       task.Owner,
+		Status:      toProtoStatus[task.Status],
+		CreatedAt:   timestamppb.New(task.CreatedAt),
+		Duration:    durationpb.New(task.RunningDuration()),
+		Payload:     payload,
+		Result:      result,
+		Error:       taskErr,
+		RetryOf:     task.RetryOf,
+		Retries:     task.Retries,
+		RequestId:   task.RequestID,
+		Labels:      task.Labels,
+		Description: task.Description,
+		Priority:    int32(task.Priority),
+	}, nil
+}
+
+// toValue converts a JSON-compatible value to a protobuf value, nil stays nil.
+func toValue(v any) (*structpb.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	// Going through JSON handles any type the HTTP API can return.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value structpb.Value
+	if err := protojson.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// toStatus maps errors of the task service to gRPC status errors.
+// Unexpected errors are reported as internal without details.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, "task not found")
+	case errors.Is(err, service.ErrCantCancel):
+		return status.Error(codes.FailedPrecondition, "task cannot be canceled because it has already finished")
+	case errors.Is(err, service.ErrConflict):
+		return status.Error(codes.Aborted, "task status changed concurrently, try again")
+	case errors.Is(err, service.ErrCantDelete):
+		return status.Error(codes.FailedPrecondition, "task cannot be deleted because it is still pending or running")
+	case errors.Is(err, service.ErrTenantLimit):
+		return status.Error(codes.ResourceExhausted, "too many queued or running tasks for the tenant")
+	case errors.Is(err, service.ErrNamespaceQuota):
+		return status.Error(codes.ResourceExhausted, "the namespace has reached its task quota")
+	case errors.Is(err, service.ErrNamespaceAccess):
+		return status.Error(codes.PermissionDenied, "credentials do not grant access to the namespace")
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, "unexpected error occurred")
+	}
+}
@@ -0,0 +1,49 @@
+package tasks
+
+// Package tasks implements the gRPC task service on top of [service.TaskService].
+
+import (
+	"time"
+
+	taskv1 "github.com/passwordhash/task-manager-api/api/task/v1"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/service"
+	"google.golang.org/grpc"
+)
+
+// Scopes maps the full method names of the service to the scopes they require.
+var Scopes = map[string]auth.Scope{
+	taskv1.TaskService_CreateTask_FullMethodName: auth.ScopeTasksCreate,
+	taskv1.TaskService_GetTask_FullMethodName:    auth.ScopeTasksRead,
+	taskv1.TaskService_ListTasks_FullMethodName:  auth.ScopeTasksRead,
+	taskv1.TaskService_CancelTask_FullMethodName: auth.ScopeTasksCancel,
+	taskv1.TaskService_DeleteTask_FullMethodName: auth.ScopeTasksDelete,
+	taskv1.TaskService_WatchTask_FullMethodName:  auth.ScopeTasksRead,
+}
+
+type server struct {
+	taskv1.UnimplementedTaskServiceServer
+
+	taskService service.TaskService
+	// watchInterval is how often WatchTask checks the task for changes.
+	watchInterval time.Duration
+	// stopWatches ends running watches when closed.
+	stopWatches <-chan struct{}
+}
+
+func NewServer(
+	taskService service.TaskService,
+	watchInterval time.Duration,
+	stopWatches <-chan struct{},
+) *server {
+	return &server{
+		taskService:   taskService,
+		watchInterval: watchInterval,
+		stopWatches:   stopWatches,
+	}
+}
+
+// Register registers the service on the gRPC server.
+func (s *server) Register(gs *grpc.Server) {
+	taskv1.RegisterTaskServiceServer(gs, s)
+}
@@ -0,0 +1,226 @@
+package tasks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	taskv1 "github.com/passwordhash/task-manager-api/api/task/v1"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxTypeLength   = 64
+	maxTenantLength = 64
+)
+
+func (s *server) CreateTask(ctx context.Context, req *taskv1.CreateTaskRequest) (*taskv1.CreateTaskResponse, error) {
+	namespace, err := requestNamespace(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetType()) > maxTypeLength {
+		return nil, status.Errorf(codes.InvalidArgument, "type must be at most %d characters long", maxTypeLength)
+	}
+	if p := req.GetPriority(); p < domain.MinPriority || p > domain.MaxPriority {
+		return nil, status.Errorf(codes.InvalidArgument, "priority must be between %d and %d", domain.MinPriority, domain.MaxPriority)
+	}
+	if err := domain.ValidateLabels(req.GetLabels()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(req.GetDescription()) > domain.MaxDescriptionLength {
+		return nil, status.Errorf(codes.InvalidArgument, "description must be at most %d characters long", domain.MaxDescriptionLength)
+	}
+
+	// An authenticated client always creates tasks of its own tenant.
+	var owner string
+	tenant := req.GetTenant()
+	if principal, ok := auth.FromContext(ctx); ok {
+		owner, tenant = principal.Name, principal.Tenant
+	}
+	if len(tenant) > maxTenantLength {
+		return nil, status.Errorf(codes.InvalidArgument, "tenant must be at most %d characters long", maxTenantLength)
+	}
+
+	var payload any
+	if req.GetPayload() != nil {
+		payload = req.GetPayload().AsInterface()
+	}
+
+	taskUUID, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		Namespace:   namespace,
+		Type:        req.GetType(),
+		Payload:     payload,
+		Tenant:      tenant,
+		Owner:       owner,
+		Priority:    int(req.GetPriority()),
+		Labels:      req.GetLabels(),
+		Description: req.GetDescription(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &taskv1.CreateTaskResponse{TaskUuid: taskUUID}, nil
+}
+
+func (s *server) GetTask(ctx context.Context, req *taskv1.GetTaskRequest) (*taskv1.GetTaskResponse, error) {
+	namespace, err := requestNamespace(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUUID(req.GetUuid()); err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskService.Get(ctx, namespace, req.GetUuid())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	pbTask, err := toProto(task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &taskv1.GetTaskResponse{Task: pbTask}, nil
+}
+
+func (s *server) ListTasks(ctx context.Context, req *taskv1.ListTasksRequest) (*taskv1.ListTasksResponse, error) {
+	namespace, err := requestNamespace(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	var filter domain.TaskStatus
+	if req.GetStatus() != taskv1.TaskStatus_TASK_STATUS_UNSPECIFIED {
+		var ok bool
+		if filter, ok = fromProtoStatus[req.GetStatus()]; !ok {
+			return nil, status.Error(codes.InvalidArgument, "unknown status")
+		}
+	}
+
+	tasks, err := s.taskService.GetAll(ctx, namespace, nil)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &taskv1.ListTasksResponse{Tasks: make([]*taskv1.Task, 0, len(tasks))}
+	for _, task := range tasks {
+		if filter != "" && task.Status != filter {
+			continue
+		}
+
+		pbTask, err := toProto(task)
+		if err != nil {
+			return nil, err
+		}
+		resp.Tasks = append(resp.Tasks, pbTask)
+	}
+
+	return resp, nil
+}
+
+func (s *server) CancelTask(ctx context.Context, req *taskv1.CancelTaskRequest) (*taskv1.CancelTaskResponse, error) {
+	namespace, err := requestNamespace(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUUID(req.GetUuid()); err != nil {
+		return nil, err
+	}
+
+	if err := s.taskService.Cancel(ctx, namespace, req.GetUuid(), 0); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &taskv1.CancelTaskResponse{}, nil
+}
+
+func (s *server) DeleteTask(ctx context.Context, req *taskv1.DeleteTaskRequest) (*taskv1.DeleteTaskResponse, error) {
+	namespace, err := requestNamespace(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUUID(req.GetUuid()); err != nil {
+		return nil, err
+	}
+
+	if err := s.taskService.Delete(ctx, namespace, req.GetUuid(), 0); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &taskv1.DeleteTaskResponse{}, nil
+}
+
+// WatchTask polls the task every watchInterval and sends it
+// whenever its status differs from the last one sent.
+func (s *server) WatchTask(req *taskv1.WatchTaskRequest, stream grpc.ServerStreamingServer[taskv1.WatchTaskResponse]) error {
+	namespace, err := requestNamespace(req.GetNamespace())
+	if err != nil {
+		return err
+	}
+	if err := validateUUID(req.GetUuid()); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	var sent domain.TaskStatus
+	for {
+		task, err := s.taskService.Get(ctx, namespace, req.GetUuid())
+		if err != nil {
+			return toStatus(err)
+		}
+
+		if task.Status != sent {
+			pbTask, err := toProto(task)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&taskv1.WatchTaskResponse{Task: pbTask}); err != nil {
+				return err
+			}
+			sent = task.Status
+		}
+
+		if task.Status.IsTerminal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.stopWatches:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-ticker.C:
+		}
+	}
+}
+
+// requestNamespace returns the namespace of a request,
+// [domain.DefaultNamespace] if it is empty.
+func requestNamespace(namespace string) (string, error) {
+	if namespace == "" {
+		return domain.DefaultNamespace, nil
+	}
+	if !domain.ValidNamespace(namespace) {
+		return "", status.Error(codes.InvalidArgument, "namespace must be a DNS label of at most 63 characters")
+	}
+	return namespace, nil
+}
+
+func validateUUID(taskUUID string) error {
+	if _, err := uuid.Parse(taskUUID); err != nil {
+		return status.Error(codes.InvalidArgument, "uuid must be a valid UUID")
+	}
+	return nil
+}
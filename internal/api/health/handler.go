@@ -0,0 +1,23 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+type handler struct {
+	pools worker.PoolGroups
+}
+
+func NewHandler(
+	pools worker.PoolGroups,
+) *handler {
+	return &handler{
+		pools: pools,
+	}
+}
+
+func (h *handler) RegisterRoutes(router gin.IRoutes) {
+	router.GET("/healthz", h.healthz)
+	router.GET("/readyz", h.readyz)
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+const (
+	statusOK     = "ok"
+	statusPaused = "paused"
+)
+
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+func (h *handler) healthz(c *gin.Context) {
+	response.NewOk(c, healthResponse{Status: statusOK})
+}
+
+type readyResponse struct {
+	Status string `json:"status"`
+	// Pool is the state of the default worker group.
+	Pool   pool            `json:"pool"`
+	Groups map[string]pool `json:"groups"`
+}
+
+type pool struct {
+	Paused  bool `json:"paused"`
+	Workers int  `json:"workers"`
+	Queued  int  `json:"queued"`
+}
+
+// readyz reports that the service accepts requests. A paused pool
+// still accepts new tasks, so pausing is reflected in the body only.
+// The status is paused if any worker group is paused.
+func (h *handler) readyz(c *gin.Context) {
+	resp := readyResponse{
+		Status: statusOK,
+		Groups: make(map[string]pool),
+	}
+
+	for _, name := range h.pools.Names() {
+		groupPool, _ := h.pools.Get(name)
+		state := groupPool.State()
+
+		if state.Paused {
+			resp.Status = statusPaused
+		}
+
+		p := pool{
+			Paused:  state.Paused,
+			Workers: state.Workers,
+			Queued:  state.Queued,
+		}
+		if name == worker.DefaultGroup {
+			resp.Pool = p
+		}
+		resp.Groups[name] = p
+	}
+
+	response.NewOk(c, resp)
+}
@@ -0,0 +1,26 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/passwordhash/task-manager-api/internal/api/openapi"
+)
+
+const tag = "health"
+
+// Routes describes the routes registered by RegisterRoutes.
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/healthz", ID: "healthz", Tag: tag,
+			Summary:  "Liveness probe",
+			Response: healthResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/readyz", ID: "readyz", Tag: tag,
+			Summary:     "Readiness probe",
+			Description: "The status is `paused` if any worker group is paused.",
+			Response:    readyResponse{},
+		},
+	}
+}
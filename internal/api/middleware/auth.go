@@ -0,0 +1,88 @@
+package middleware
+
+// Package middleware contains gin middlewares shared by the API handlers.
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+)
+
+// APIKeyHeader carries the API key of the client.
+const APIKeyHeader = "X-API-Key"
+
+const bearerPrefix = "Bearer "
+
+// Authenticate authenticates requests by the key from [APIKeyHeader]
+// or by the bearer token from the Authorization header and stores
+// the principal in the request context. Either keys or tokens may be nil
+// to disable the corresponding method. Requests without valid
+// credentials are rejected with 401.
+func Authenticate(keys *auth.APIKeys, tokens *auth.JWTVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		authorization := c.GetHeader("Authorization")
+
+		var (
+			principal auth.Principal
+			err       error
+		)
+		switch {
+		case key != "" && keys != nil:
+			principal, err = keys.Authenticate(key)
+			if err != nil {
+				unauthorized(c, tokens != nil, "Invalid API key")
+				return
+			}
+		case authorization != "" && tokens != nil:
+			token, ok := strings.CutPrefix(authorization, bearerPrefix)
+			if !ok || token == "" {
+				unauthorized(c, true, "Authorization header must be a bearer token")
+				return
+			}
+
+			principal, err = tokens.Authenticate(token)
+			if errors.Is(err, auth.ErrTokenExpired) {
+				unauthorized(c, true, "Bearer token has expired")
+				return
+			}
+			if err != nil {
+				unauthorized(c, true, "Invalid bearer token")
+				return
+			}
+		default:
+			unauthorized(c, tokens != nil, "Authentication is required")
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, bearer bool, message string) {
+	if bearer {
+		c.Header("WWW-Authenticate", `Bearer realm="task-manager"`)
+	}
+	response.NewErr(c, response.ProblemUnauthorized, message)
+	c.Abort()
+}
+
+// RequireScope rejects requests of principals without the scope with 403.
+// Requests without a principal pass, since they only reach
+// the handlers when authentication is disabled.
+func RequireScope(scope auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if ok && !principal.HasScope(scope) {
+			response.NewErr(c, response.ProblemForbidden, "Credentials lack the "+string(scope)+" scope")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/request"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+)
+
+const (
+	// IdempotencyKeyHeader carries the key that makes retries of a request safe.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses repeated for a known key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// MaxIdempotencyKeyLength limits the length of idempotency keys.
+	MaxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the headers of a response remembered with its body.
+// Headers of the exchange, such as the request ID or rate limits,
+// are set anew for the repeated request.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+type idempotentRequest struct {
+	// fingerprint tells requests reusing the key for something else.
+	fingerprint string
+	// done is false while the first request with the key is served.
+	done    bool
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// IdempotencyKeys remembers the responses to requests with an idempotency
+// key for a TTL. Keys are scoped by client, so clients cannot see responses
+// of each other. It is safe for concurrent use.
+type IdempotencyKeys struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	requests  map[string]*idempotentRequest
+	lastSweep time.Time
+}
+
+func NewIdempotencyKeys(ttl time.Duration) *IdempotencyKeys {
+	return &IdempotencyKeys{
+		ttl:       ttl,
+		requests:  make(map[string]*idempotentRequest),
+		lastSweep: time.Now(),
+	}
+}
+
+// begin returns the request remembered for the key and whether it is new.
+// A new request is remembered as in progress until finish or abort is called.
+func (k *IdempotencyKeys) begin(key, fingerprint string, now time.Time) (req *idempotentRequest, isNew bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.sweep(now)
+
+	if req, ok := k.requests[key]; ok && now.Before(req.expires) {
+		return req, false
+	}
+
+	req = &idempotentRequest{fingerprint: fingerprint, expires: now.Add(k.ttl)}
+	k.requests[key] = req
+	return req, true
+}
+
+// finish remembers the response to the request. Server errors are
+// forgotten instead, so the request can be retried with the same key.
+func (k *IdempotencyKeys) finish(key string, req *idempotentRequest, status int, header http.Header, body []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if status >= http.StatusInternalServerError {
+		k.forget(key, req)
+		return
+	}
+
+	req.done = true
+	req.status = status
+	req.header = header
+	req.body = body
+}
+
+// forget drops the request, unless the key was taken by another one
+// after it expired. k.mu must be held.
+func (k *IdempotencyKeys) forget(key string, req *idempotentRequest) {
+	if k.requests[key] == req {
+		delete(k.requests, key)
+	}
+}
+
+// abort forgets the request, so it can be retried with the same key.
+func (k *IdempotencyKeys) abort(key string, req *idempotentRequest) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.forget(key, req)
+}
+
+// sweep drops expired requests. k.mu must be held.
+func (k *IdempotencyKeys) sweep(now time.Time) {
+	if now.Sub(k.lastSweep) < sweepInterval {
+		return
+	}
+	k.lastSweep = now
+
+	for key, req := range k.requests {
+		if !now.Before(req.expires) {
+			delete(k.requests, key)
+		}
+	}
+}
+
+// bodyRecorder keeps a copy of the response body.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *bodyRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes requests with [IdempotencyKeyHeader] that change state
+// safe to retry: a request repeating the key of a served one gets the same
+// response with [IdempotentReplayedHeader] and is not served again. Reusing
+// the key for another method, path, query or body is rejected with 422, repeating
+// it while the first request is served is rejected with 409. It must run
+// after [Authenticate] to tell clients apart by their keys.
+func Idempotency(keys *IdempotencyKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			key = ""
+		}
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > MaxIdempotencyKeyLength {
+			response.NewValidationErr(c, "Invalid request headers", []response.FieldError{{
+				Field:  IdempotencyKeyHeader,
+				Reason: "must be at most " + strconv.Itoa(MaxIdempotencyKeyLength) + " characters long",
+			}})
+			c.Abort()
+			return
+		}
+
+		// Read one byte over the limit, so the handler still sees the body is too large.
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, request.MaxBodySize+1))
+		if err != nil {
+			response.NewErr(c, response.ProblemBadRequest, "Request body cannot be read")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		// The query matters too, e.g. the selector of a bulk request.
+		fingerprint := c.Request.Method + " " + c.Request.URL.RequestURI() + " " + hex.EncodeToString(sum[:])
+		storeKey := clientKey(c) + " " + key
+
+		req, isNew := keys.begin(storeKey, fingerprint, time.Now())
+		if !isNew {
+			replay(c, keys, req, fingerprint)
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// A panicking handler must not leave the key in progress
+		// until it expires. The panic goes on to the recovery middleware.
+		served := false
+		defer func() {
+			if !served {
+				keys.abort(storeKey, req)
+				return
+			}
+			keys.finish(storeKey, req, recorder.Status(), replayedHeader(recorder.Header()), recorder.body.Bytes())
+		}()
+
+		c.Next()
+		served = true
+	}
+}
+
+// replayedHeader returns the [replayedHeaders] of the response header.
+func replayedHeader(header http.Header) http.Header {
+	replayed := make(http.Header, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			replayed[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+		}
+	}
+	return replayed
+}
+
+// replay responds to a request repeating a known key.
+func replay(c *gin.Context, keys *IdempotencyKeys, req *idempotentRequest, fingerprint string) {
+	keys.mu.Lock()
+	done, status, header, body := req.done, req.status, req.header, req.body
+	keys.mu.Unlock()
+
+	switch {
+	case req.fingerprint != fingerprint:
+		response.NewErr(c, response.ProblemIdempotencyKeyReused,
+			"The idempotency key was used for a request with another method, path, query or body")
+	case !done:
+		response.NewErr(c, response.ProblemIdempotencyKeyInUse,
+			"A request with the idempotency key is still in progress, retry later")
+	default:
+		for name, values := range header {
+			c.Writer.Header()[name] = slices.Clone(values)
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(status, header.Get("Content-Type"), body)
+	}
+	c.Abort()
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newIdempotentRouter returns a router serving POST /tasks with the handler
+// behind the recovery and idempotency middlewares.
+func newIdempotentRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.Use(Idempotency(NewIdempotencyKeys(time.Hour)))
+	router.POST("/tasks", handler)
+	return router
+}
+
+func postWithKey(router http.Handler, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/tasks", nil)
+	req.Header.Set(IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysResponses(t *testing.T) {
+	calls := 0
+	router := newIdempotentRouter(func(c *gin.Context) {
+		calls++
+		c.Header("ETag", `"1"`)
+		c.Header("Location", "/tasks/1")
+		c.Header("X-Request-ID", "first")
+		c.String(http.StatusCreated, "created")
+	})
+
+	first := postWithKey(router, "key")
+	second := postWithKey(router, "key")
+
+	if calls != 1 {
+		t.Errorf("handler called %d times, want once", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != "created" || second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("repeated request got %d %q, want the replayed %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+	for _, name := range []string{"Content-Type", "ETag", "Location"} {
+		if got, want := second.Header().Get(name), first.Header().Get(name); got != want {
+			t.Errorf("repeated request got %s %q, want the replayed %q", name, got, want)
+		}
+	}
+	if got := second.Header().Get("X-Request-ID"); got != "" {
+		t.Errorf("repeated request got X-Request-ID %q of the first one", got)
+	}
+}
+
+func TestIdempotencyForgetsFailedRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		fail gin.HandlerFunc
+	}{
+		{name: "server error", fail: func(c *gin.Context) { c.Status(http.StatusServiceUnavailable) }},
+		{name: "panic", fail: func(*gin.Context) { panic("handler failed") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			router := newIdempotentRouter(func(c *gin.Context) {
+				calls++
+				if calls == 1 {
+					tt.fail(c)
+					return
+				}
+				c.String(http.StatusCreated, "created")
+			})
+
+			if rec := postWithKey(router, "key"); rec.Code < http.StatusInternalServerError {
+				t.Fatalf("first request: status %d, want a server error", rec.Code)
+			}
+
+			// The retry is served again instead of being rejected as in progress.
+			rec := postWithKey(router, "key")
+			if rec.Code != http.StatusCreated || calls != 2 {
+				t.Errorf("retry: status %d after %d calls, want %d after 2 calls", rec.Code, calls, http.StatusCreated)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyIsBoundToQuery(t *testing.T) {
+	calls := 0
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Idempotency(NewIdempotencyKeys(time.Hour)))
+	router.POST("/tasks/cancel", func(c *gin.Context) {
+		calls++
+		c.String(http.StatusOK, "canceled "+c.Query("selector"))
+	})
+
+	post := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		req.Header.Set(IdempotencyKeyHeader, "key")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post("/tasks/cancel?selector=env%3Ddev"); rec.Code != http.StatusOK {
+		t.Fatalf("first request: status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec := post("/tasks/cancel?selector=env%3Dprod")
+	if rec.Code != http.StatusUnprocessableEntity || calls != 1 {
+		t.Errorf("request with another query: status %d after %d calls, want %d after 1 call",
+			rec.Code, calls, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/requestid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID of the request. A valid ID sent
+// by the client is kept, otherwise a new one is generated.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID assigns an ID to the request, returns it in [RequestIDHeader]
+// and stores it in the request context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(requestid.WithContext(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// AccessLog logs one line per request once it is served.
+// Server errors are logged at the error level, client errors
+// at the warning level. It must run after [RequestID].
+func AccessLog(log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+
+		attrs := []slog.Attr{
+			slog.String("request_id", requestid.FromContext(c.Request.Context())),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if principal, ok := auth.FromContext(c.Request.Context()); ok {
+			attrs = append(attrs, slog.String("principal", principal.Name))
+		}
+		if err := c.Errors.Last(); err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		log.LogAttrs(c.Request.Context(), level, "HTTP request", attrs...)
+	}
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+)
+
+// sweepInterval is how often buckets of idle clients are dropped.
+const sweepInterval = time.Minute
+
+// RateLimit is the rate of a token bucket: clients may send Burst
+// requests at once and then Rate requests per second.
+// A zero Rate disables the limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter limits requests per client with token buckets.
+// Clients are identified by the authenticated principal,
+// or by the IP address when there is none.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	limit RateLimit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	return &RateLimiter{
+		limit:     limit,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// take takes a token from the bucket of the client. It returns whether
+// the request is allowed, the number of tokens left, the time until the
+// next token is available and the time until the bucket is full.
+func (l *RateLimiter) take(key string, now time.Time) (ok bool, remaining int, retryAfter, reset time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	burst := float64(l.limit.Burst)
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		ok = true
+	} else {
+		retryAfter = l.duration(1 - b.tokens)
+	}
+
+	return ok, int(b.tokens), retryAfter, l.duration(burst - b.tokens)
+}
+
+// duration returns the time needed to refill the number of tokens.
+func (l *RateLimiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.limit.Rate * float64(time.Second))
+}
+
+// sweep drops the buckets that have refilled completely,
+// they are the same as new ones. Must be called with mu held.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	burst := float64(l.limit.Burst)
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate >= burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitByClient limits requests with the limiter. Every response
+// gets the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset
+// headers, requests over the limit are rejected with 429 and Retry-After.
+// It must run after [Authenticate] to tell clients apart by their keys.
+func RateLimitByClient(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter.limit.Rate <= 0 {
+			c.Next()
+			return
+		}
+
+		ok, remaining, retryAfter, reset := limiter.take(clientKey(c), time.Now())
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limiter.limit.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+			response.NewErr(c, response.ProblemTooManyRequests, "Rate limit exceeded, retry later")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitByMethod applies the read limiter to GET and HEAD requests
+// and the create limiter to the others, which create or change tasks,
+// so clients creating tasks do not use up the limit of polling their status.
+func RateLimitByMethod(read, create *RateLimiter) gin.HandlerFunc {
+	readLimit, createLimit := RateLimitByClient(read), RateLimitByClient(create)
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead:
+			readLimit(c)
+		default:
+			createLimit(c)
+		}
+	}
+}
+
+// clientKey identifies the client of the request by the authenticated
+// principal, or by the IP address when there is none.
+func clientKey(c *gin.Context) string {
+	if principal, ok := auth.FromContext(c.Request.Context()); ok {
+		return "principal:" + principal.Name
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,185 @@
+package openapi
+
+// Package openapi builds the OpenAPI 3 document of the API from route
+// descriptions and the request and response types of the handlers.
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+)
+
+const Version = "3.0.3"
+
+// Security schemes of the document.
+const (
+	APIKeyScheme = "ApiKey"
+	BearerScheme = "Bearer"
+)
+
+// Route describes an operation of the API. Path uses the gin syntax
+// and must match the path the handler is registered with.
+type Route struct {
+	Method string
+	Path   string
+	// ID is the operation ID, unique in the document.
+	ID          string
+	Summary     string
+	Description string
+	Tag         string
+	// Scope the client needs, see [auth.Scope]. Routes without
+	// a scope are public.
+	Scope string
+	// Parameters lists query and header parameters and path parameters
+	// that need a description. Other path parameters are added from Path.
+	Parameters []Parameter
+	// Query is a value of the struct type the query is bound to,
+	// its fields with a form tag are added as query parameters.
+	Query any
+	// Request is a value of the type of the JSON request body, nil if the
+	// route has no body. The body is required unless RequestOptional is set.
+	Request         any
+	RequestOptional bool
+	// Response is a value of the type of the response body,
+	// ResponseContentType is its media type, JSON if empty.
+	Response            any
+	ResponseContentType string
+	// NotModified documents the empty 304 response to a conditional
+	// request whose If-None-Match lists the current ETag.
+	NotModified bool
+	// Problems lists the problems the route responds with. Authentication
+	// problems of routes with a scope and internal errors are added to every route.
+	Problems []response.ProblemType
+}
+
+// Build returns the document describing the routes.
+func Build(info Info, routes []Route) *Document {
+	gen := newSchemaGenerator()
+	problem := gen.schema(reflect.TypeOf(response.Problem{}), false)
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: gen.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				APIKeyScheme: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+				BearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		Security: []SecurityRequirement{{APIKeyScheme: {}}, {BearerScheme: {}}},
+	}
+
+	for _, route := range routes {
+		path := Path(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+
+		op := &Operation{
+			OperationID: route.ID,
+			Summary:     route.Summary,
+			Description: route.Description,
+			Parameters:  parameters(route, gen),
+			Responses:   make(map[string]Response),
+		}
+		if route.Tag != "" {
+			op.Tags = []string{route.Tag}
+		}
+
+		problems := slices.Clone(route.Problems)
+		if route.Scope == "" {
+			op.Security = &[]SecurityRequirement{}
+		} else {
+			op.Description = strings.TrimSpace(op.Description + "\n\nRequires the `" + route.Scope + "` scope.")
+			problems = append(problems, response.ProblemUnauthorized, response.ProblemForbidden)
+		}
+		problems = append(problems, response.ProblemInternal)
+
+		if route.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: !route.RequestOptional,
+				Content: map[string]MediaType{
+					"application/json": {Schema: gen.schema(reflect.TypeOf(route.Request), true)},
+				},
+			}
+		}
+
+		ok200 := Response{Description: "OK"}
+		if route.Response != nil {
+			contentType := route.ResponseContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			ok200.Content = map[string]MediaType{
+				contentType: {Schema: gen.schema(reflect.TypeOf(route.Response), false)},
+			}
+		}
+		op.Responses[strconv.Itoa(http.StatusOK)] = ok200
+		if route.NotModified {
+			op.Responses[strconv.Itoa(http.StatusNotModified)] = Response{Description: "Not Modified"}
+		}
+
+		for _, p := range problems {
+			status := strconv.Itoa(p.Status)
+			resp, ok := op.Responses[status]
+			if !ok {
+				resp = Response{
+					Description: p.Title,
+					Content: map[string]MediaType{
+						response.ProblemContentType: {Schema: problem},
+					},
+				}
+			} else if !strings.Contains(resp.Description, p.Title) {
+				resp.Description += "; " + p.Title
+			}
+			op.Responses[status] = resp
+		}
+
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	return doc
+}
+
+// Path converts a gin route path to an OpenAPI path template.
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// parameters returns the parameters of the route with its query parameters
+// and the path parameters that are not described explicitly.
+func parameters(route Route, gen *schemaGenerator) []Parameter {
+	params := slices.Clone(route.Parameters)
+	if route.Query != nil {
+		params = append(params, gen.query(reflect.TypeOf(route.Query))...)
+	}
+
+	for _, s := range strings.Split(route.Path, "/") {
+		if !strings.HasPrefix(s, ":") {
+			continue
+		}
+		name := s[1:]
+		described := slices.ContainsFunc(params, func(p Parameter) bool {
+			return p.In == "path" && p.Name == name
+		})
+		if !described {
+			params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	return params
+}
@@ -0,0 +1,177 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaGenerator derives schemas from Go types. Named struct types
+// become components referenced by their capitalized type name.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: make(map[string]*Schema),
+		types:   make(map[string]reflect.Type),
+	}
+}
+
+// schema returns the schema of t. Fields of request types are required
+// if their binding tag requires them, fields of response types
+// are required unless they are omitted when empty.
+func (g *schemaGenerator) schema(t reflect.Type, request bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		return g.component(t, request)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem(), request)}
+	case reflect.Struct:
+		return g.object(t, request)
+	default:
+		// Interfaces hold arbitrary JSON values.
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) component(t reflect.Type, request bool) *Schema {
+	r, size := utf8.DecodeRuneInString(t.Name())
+	name := string(unicode.ToUpper(r)) + t.Name()[size:]
+
+	if known, ok := g.types[name]; ok {
+		if known != t {
+			panic(fmt.Sprintf("openapi: schema name %s is used by %s and %s", name, known, t))
+		}
+	} else {
+		g.types[name] = t
+		// Register the name first so that recursive types terminate.
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.object(t, request)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *schemaGenerator) object(t reflect.Type, request bool) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.schema(field.Type, request)
+		rules := strings.Split(field.Tag.Get("binding"), ",")
+		applyRules(prop, field.Type, rules)
+		s.Properties[name] = prop
+
+		required := !strings.Contains(opts, "omitempty")
+		if request {
+			required = slices.Contains(rules, "required")
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+// query returns the parameters of the fields of t with a form tag.
+func (g *schemaGenerator) query(t reflect.Type) []Parameter {
+	var params []Parameter
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema := g.schema(field.Type, true)
+		rules := strings.Split(field.Tag.Get("binding"), ",")
+		applyRules(schema, field.Type, rules)
+
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: slices.Contains(rules, "required"),
+			Schema:   schema,
+		})
+	}
+
+	return params
+}
+
+// applyRules adds the constraints of the validator binding rules to s.
+func applyRules(s *Schema, t reflect.Type, rules []string) {
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "uuid":
+			s.Format = "uuid"
+		case "oneof":
+			s.Enum = strings.Fields(arg)
+		case "min", "max":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				continue
+			}
+			switch {
+			case t.Kind() == reflect.String && name == "min":
+				s.MinLength = &n
+			case t.Kind() == reflect.String:
+				s.MaxLength = &n
+			case s.Type != "integer" && s.Type != "number":
+				// Limits of collections are not described.
+			case name == "min":
+				f := float64(n)
+				s.Minimum = &f
+			default:
+				f := float64(n)
+				s.Maximum = &f
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package openapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const initializerTemplate = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// UIHandler serves the embedded Swagger UI showing the document at specURL.
+// The handler expects paths relative to the UI root, see [http.StripPrefix].
+func UIHandler(specURL string) http.Handler {
+	initializer := fmt.Sprintf(initializerTemplate, specURL)
+	files := http.FileServerFS(swaggerFiles.FS)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "swagger-initializer.js" {
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			_, _ = io.WriteString(w, initializer)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,92 @@
+package request
+
+// Package request binds and validates the path, query and body
+// of requests and responds with a validation problem if they are invalid.
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+)
+
+// MaxBodySize limits the size of JSON request bodies.
+const MaxBodySize = 1 << 20
+
+const (
+	sourceBody  = "body"
+	sourcePath  = "path"
+	sourceQuery = "query"
+)
+
+var errTrailingData = errors.New("request body must contain a single JSON object")
+
+// BindJSON decodes the JSON body into obj and validates it with
+// its binding tags. Unknown fields and bodies larger than [MaxBodySize]
+// are rejected. If the body is invalid, it responds with
+// [response.ProblemValidation] and returns false.
+func BindJSON(c *gin.Context, obj any) bool {
+	return bindJSON(c, obj, false)
+}
+
+// BindOptionalJSON is like [BindJSON] but accepts an empty body,
+// leaving obj as is.
+func BindOptionalJSON(c *gin.Context, obj any) bool {
+	return bindJSON(c, obj, true)
+}
+
+func bindJSON(c *gin.Context, obj any, optional bool) bool {
+	err := decodeJSON(c, obj)
+	if optional && errors.Is(err, io.EOF) {
+		err = nil
+	}
+	if err == nil {
+		err = binding.Validator.ValidateStruct(obj)
+	}
+	if err != nil {
+		response.NewValidationErr(c, "Invalid request body", response.FieldErrors(err, sourceBody))
+		return false
+	}
+	return true
+}
+
+func decodeJSON(c *gin.Context, obj any) error {
+	if c.Request.Body == nil {
+		return io.EOF
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, MaxBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errTrailingData
+	}
+	return nil
+}
+
+// BindURI binds the path parameters to the uri tags of obj and validates them.
+// If they are invalid, it responds with [response.ProblemValidation] and returns false.
+func BindURI(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindUri(obj); err != nil {
+		response.NewValidationErr(c, "Invalid path parameters", response.FieldErrors(err, sourcePath))
+		return false
+	}
+	return true
+}
+
+// BindQuery binds the query parameters to the form tags of obj and validates them.
+// If they are invalid, it responds with [response.ProblemValidation] and returns false.
+func BindQuery(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		response.NewValidationErr(c, "Invalid query parameters", response.FieldErrors(err, sourceQuery))
+		return false
+	}
+	return true
+}
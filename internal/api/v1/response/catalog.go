@@ -0,0 +1,121 @@
+package response
+
+import "net/http"
+
+// problemTypeBase prefixes the codes of the catalog to form problem type URIs.
+const problemTypeBase = "urn:task-manager:problem:"
+
+// ProblemType is an entry of the error catalog. Codes are stable:
+// clients may rely on them, so they must never be changed or reused.
+type ProblemType struct {
+	Code   string
+	Status int
+	Title  string
+}
+
+// URI returns the problem type URI sent in the type member.
+func (p ProblemType) URI() string {
+	return problemTypeBase + p.Code
+}
+
+var (
+	ProblemBadRequest = ProblemType{
+		Code: "invalid_request_parameters", Status: http.StatusBadRequest,
+		Title: "Invalid request parameters",
+	}
+	ProblemValidation = ProblemType{
+		Code: "validation_failed", Status: http.StatusBadRequest,
+		Title: "Request validation failed",
+	}
+	ProblemUnauthorized = ProblemType{
+		Code: "unauthorized", Status: http.StatusUnauthorized,
+		Title: "Authentication required",
+	}
+	ProblemForbidden = ProblemType{
+		Code: "forbidden", Status: http.StatusForbidden,
+		Title: "Access denied",
+	}
+	ProblemQuotaExceeded = ProblemType{
+		Code: "quota_exceeded", Status: http.StatusForbidden,
+		Title: "Quota exceeded",
+	}
+	ProblemNotFound = ProblemType{
+		Code: "not_found", Status: http.StatusNotFound,
+		Title: "Resource not found",
+	}
+	ProblemRouteNotFound = ProblemType{
+		Code: "route_not_found", Status: http.StatusNotFound,
+		Title: "Route not found",
+	}
+	ProblemCantCancel = ProblemType{
+		Code: "cant_be_canceled", Status: http.StatusConflict,
+		Title: "Task cannot be canceled",
+	}
+	ProblemCantRetry = ProblemType{
+		Code: "cant_be_retried", Status: http.StatusConflict,
+		Title: "Task cannot be retried",
+	}
+	ProblemCantUpdate = ProblemType{
+		Code: "cant_be_updated", Status: http.StatusConflict,
+		Title: "Task cannot be updated",
+	}
+	ProblemCantDelete = ProblemType{
+		Code: "cant_be_deleted", Status: http.StatusConflict,
+		Title: "Task cannot be deleted",
+	}
+	ProblemStatusConflict = ProblemType{
+		Code: "status_conflict", Status: http.StatusConflict,
+		Title: "Task status changed concurrently",
+	}
+	ProblemPreconditionFailed = ProblemType{
+		Code: "precondition_failed", Status: http.StatusPreconditionFailed,
+		Title: "Task version does not match",
+	}
+	ProblemIdempotencyKeyInUse = ProblemType{
+		Code: "idempotency_key_in_use", Status: http.StatusConflict,
+		Title: "Request with the idempotency key is in progress",
+	}
+	ProblemIdempotencyKeyReused = ProblemType{
+		Code: "idempotency_key_reused", Status: http.StatusUnprocessableEntity,
+		Title: "Idempotency key was used for another request",
+	}
+	ProblemTooManyRequests = ProblemType{
+		Code: "too_many_requests", Status: http.StatusTooManyRequests,
+		Title: "Too many requests",
+	}
+	ProblemRequestTimeout = ProblemType{
+		Code: "request_timeout", Status: http.StatusRequestTimeout,
+		Title: "Request canceled",
+	}
+	ProblemInternal = ProblemType{
+		Code: "internal_error", Status: http.StatusInternalServerError,
+		Title: "Internal server error",
+	}
+	ProblemGatewayTimeout = ProblemType{
+		Code: "gateway_timeout", Status: http.StatusGatewayTimeout,
+		Title: "Request timed out",
+	}
+)
+
+// Catalog lists every problem type the API responds with.
+var Catalog = []ProblemType{
+	ProblemBadRequest,
+	ProblemValidation,
+	ProblemUnauthorized,
+	ProblemForbidden,
+	ProblemQuotaExceeded,
+	ProblemNotFound,
+	ProblemRouteNotFound,
+	ProblemCantCancel,
+	ProblemCantRetry,
+	ProblemCantUpdate,
+	ProblemCantDelete,
+	ProblemStatusConflict,
+	ProblemPreconditionFailed,
+	ProblemIdempotencyKeyInUse,
+	ProblemIdempotencyKeyReused,
+	ProblemTooManyRequests,
+	ProblemRequestTimeout,
+	ProblemInternal,
+	ProblemGatewayTimeout,
+}
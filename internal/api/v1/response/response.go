@@ -6,48 +6,87 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/requestid"
 )
 
-var (
-	ErrBadRequestParams = errors.New("invalid_request_parameters")
-	ErrNotFound         = errors.New("not_found")
-)
+// ProblemContentType is the media type of error responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
 
 type Message struct {
 	Message string `json:"message"`
 }
 
-type Error struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
+// Problem is the body of error responses in the RFC 7807 format.
+// Code duplicates the last part of Type for clients that
+// prefer to switch on a short string.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes an invalid field of a request.
+type FieldError struct {
+	// Field is the name of the field in the request: a JSON field,
+	// a query or a path parameter, or a header.
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
 }
 
 func NewOk(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, data)
 }
 
-func NewErr(c *gin.Context, code int, err error, clientMessage string) {
-	c.JSON(code, Error{
-		Error:   err.Error(),
-		Message: clientMessage,
+// NewErr responds with a problem of the type from the catalog.
+// detail explains this occurrence of the problem to the client.
+func NewErr(c *gin.Context, problemType ProblemType, detail string) {
+	writeProblem(c, problemType, detail, nil)
+}
+
+// NewValidationErr responds with a [ProblemValidation] problem
+// listing the invalid fields.
+func NewValidationErr(c *gin.Context, detail string, fields []FieldError) {
+	writeProblem(c, ProblemValidation, detail, fields)
+}
+
+func writeProblem(c *gin.Context, problemType ProblemType, detail string, fields []FieldError) {
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(problemType.Status, Problem{
+		Type:      problemType.URI(),
+		Title:     problemType.Title,
+		Status:    problemType.Status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      problemType.Code,
+		RequestID: requestid.FromContext(c.Request.Context()),
+		Errors:    fields,
 	})
 }
 
 // HandleError processes handlgin basic errors in the context of a gin handler.
 // It returns true if an error was handled, false otherwise.
+// The error itself is attached to the gin context for the access log
+// and never sent to the client.
 func HandleError(c *gin.Context, err error) bool {
 	if err == nil {
 		return false
 	}
 
+	_ = c.Error(err)
+
 	if errors.Is(err, context.DeadlineExceeded) {
-		NewErr(c, http.StatusGatewayTimeout, errors.New("gateway_timeout"), "The request timed out.")
+		NewErr(c, ProblemGatewayTimeout, "The request timed out.")
 		return true
 	}
 	if errors.Is(err, context.Canceled) {
-		NewErr(c, http.StatusRequestTimeout, errors.New("request_timeout"), "The request was canceled.")
+		NewErr(c, ProblemRequestTimeout, "The request was canceled.")
 		return true
 	}
-	NewErr(c, http.StatusInternalServerError, err, "Unexpected error occurred.")
+	NewErr(c, ProblemInternal, "Unexpected error occurred.")
 	return true
 }
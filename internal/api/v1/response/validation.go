@@ -0,0 +1,128 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report fields by the names clients use rather than the Go ones.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "uri", "form"} {
+				name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// FieldErrors describes the fields rejected by request binding. Errors
+// not related to a single field, e.g. malformed JSON, are reported for
+// the source of the request: "body", "path" or "query".
+func FieldErrors(err error, source string) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:  fieldName(fe),
+				Reason: reason(fe),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:  typeErr.Field,
+			Reason: "must be of type " + jsonType(typeErr.Type),
+		}}
+	}
+
+	var sizeErr *http.MaxBytesError
+	if errors.As(err, &sizeErr) {
+		return []FieldError{{
+			Field:  source,
+			Reason: "must be at most " + strconv.FormatInt(sizeErr.Limit, 10) + " bytes long",
+		}}
+	}
+
+	// encoding/json has no type for unknown field errors.
+	if field, ok := strings.CutPrefix(err.Error(), `json: unknown field "`); ok {
+		return []FieldError{{
+			Field:  strings.TrimSuffix(field, `"`),
+			Reason: "is not allowed",
+		}}
+	}
+
+	if source == "body" {
+		return []FieldError{{Field: source, Reason: "must be a valid JSON object"}}
+	}
+	return []FieldError{{Field: source, Reason: "is malformed"}}
+}
+
+// fieldName returns the path of the field without the name of the request struct.
+func fieldName(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func reason(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		if fe.Kind() == reflect.String {
+			return "must be at most " + fe.Param() + " characters long"
+		}
+		return "must be at most " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.String {
+			return "must be at least " + fe.Param() + " characters long"
+		}
+		return "must be at least " + fe.Param()
+	case "uuid":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
+
+// jsonType names the JSON type matching the Go type.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
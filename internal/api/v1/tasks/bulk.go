@@ -0,0 +1,77 @@
+package tasks
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/request"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+)
+
+type bulkQuery struct {
+	// Selector chooses the tasks by their labels. It is required,
+	// so a request can't act on every task of the namespace by mistake.
+	Selector string `form:"selector" binding:"required"`
+}
+
+type bulkResponse struct {
+	// TaskUUIDs lists the tasks the request changed.
+	TaskUUIDs []string `json:"task_uuids"`
+}
+
+func (h *handler) cancelAll(c *gin.Context) {
+	var query bulkQuery
+	if !request.BindQuery(c, &query) {
+		return
+	}
+	selector, ok := bindBulkSelector(c, query.Selector)
+	if !ok {
+		return
+	}
+
+	uuids, err := h.taskService.CancelAll(c, requestNamespace(c), selector)
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, newBulkResponse(uuids))
+}
+
+func (h *handler) deleteAll(c *gin.Context) {
+	var query bulkQuery
+	if !request.BindQuery(c, &query) {
+		return
+	}
+	selector, ok := bindBulkSelector(c, query.Selector)
+	if !ok {
+		return
+	}
+
+	uuids, err := h.taskService.DeleteAll(c, requestNamespace(c), selector)
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, newBulkResponse(uuids))
+}
+
+// bindBulkSelector parses the selector of a bulk request like [bindSelector].
+// A selector without requirements, e.g. a blank one, matches every task,
+// so it is rejected like a missing one.
+func bindBulkSelector(c *gin.Context, raw string) (domain.Selector, bool) {
+	selector, ok := bindSelector(c, raw)
+	if ok && len(selector) == 0 {
+		response.NewValidationErr(c, "Invalid query parameters", []response.FieldError{{
+			Field:  "selector",
+			Reason: "must have at least one requirement",
+		}})
+		return nil, false
+	}
+	return selector, ok
+}
+
+func newBulkResponse(uuids []string) bulkResponse {
+	if uuids == nil {
+		uuids = []string{}
+	}
+	return bulkResponse{TaskUUIDs: uuids}
+}
@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+)
+
+// Headers of conditional requests. The entity tag of a task
+// is its version, see [domain.Task.Version].
+const (
+	etagHeader        = "ETag"
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+// etag formats the version of a task as a strong entity tag.
+func etag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// bindIfMatch returns the version of the task required by the If-Match
+// header, zero if the header is absent or "*": the task only has to exist.
+// Lists and weak tags are not supported as a change applies to a single
+// version. If the header is malformed, it responds with
+// [response.ProblemValidation] and returns false.
+func bindIfMatch(c *gin.Context) (version uint64, ok bool) {
+	header := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	unquoted, quoted := strings.CutPrefix(header, `"`)
+	unquoted, closed := strings.CutSuffix(unquoted, `"`)
+	version, err := strconv.ParseUint(unquoted, 10, 64)
+	if !quoted || !closed || err != nil || version == 0 {
+		response.NewValidationErr(c, "Invalid request headers", []response.FieldError{{
+			Field:  ifMatchHeader,
+			Reason: `must be "*" or a single entity tag from the ETag header`,
+		}})
+		return 0, false
+	}
+	return version, true
+}
+
+// notModified reports whether the If-None-Match header lists the entity
+// tag of the version, i.e. the copy of the client is up to date.
+// Weak tags match as well, as the comparison for If-None-Match is weak.
+func notModified(c *gin.Context, version uint64) bool {
+	header := strings.TrimSpace(c.GetHeader(ifNoneMatchHeader))
+	if header == "*" {
+		return true
+	}
+
+	tag := etag(version)
+	for candidate := range strings.SplitSeq(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/request"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/service"
+)
+
+const (
+	// EventStreamContentType is the media type of server-sent events.
+	EventStreamContentType = "text/event-stream"
+
+	// eventsBuffer is the number of events waiting to be written
+	// to a stream before the stream is ended.
+	eventsBuffer = 64
+
+	// heartbeatInterval is how often an idle stream gets a comment line,
+	// so proxies and clients do not consider it dead.
+	heartbeatInterval = 15 * time.Second
+)
+
+// taskEvent is the data of a server-sent event, the event name is its type.
+type taskEvent struct {
+	Type      string    `json:"type"`
+	Namespace string    `json:"namespace"`
+	TaskUUID  string    `json:"task_uuid"`
+	Time      time.Time `json:"time"`
+	// Status and Version are set for status events.
+	Status  string `json:"status,omitempty"`
+	Version uint64 `json:"version,omitempty"`
+	// Progress is the done share of the task in percent, set for progress events.
+	Progress *int `json:"progress,omitempty"`
+	// Message is set for log events.
+	Message string `json:"message,omitempty"`
+}
+
+// events streams events of the task as server-sent events. The stream
+// starts with a status event of the current status and ends after the
+// status event of a terminal status. A client that falls behind gets
+// its stream ended and should reconnect.
+func (h *handler) events(c *gin.Context) {
+	var uri taskURI
+	if !request.BindURI(c, &uri) {
+		return
+	}
+	namespace := requestNamespace(c)
+
+	// Subscribe before reading the task, so no change
+	// between the read and the subscription is missed.
+	sub := h.broker.Subscribe(eventsBuffer, func(event events.Event) bool {
+		return event.TaskUUID == uri.UUID && event.Namespace == namespace
+	})
+	defer sub.Close()
+
+	task, err := h.taskService.Get(c, namespace, uri.UUID)
+	if errors.Is(err, service.ErrNotFound) {
+		response.NewErr(c, response.ProblemNotFound, "Task not found")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	// The stream outlives the write timeout of the server.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		_ = c.Error(err)
+	}
+
+	c.Header("Content-Type", EventStreamContentType)
+	c.Header("Cache-Control", "no-cache")
+	// Keep reverse proxies from buffering the stream.
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !writeEvent(c, events.Event{
+		Type:      events.TypeStatus,
+		Namespace: task.Namespace,
+		TaskUUID:  task.UUID,
+		Time:      time.Now(),
+		Status:    task.Status,
+		Version:   task.Version,
+	}) || task.Status.IsTerminal() {
+		return
+	}
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-sub.Events():
+			if !ok {
+				_ = c.Error(sub.Err())
+				return
+			}
+			if !writeEvent(c, event) {
+				return
+			}
+			if event.Type == events.TypeStatus && event.Status.IsTerminal() {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent writes and flushes the event, it reports
+// whether the client can still be written to.
+func writeEvent(c *gin.Context, event events.Event) bool {
+	data := taskEvent{
+		Type:      string(event.Type),
+		Namespace: event.Namespace,
+		TaskUUID:  event.TaskUUID,
+		Time:      event.Time,
+		Status:    string(event.Status),
+		Version:   event.Version,
+		Message:   event.Message,
+	}
+	if event.Type == events.TypeProgress {
+		data.Progress = &event.Progress
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		_ = c.Error(err)
+		return false
+	}
+
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, encoded); err != nil {
+		return false
+	}
+	c.Writer.Flush()
+
+	return true
+}
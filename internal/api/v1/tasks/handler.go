@@ -2,31 +2,52 @@ package tasks
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/events"
 	"github.com/passwordhash/task-manager-api/internal/service"
 )
 
 type handler struct {
 	taskService service.TaskService
+	broker      *events.Broker
+	// stop is closed when the server shuts down to end event streams.
+	stop <-chan struct{}
 }
 
 func NewHandler(
 	taskService service.TaskService,
+	broker *events.Broker,
+	stop <-chan struct{},
 ) *handler {
 	return &handler{
 		taskService: taskService,
+		broker:      broker,
+		stop:        stop,
 	}
 }
 
+// RegisterRoutes registers the task routes of the default namespace
+// under /tasks and of every other namespace under /namespaces/:ns/tasks.
 func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
-	tasksGroup := router.Group("/tasks")
-	{
-		tasksGroup.GET("/", h.list)
-		tasksGroup.POST("/", h.create)
+	h.registerTaskRoutes(router.Group("/tasks", h.namespace))
+	h.registerTaskRoutes(router.Group("/namespaces/:"+namespaceParam+"/tasks", h.namespace))
+}
 
-		taskGroup := tasksGroup.Group("/:uuid")
-		{
-			taskGroup.GET("/status", h.status)
-			taskGroup.POST("/cancel", h.cancel)
-		}
+func (h *handler) registerTaskRoutes(tasksGroup *gin.RouterGroup) {
+	tasksGroup.GET("/", middleware.RequireScope(auth.ScopeTasksRead), h.list)
+	tasksGroup.POST("/", middleware.RequireScope(auth.ScopeTasksCreate), h.create)
+	tasksGroup.DELETE("/", middleware.RequireScope(auth.ScopeTasksDelete), h.deleteAll)
+	tasksGroup.POST("/cancel", middleware.RequireScope(auth.ScopeTasksCancel), h.cancelAll)
+
+	taskGroup := tasksGroup.Group("/:uuid")
+	{
+		taskGroup.DELETE("", middleware.RequireScope(auth.ScopeTasksDelete), h.delete)
+		taskGroup.PATCH("", middleware.RequireScope(auth.ScopeTasksUpdate), h.patch)
+		taskGroup.GET("/status", middleware.RequireScope(auth.ScopeTasksRead), h.status)
+		taskGroup.GET("/events", middleware.RequireScope(auth.ScopeTasksRead), h.events)
+		taskGroup.GET("/history", middleware.RequireScope(auth.ScopeTasksRead), h.history)
+		taskGroup.POST("/cancel", middleware.RequireScope(auth.ScopeTasksCancel), h.cancel)
+		taskGroup.POST("/retry", middleware.RequireScope(auth.ScopeTasksCreate), h.retry)
 	}
 }
@@ -0,0 +1,75 @@
+package tasks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/request"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/service"
+)
+
+// transition is a change of the status of a task. Edits of labels,
+// description or priority are recorded with From equal to To.
+type transition struct {
+	// From is omitted for the transition creating the task.
+	From string    `json:"from,omitempty"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+	// Actor is the API client that caused the transition, "system"
+	// for transitions made by the service and "anonymous" for clients
+	// when authentication is disabled.
+	Actor  string `json:"actor"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type historyResponse struct {
+	TaskUUID string       `json:"task_uuid"`
+	Status   string       `json:"status"`
+	History  []transition `json:"history"`
+	// QueueWait is the time the task waited in the queue,
+	// ExecutionTime is the time it has been running.
+	QueueWait     string `json:"queue_wait"`
+	ExecutionTime string `json:"execution_time"`
+}
+
+func newHistoryResponse(task domain.Task) historyResponse {
+	history := make([]transition, 0, len(task.History))
+	for _, t := range task.History {
+		history = append(history, transition{
+			From:   string(t.From),
+			To:     string(t.To),
+			At:     t.At,
+			Actor:  t.Actor,
+			Reason: t.Reason,
+		})
+	}
+
+	return historyResponse{
+		TaskUUID:      task.UUID,
+		Status:        string(task.Status),
+		History:       history,
+		QueueWait:     task.QueueWait().String(),
+		ExecutionTime: task.ExecutionTime().String(),
+	}
+}
+
+func (h *handler) history(c *gin.Context) {
+	var uri taskURI
+	if !request.BindURI(c, &uri) {
+		return
+	}
+
+	task, err := h.taskService.Get(c, requestNamespace(c), uri.UUID)
+	if errors.Is(err, service.ErrNotFound) {
+		response.NewErr(c, response.ProblemNotFound, "Task not found")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, newHistoryResponse(task))
+}
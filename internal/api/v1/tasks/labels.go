@@ -0,0 +1,51 @@
+package tasks
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+)
+
+// labelErrors validates label keys and values, a nil value
+// stands for a label that is removed.
+func labelErrors(labels map[string]*string) []response.FieldError {
+	var fields []response.FieldError
+	if len(labels) > domain.MaxLabels {
+		fields = append(fields, response.FieldError{
+			Field:  "labels",
+			Reason: "must have at most " + strconv.Itoa(domain.MaxLabels) + " labels",
+		})
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		if err := domain.ValidateLabelKey(key); err != nil {
+			fields = append(fields, response.FieldError{Field: "labels", Reason: "key " + strconv.Quote(key) + " " + err.Error()})
+			continue
+		}
+		if value := labels[key]; value != nil {
+			if err := domain.ValidateLabelValue(*value); err != nil {
+				fields = append(fields, response.FieldError{Field: "labels." + key, Reason: err.Error()})
+			}
+		}
+	}
+
+	return fields
+}
+
+// bindSelector parses the label selector from the selector query parameter.
+// If it is invalid, it responds with [response.ProblemValidation] and returns false.
+func bindSelector(c *gin.Context, raw string) (domain.Selector, bool) {
+	selector, err := domain.ParseSelector(raw)
+	if err != nil {
+		response.NewValidationErr(c, "Invalid query parameters", []response.FieldError{{
+			Field:  "selector",
+			Reason: err.Error(),
+		}})
+		return nil, false
+	}
+	return selector, true
+}
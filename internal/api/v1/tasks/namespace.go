@@ -0,0 +1,46 @@
+package tasks
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+)
+
+const (
+	namespaceParam = "ns"
+	namespaceKey   = "namespace"
+)
+
+// namespace resolves the namespace of the request from the path,
+// [domain.DefaultNamespace] for routes without it, and checks
+// that the principal may access it.
+func (h *handler) namespace(c *gin.Context) {
+	ns := c.Param(namespaceParam)
+	if ns == "" {
+		ns = domain.DefaultNamespace
+	}
+
+	if !domain.ValidNamespace(ns) {
+		response.NewValidationErr(c, "Invalid path parameters", []response.FieldError{{
+			Field:  namespaceParam,
+			Reason: "must be a DNS label of at most 63 characters",
+		}})
+		c.Abort()
+		return
+	}
+
+	if principal, ok := auth.FromContext(c.Request.Context()); ok && !principal.CanAccess(ns) {
+		response.NewErr(c, response.ProblemForbidden, "Credentials do not grant access to the namespace")
+		c.Abort()
+		return
+	}
+
+	c.Set(namespaceKey, ns)
+	c.Next()
+}
+
+// requestNamespace returns the namespace resolved by [handler.namespace].
+func requestNamespace(c *gin.Context) string {
+	return c.GetString(namespaceKey)
+}
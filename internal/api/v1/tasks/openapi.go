@@ -0,0 +1,223 @@
+package tasks
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+	"github.com/passwordhash/task-manager-api/internal/api/openapi"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+)
+
+const tag = "tasks"
+
+const selectorDescription = "A `selector` is a comma-separated list of label requirements that all must match: " +
+	"`key=value`, `key!=value`, `key in (v1,v2)`, `key notin (v1,v2)`, `key` and `!key`, " +
+	"e.g. `env=prod,batch in (a,b),!draft`."
+
+// Routes describes the routes registered by RegisterRoutes
+// on the group with the base path.
+func Routes(base string) []openapi.Route {
+	nsParam := openapi.Parameter{
+		Name:        namespaceParam,
+		In:          "path",
+		Description: "Namespace of the tasks.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string", Pattern: domain.NamespacePattern},
+	}
+
+	routes := taskRoutes(base+"/tasks", "", nil)
+	return append(routes, taskRoutes(base+"/namespaces/:"+namespaceParam+"/tasks", "InNamespace", &nsParam)...)
+}
+
+// taskRoutes describes the routes of registerTaskRoutes. The suffix
+// makes operation IDs unique, nsParam describes the namespace path parameter.
+func taskRoutes(base, suffix string, nsParam *openapi.Parameter) []openapi.Route {
+	var params []openapi.Parameter
+	if nsParam != nil {
+		params = append(params, *nsParam)
+	}
+	uuidParams := slices.Concat(params, []openapi.Parameter{{
+		Name:     "uuid",
+		In:       "path",
+		Required: true,
+		Schema:   &openapi.Schema{Type: "string", Format: "uuid"},
+	}})
+	idempotencyParam := openapi.Parameter{
+		Name: middleware.IdempotencyKeyHeader,
+		In:   "header",
+		Description: "Makes the request safe to retry: a request repeating the key gets the response " +
+			"to the first one with the `" + middleware.IdempotentReplayedHeader + "` header and is not served again.",
+		Schema: &openapi.Schema{Type: "string", MaxLength: ptr(middleware.MaxIdempotencyKeyLength)},
+	}
+	idempotencyProblems := []response.ProblemType{
+		response.ProblemIdempotencyKeyInUse, response.ProblemIdempotencyKeyReused,
+	}
+	ifMatchParam := openapi.Parameter{
+		Name: ifMatchHeader,
+		In:   "header",
+		Description: "Applies the change only if the task still has the version from the `" + etagHeader +
+			"` header of its status, `*` or no header applies it to any version.",
+		Schema: &openapi.Schema{Type: "string"},
+	}
+	tenantParam := openapi.Parameter{
+		Name:        tenantHeader,
+		In:          "header",
+		Description: "Tenant of the task when authentication is disabled, `default` if empty.",
+		Schema:      &openapi.Schema{Type: "string", MaxLength: ptr(maxTenantLength)},
+	}
+
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: base + "/", ID: "listTasks" + suffix, Tag: tag,
+			Summary: "List tasks of the namespace",
+			Description: "Tasks are ordered by creation time. With `limit` the response holds a page of tasks " +
+				"and `next_page_token` to pass as `page_token` to get the next one. " + selectorDescription,
+			Scope:      string(auth.ScopeTasksRead),
+			Parameters: params,
+			Query:      listTasksQuery{},
+			Response:   listTasksResponse{},
+			Problems:   []response.ProblemType{response.ProblemValidation, response.ProblemTooManyRequests},
+		},
+		{
+			Method: http.MethodPost, Path: base + "/", ID: "createTask" + suffix, Tag: tag,
+			Summary:         "Create a task",
+			Description:     "Without a body the task gets the `default` type.",
+			Scope:           string(auth.ScopeTasksCreate),
+			Parameters:      slices.Concat(params, []openapi.Parameter{tenantParam, idempotencyParam}),
+			Request:         createTaskRequest{},
+			RequestOptional: true,
+			Response:        createTaskResponse{},
+			Problems: slices.Concat([]response.ProblemType{
+				response.ProblemValidation, response.ProblemQuotaExceeded, response.ProblemTooManyRequests,
+			}, idempotencyProblems),
+		},
+		{
+			Method: http.MethodPost, Path: base + "/cancel", ID: "cancelTasks" + suffix, Tag: tag,
+			Summary: "Cancel the tasks matching a label selector",
+			Description: "Cancels the pending and running tasks matching `selector` and lists them, " +
+				"tasks that have already finished are skipped. " + selectorDescription,
+			Scope:      string(auth.ScopeTasksCancel),
+			Parameters: slices.Concat(params, []openapi.Parameter{idempotencyParam}),
+			Query:      bulkQuery{},
+			Response:   bulkResponse{},
+			Problems: slices.Concat([]response.ProblemType{
+				response.ProblemValidation, response.ProblemTooManyRequests,
+			}, idempotencyProblems),
+		},
+		{
+			Method: http.MethodDelete, Path: base + "/", ID: "deleteTasks" + suffix, Tag: tag,
+			Summary: "Delete the tasks matching a label selector",
+			Description: "Deletes the tasks in a terminal status matching `selector` and lists them, " +
+				"tasks that are still pending or running are skipped. " + selectorDescription,
+			Scope:      string(auth.ScopeTasksDelete),
+			Parameters: slices.Concat(params, []openapi.Parameter{idempotencyParam}),
+			Query:      bulkQuery{},
+			Response:   bulkResponse{},
+			Problems: slices.Concat([]response.ProblemType{
+				response.ProblemValidation, response.ProblemTooManyRequests,
+			}, idempotencyProblems),
+		},
+		{
+			Method: http.MethodGet, Path: base + "/:uuid/status", ID: "getTaskStatus" + suffix, Tag: tag,
+			Summary: "Get the status of a task",
+			Description: "The `" + etagHeader + "` header holds the version of the task. A request with the header " +
+				"in `" + ifNoneMatchHeader + "` gets an empty `304` response until the task changes.",
+			Scope: string(auth.ScopeTasksRead),
+			Parameters: slices.Concat(uuidParams, []openapi.Parameter{{
+				Name:   ifNoneMatchHeader,
+				In:     "header",
+				Schema: &openapi.Schema{Type: "string"},
+			}}),
+			Response:    statusResponse{},
+			NotModified: true,
+			Problems: []response.ProblemType{
+				response.ProblemValidation, response.ProblemNotFound, response.ProblemTooManyRequests,
+			},
+		},
+		{
+			Method: http.MethodGet, Path: base + "/:uuid/history", ID: "getTaskHistory" + suffix, Tag: tag,
+			Summary: "Get the status history of a task",
+			Description: "Status transitions of the task from its creation, with the client that caused them. " +
+				"Queue wait and execution time are computed from the transitions.",
+			Scope:      string(auth.ScopeTasksRead),
+			Parameters: uuidParams,
+			Response:   historyResponse{},
+			Problems: []response.ProblemType{
+				response.ProblemValidation, response.ProblemNotFound, response.ProblemTooManyRequests,
+			},
+		},
+		{
+			Method: http.MethodPost, Path: base + "/:uuid/cancel", ID: "cancelTask" + suffix, Tag: tag,
+			Summary:    "Cancel a pending or running task",
+			Scope:      string(auth.ScopeTasksCancel),
+			Parameters: slices.Concat(uuidParams, []openapi.Parameter{ifMatchParam, idempotencyParam}),
+			Response:   response.Message{},
+			Problems: slices.Concat([]response.ProblemType{
+				response.ProblemValidation, response.ProblemNotFound, response.ProblemPreconditionFailed,
+				response.ProblemCantCancel, response.ProblemStatusConflict, response.ProblemTooManyRequests,
+			}, idempotencyProblems),
+		},
+		{
+			Method: http.MethodDelete, Path: base + "/:uuid", ID: "deleteTask" + suffix, Tag: tag,
+			Summary:     "Delete a finished task",
+			Description: "Only tasks in a terminal status can be deleted.",
+			Scope:       string(auth.ScopeTasksDelete),
+			Parameters:  slices.Concat(uuidParams, []openapi.Parameter{ifMatchParam, idempotencyParam}),
+			Response:    response.Message{},
+			Problems: slices.Concat([]response.ProblemType{
+				response.ProblemValidation, response.ProblemNotFound, response.ProblemPreconditionFailed,
+				response.ProblemCantDelete, response.ProblemTooManyRequests,
+			}, idempotencyProblems),
+		},
+		{
+			Method: http.MethodPatch, Path: base + "/:uuid", ID: "patchTask" + suffix, Tag: tag,
+			Summary: "Edit labels, description, priority and start time of a task",
+			Description: "A JSON merge patch (RFC 7396): omitted fields are kept, a label set to `null` is removed, " +
+				"`null` labels or description are removed, `null` priority is reset to 0 and `null` run_at " +
+				"lets the task run right away. Labels and description can be edited at any time, priority and " +
+				"run_at only while the task is pending: a new priority moves the task in the queue of its tenant, " +
+				"run_at (in the future) holds it in the queue until then. Every edit is recorded in the history.",
+			Scope:      string(auth.ScopeTasksUpdate),
+			Parameters: slices.Concat(uuidParams, []openapi.Parameter{ifMatchParam, idempotencyParam}),
+			Request:    patchTaskRequest{},
+			Response:   statusResponse{},
+			Problems: slices.Concat([]response.ProblemType{
+				response.ProblemValidation, response.ProblemNotFound, response.ProblemPreconditionFailed,
+				response.ProblemCantUpdate, response.ProblemTooManyRequests,
+			}, idempotencyProblems),
+		},
+		{
+			Method: http.MethodPost, Path: base + "/:uuid/retry", ID: "retryTask" + suffix, Tag: tag,
+			Summary:     "Retry a finished task",
+			Description: "Creates a new attempt with the type and payload of a task in a terminal status.",
+			Scope:       string(auth.ScopeTasksCreate),
+			Parameters:  slices.Concat(uuidParams, []openapi.Parameter{idempotencyParam}),
+			Response:    retryTaskResponse{},
+			Problems: slices.Concat([]response.ProblemType{
+				response.ProblemValidation, response.ProblemNotFound, response.ProblemCantRetry,
+				response.ProblemQuotaExceeded, response.ProblemTooManyRequests,
+			}, idempotencyProblems),
+		},
+		{
+			Method: http.MethodGet, Path: base + "/:uuid/events", ID: "watchTask" + suffix, Tag: tag,
+			Summary: "Stream events of a task",
+			Description: "Server-sent events named after their type: `status`, `progress` and `log`. " +
+				"The stream starts with the current status and ends after a terminal one. " +
+				"A client that does not keep up has its stream ended and should reconnect.",
+			Scope:               string(auth.ScopeTasksRead),
+			Parameters:          uuidParams,
+			Response:            taskEvent{},
+			ResponseContentType: EventStreamContentType,
+			Problems: []response.ProblemType{
+				response.ProblemValidation, response.ProblemNotFound, response.ProblemTooManyRequests,
+			},
+		},
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
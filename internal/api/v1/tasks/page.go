@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+)
+
+var errMalformedPageToken = errors.New("malformed page token")
+
+// pageToken marks the last task of a page, the next page starts after it.
+// Since it does not refer to a position, deleting or adding tasks
+// between the requests of two pages does not skip or repeat tasks.
+type pageToken struct {
+	createdAt time.Time
+	uuid      string
+}
+
+func (p pageToken) String() string {
+	raw := strconv.FormatInt(p.createdAt.UnixNano(), 10) + "/" + p.uuid
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func parsePageToken(s string) (pageToken, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageToken{}, errMalformedPageToken
+	}
+
+	nanos, uuid, ok := strings.Cut(string(raw), "/")
+	if !ok || uuid == "" {
+		return pageToken{}, errMalformedPageToken
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return pageToken{}, errMalformedPageToken
+	}
+
+	return pageToken{createdAt: time.Unix(0, unixNano), uuid: uuid}, nil
+}
+
+// after reports whether the task goes after the token in [domain.CompareCreation] order.
+func (p pageToken) after(task domain.Task) bool {
+	return domain.CompareCreation(task, domain.Task{CreatedAt: p.createdAt, UUID: p.uuid}) > 0
+}
+
+// paginate returns the page of at most limit tasks following the token,
+// all of them if limit is zero, and the token of the next page,
+// empty if it is the last one. tasks must be ordered by [domain.CompareCreation].
+func paginate(tasks []domain.Task, token *pageToken, limit int) ([]domain.Task, string) {
+	if token != nil {
+		start := len(tasks)
+		for i, task := range tasks {
+			if token.after(task) {
+				start = i
+				break
+			}
+		}
+		tasks = tasks[start:]
+	}
+
+	if limit == 0 || len(tasks) <= limit {
+		return tasks, ""
+	}
+
+	last := tasks[limit-1]
+	return tasks[:limit], pageToken{createdAt: last.CreatedAt, uuid: last.UUID}.String()
+}
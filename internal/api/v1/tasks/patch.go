@@ -0,0 +1,143 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/request"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/service"
+)
+
+// patchTaskRequest is a JSON merge patch of a task (RFC 7396):
+// omitted fields are kept, a field set to null is reset and
+// a label set to null is removed.
+type patchTaskRequest struct {
+	Labels      map[string]*string `json:"labels"`
+	Description *string            `json:"description" binding:"omitempty,max=1024"`
+	// Priority can only be changed while the task is pending.
+	Priority *int `json:"priority" binding:"omitempty,min=-100,max=100"`
+	// RunAt holds the task in the queue until then. It must be in the
+	// future and can only be changed while the task is pending.
+	RunAt *time.Time `json:"run_at"`
+
+	// clearLabels is set by "labels": null.
+	clearLabels bool
+}
+
+// UnmarshalJSON decodes the patch like encoding/json with unknown fields
+// disallowed, but tells fields set to null from omitted ones: null removes
+// all labels, clears the description, resets the priority to 0 and lets
+// the task run right away.
+func (r *patchTaskRequest) UnmarshalJSON(data []byte) error {
+	// plain has the fields of patchTaskRequest without this method.
+	type plain patchTaskRequest
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode((*plain)(r)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	r.clearLabels = isNull(fields["labels"])
+	if isNull(fields["description"]) {
+		r.Description = new(string)
+	}
+	if isNull(fields["priority"]) {
+		r.Priority = new(int)
+	}
+	if isNull(fields["run_at"]) {
+		r.RunAt = new(time.Time)
+	}
+	return nil
+}
+
+func isNull(value json.RawMessage) bool {
+	return string(value) == "null"
+}
+
+func (h *handler) patch(c *gin.Context) {
+	var uri taskURI
+	if !request.BindURI(c, &uri) {
+		return
+	}
+
+	var req patchTaskRequest
+	if !request.BindJSON(c, &req) || !validatePatch(c, req) {
+		return
+	}
+
+	version, ok := bindIfMatch(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.taskService.Update(c, requestNamespace(c), uri.UUID, service.UpdateTaskParams{
+		Labels:      req.Labels,
+		ClearLabels: req.clearLabels,
+		Description: req.Description,
+		Priority:    req.Priority,
+		RunAt:       req.RunAt,
+	}, version)
+	if errors.Is(err, service.ErrNotFound) {
+		response.NewErr(c, response.ProblemNotFound, "Task not found")
+		return
+	}
+	if errors.Is(err, service.ErrVersionMismatch) {
+		response.NewErr(c, response.ProblemPreconditionFailed, "Task has changed since the version in If-Match")
+		return
+	}
+	if errors.Is(err, service.ErrCantUpdate) {
+		response.NewErr(c, response.ProblemCantUpdate, "Priority and start time can only be changed while the task is pending")
+		return
+	}
+	if errors.Is(err, service.ErrTooManyLabels) {
+		response.NewValidationErr(c, "Invalid request body", []response.FieldError{{
+			Field:  "labels",
+			Reason: "must leave the task with at most " + strconv.Itoa(domain.MaxLabels) + " labels",
+		}})
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	c.Header(etagHeader, etag(task.Version))
+	response.NewOk(c, newStatusResponse(task))
+}
+
+// validatePatch checks the labels and the start time of the patch and
+// that it changes anything. If it is invalid, it responds with
+// [response.ProblemValidation] and returns false.
+func validatePatch(c *gin.Context, req patchTaskRequest) bool {
+	if req.Labels == nil && !req.clearLabels && req.Description == nil && req.Priority == nil && req.RunAt == nil {
+		response.NewValidationErr(c, "Invalid request body", []response.FieldError{{
+			Field:  "body",
+			Reason: "must change labels, description, priority or run_at",
+		}})
+		return false
+	}
+
+	if req.RunAt != nil && !req.RunAt.IsZero() && !req.RunAt.After(time.Now()) {
+		response.NewValidationErr(c, "Invalid request body", []response.FieldError{{
+			Field:  "run_at",
+			Reason: "must be in the future",
+		}})
+		return false
+	}
+
+	if fields := labelErrors(req.Labels); len(fields) > 0 {
+		response.NewValidationErr(c, "Invalid request body", fields)
+		return false
+	}
+	return true
+}
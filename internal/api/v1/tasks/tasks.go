@@ -4,13 +4,34 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/request"
 	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/domain"
 	"github.com/passwordhash/task-manager-api/internal/service"
 )
 
+// tenantHeader identifies the tenant that owns created tasks
+// when authentication is disabled.
+const tenantHeader = "X-Tenant-ID"
+
+const maxTenantLength = 64
+
+type createTaskRequest struct {
+	Type    string `json:"type" binding:"omitempty,max=64"`
+	Payload any    `json:"payload"`
+	// Priority orders the task among queued tasks of its tenant,
+	// the highest first.
+	Priority int `json:"priority" binding:"min=-100,max=100"`
+	// Labels group tasks to select them on list, bulk cancel and delete.
+	Labels      map[string]string `json:"labels"`
+	Description string            `json:"description" binding:"max=1024"`
+}
+
 type createTaskResponse struct {
 	TaskUUID string `json:"task_uuid"`
 }
@@ -19,7 +40,53 @@ func (h *handler) create(c *gin.Context) {
 	ctx, cancel := context.WithCancel(c)
 	defer cancel()
 
-	uuid, err := h.taskService.CreateTask(ctx)
+	// The body is optional, a task without it gets the default type.
+	var req createTaskRequest
+	if !request.BindOptionalJSON(c, &req) {
+		return
+	}
+
+	labels := make(map[string]*string, len(req.Labels))
+	for key, value := range req.Labels {
+		labels[key] = &value
+	}
+	if fields := labelErrors(labels); len(fields) > 0 {
+		response.NewValidationErr(c, "Invalid request body", fields)
+		return
+	}
+
+	// An authenticated client always creates tasks of its own tenant.
+	var owner string
+	tenant := c.GetHeader(tenantHeader)
+	if principal, ok := auth.FromContext(ctx); ok {
+		owner, tenant = principal.Name, principal.Tenant
+	}
+	if len(tenant) > maxTenantLength {
+		response.NewValidationErr(c, "Invalid request headers", []response.FieldError{{
+			Field:  tenantHeader,
+			Reason: "must be at most " + strconv.Itoa(maxTenantLength) + " characters long",
+		}})
+		return
+	}
+
+	uuid, err := h.taskService.CreateTask(ctx, service.CreateTaskParams{
+		Namespace:   requestNamespace(c),
+		Type:        req.Type,
+		Payload:     req.Payload,
+		Tenant:      tenant,
+		Owner:       owner,
+		Priority:    req.Priority,
+		Labels:      req.Labels,
+		Description: req.Description,
+	})
+	if errors.Is(err, service.ErrTenantLimit) {
+		response.NewErr(c, response.ProblemTooManyRequests, "Too many queued or running tasks for the tenant")
+		return
+	}
+	if errors.Is(err, service.ErrNamespaceQuota) {
+		response.NewErr(c, response.ProblemQuotaExceeded, "The namespace has reached its task quota")
+		return
+	}
 	if response.HandleError(c, err) {
 		return
 	}
@@ -27,88 +94,211 @@ func (h *handler) create(c *gin.Context) {
 	response.NewOk(c, createTaskResponse{TaskUUID: uuid})
 }
 
+// taskURI holds the path parameters of the routes of a single task.
+type taskURI struct {
+	UUID string `uri:"uuid" binding:"required,uuid"`
+}
+
 type statusResponse struct {
+	UUID      string `json:"uuid"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	Tenant    string `json:"tenant"`
+	Owner     string `json:"owner,omitempty"`
 	Status    string `json:"status"`
 	CreatedAt string `json:"created_at"`
-	Duration  string `json:"duration"`
+	// StartedAt is set once a worker takes the task.
+	StartedAt string `json:"started_at,omitempty"`
+	// Duration is the running time of a finished task. The body only
+	// changes with the version, which is its ETag, so the running time
+	// of a running task is left to clients, see StartedAt.
+	Duration string `json:"duration,omitempty"`
+
+	Labels      map[string]string `json:"labels,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Priority    int               `json:"priority"`
+	// RunAt is the time the task is held in the queue until, if any.
+	RunAt string `json:"run_at,omitempty"`
 
 	Result any    `json:"result,omitempty"`
 	Error  string `json:"error,omitempty"`
+
+	RetryOf string   `json:"retry_of,omitempty"`
+	Retries []string `json:"retries,omitempty"`
+
+	RequestID string `json:"request_id,omitempty"`
+
+	// Version is the version of the task, also returned as the ETag header.
+	Version uint64 `json:"version"`
 }
 
 func (h *handler) status(c *gin.Context) {
-	uuid := c.Param("uuid")
-	if uuid == "" {
-		response.NewErr(c, http.StatusBadRequest, response.ErrBadRequestParams, "Task UUID is required")
+	var uri taskURI
+	if !request.BindURI(c, &uri) {
 		return
 	}
 
-	task, err := h.taskService.Get(c, uuid)
+	task, err := h.taskService.Get(c, requestNamespace(c), uri.UUID)
 	if errors.Is(err, service.ErrNotFound) {
-		response.NewErr(c, http.StatusNotFound, response.ErrNotFound, "Task not found")
+		response.NewErr(c, response.ProblemNotFound, "Task not found")
 		return
 	}
 	if response.HandleError(c, err) {
 		return
 	}
 
+	c.Header(etagHeader, etag(task.Version))
+	if notModified(c, task.Version) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	response.NewOk(c, newStatusResponse(task))
+}
+
+func newStatusResponse(task domain.Task) statusResponse {
 	var taskErrResp string
 	if task.Error != nil {
 		taskErrResp = task.Error.Error()
 	}
+	var runAt, startedAt, duration string
+	if !task.RunAt.IsZero() {
+		runAt = task.RunAt.Format(time.RFC3339)
+	}
+	if !task.StartedAt.IsZero() {
+		startedAt = task.StartedAt.Format(time.RFC3339Nano)
+	}
+	if task.Status.IsTerminal() {
+		duration = task.RunningDuration().String()
+	}
 
-	response.NewOk(c, statusResponse{
+	return statusResponse{
+		UUID:      task.UUID,
+		Namespace: task.Namespace,
+		Type:      task.Type,
+		Tenant:    task.Tenant,
+		Owner:     task.Owner,
 		Status:    string(task.Status),
 		CreatedAt: task.CreatedAt.Format(time.RFC3339),
-		Duration:  task.RunningDuration().String(),
+		StartedAt: startedAt,
+		Duration:  duration,
+
+		Labels:      task.Labels,
+		Description: task.Description,
+		Priority:    task.Priority,
+		RunAt:       runAt,
 
 		Result: task.Result,
 		Error:  taskErrResp,
-	})
+
+		RetryOf: task.RetryOf,
+		Retries: task.Retries,
+
+		RequestID: task.RequestID,
+		Version:   task.Version,
+	}
 }
 
 type task struct {
-	UUID   string `json:"uuid"`
-	Status string `json:"status"`
-	Result string `json:"result,omitempty"`
-	Error  string `json:"error,omitempty"`
+	UUID      string            `json:"uuid"`
+	Type      string            `json:"type"`
+	Tenant    string            `json:"tenant"`
+	Status    string            `json:"status"`
+	CreatedAt string            `json:"created_at"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Result    string            `json:"result,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type listTasksQuery struct {
+	// Limit is the maximum number of tasks in the response, all tasks if omitted.
+	Limit int `form:"limit" binding:"omitempty,min=1,max=1000"`
+	// PageToken is next_page_token of the previous page.
+	PageToken string `form:"page_token"`
+	// Selector filters the tasks by their labels, e.g. "env=prod,batch in (a,b),!draft".
+	Selector string `form:"selector"`
 }
 
 type listTasksResponse struct {
+	// Tasks are ordered by creation time.
 	Tasks []task `json:"tasks"`
+	// NextPageToken is passed as page_token to get the next page,
+	// it is omitted on the last page.
+	NextPageToken string `json:"next_page_token,omitempty"`
 }
 
 func (h *handler) list(c *gin.Context) {
-	tasks, err := h.taskService.GetAll(c)
+	var query listTasksQuery
+	if !request.BindQuery(c, &query) {
+		return
+	}
+
+	var token *pageToken
+	if query.PageToken != "" {
+		parsed, err := parsePageToken(query.PageToken)
+		if err != nil {
+			response.NewValidationErr(c, "Invalid query parameters", []response.FieldError{{
+				Field:  "page_token",
+				Reason: "is malformed",
+			}})
+			return
+		}
+		token = &parsed
+	}
+
+	selector, ok := bindSelector(c, query.Selector)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.taskService.GetAll(c, requestNamespace(c), selector)
 	if response.HandleError(c, err) {
 		return
 	}
 
-	respTasks := make([]task, 0, len(tasks))
-	for _, t := range tasks {
+	page, nextPageToken := paginate(tasks, token, query.Limit)
+
+	respTasks := make([]task, 0, len(page))
+	for _, t := range page {
 		respTasks = append(respTasks, task{
-			UUID:   t.UUID,
-			Status: string(t.Status),
+			UUID:      t.UUID,
+			Type:      t.Type,
+			Tenant:    t.Tenant,
+			Status:    string(t.Status),
+			CreatedAt: t.CreatedAt.Format(time.RFC3339),
+			Labels:    t.Labels,
 		})
 	}
 
-	response.NewOk(c, listTasksResponse{Tasks: respTasks})
+	response.NewOk(c, listTasksResponse{Tasks: respTasks, NextPageToken: nextPageToken})
 }
 
 func (h *handler) cancel(c *gin.Context) {
-	uuid := c.Param("uuid")
-	if uuid == "" {
-		response.NewErr(c, http.StatusBadRequest, response.ErrBadRequestParams, "Task UUID is required")
+	var uri taskURI
+	if !request.BindURI(c, &uri) {
 		return
 	}
 
-	err := h.taskService.Cancel(c, uuid)
+	version, ok := bindIfMatch(c)
+	if !ok {
+		return
+	}
+
+	err := h.taskService.Cancel(c, requestNamespace(c), uri.UUID, version)
 	if errors.Is(err, service.ErrNotFound) {
-		response.NewErr(c, http.StatusNotFound, response.ErrNotFound, "Task not found")
+		response.NewErr(c, response.ProblemNotFound, "Task not found")
+		return
+	}
+	if errors.Is(err, service.ErrVersionMismatch) {
+		response.NewErr(c, response.ProblemPreconditionFailed, "Task has changed since the version in If-Match")
 		return
 	}
 	if errors.Is(err, service.ErrCantCancel) {
-		response.NewErr(c, http.StatusConflict, errors.New("cant_be_canceled"), "Task cannot be canceled because it is already completed or cancelled")
+		response.NewErr(c, response.ProblemCantCancel, "Task cannot be canceled because it has already finished")
+		return
+	}
+	if errors.Is(err, service.ErrConflict) {
+		response.NewErr(c, response.ProblemStatusConflict, "Task status changed concurrently, try again")
 		return
 	}
 	if response.HandleError(c, err) {
@@ -117,3 +307,69 @@ func (h *handler) cancel(c *gin.Context) {
 
 	response.NewOk(c, response.Message{Message: "Task canceled successfully"})
 }
+
+type retryTaskResponse struct {
+	TaskUUID string `json:"task_uuid"`
+	RetryOf  string `json:"retry_of"`
+}
+
+func (h *handler) retry(c *gin.Context) {
+	var uri taskURI
+	if !request.BindURI(c, &uri) {
+		return
+	}
+
+	retryUUID, err := h.taskService.Retry(c, requestNamespace(c), uri.UUID)
+	if errors.Is(err, service.ErrNotFound) {
+		response.NewErr(c, response.ProblemNotFound, "Task not found")
+		return
+	}
+	if errors.Is(err, service.ErrTenantLimit) {
+		response.NewErr(c, response.ProblemTooManyRequests, "Too many queued or running tasks for the tenant")
+		return
+	}
+	if errors.Is(err, service.ErrNamespaceQuota) {
+		response.NewErr(c, response.ProblemQuotaExceeded, "The namespace has reached its task quota")
+		return
+	}
+	if errors.Is(err, service.ErrCantRetry) {
+		response.NewErr(c, response.ProblemCantRetry, "Task cannot be retried because it is still pending or running")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, retryTaskResponse{TaskUUID: retryUUID, RetryOf: uri.UUID})
+}
+
+func (h *handler) delete(c *gin.Context) {
+	var uri taskURI
+	if !request.BindURI(c, &uri) {
+		return
+	}
+
+	version, ok := bindIfMatch(c)
+	if !ok {
+		return
+	}
+
+	err := h.taskService.Delete(c, requestNamespace(c), uri.UUID, version)
+	if errors.Is(err, service.ErrNotFound) {
+		response.NewErr(c, response.ProblemNotFound, "Task not found")
+		return
+	}
+	if errors.Is(err, service.ErrVersionMismatch) {
+		response.NewErr(c, response.ProblemPreconditionFailed, "Task has changed since the version in If-Match")
+		return
+	}
+	if errors.Is(err, service.ErrCantDelete) {
+		response.NewErr(c, response.ProblemCantDelete, "Task cannot be deleted because it is still pending or running")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, response.Message{Message: "Task deleted successfully"})
+}
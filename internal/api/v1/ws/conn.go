@@ -0,0 +1,371 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/request"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/requestid"
+	"github.com/passwordhash/task-manager-api/internal/service"
+)
+
+const (
+	// writeTimeout limits writing a single message to a connection.
+	writeTimeout = 10 * time.Second
+
+	// repliesBuffer is the number of replies waiting to be written
+	// before the connection stops reading client messages.
+	repliesBuffer = 16
+
+	// tenantHeader identifies the tenant that owns created tasks
+	// when authentication is disabled.
+	tenantHeader = "X-Tenant-ID"
+
+	maxTypeLength   = 64
+	maxTenantLength = 64
+)
+
+type taskKey struct {
+	namespace string
+	uuid      string
+}
+
+// conn serves one WebSocket connection: the reader handles client
+// messages and queues the replies, the writer writes the replies,
+// events of the subscribed tasks and pings.
+type conn struct {
+	*handler
+
+	ws  *websocket.Conn
+	log *slog.Logger
+	// ctx is the context of the upgraded request, it carries the principal.
+	ctx    context.Context
+	tenant string
+
+	mu            sync.Mutex
+	subscriptions map[taskKey]struct{}
+
+	replies chan any
+	// closing is closed once the writer stops, so the reader
+	// does not wait for replies to be written.
+	closing chan struct{}
+}
+
+func newConn(h *handler, wsConn *websocket.Conn, r *http.Request) *conn {
+	ctx := r.Context()
+
+	return &conn{
+		handler: h,
+		ws:      wsConn,
+		log:     h.log.With(slog.String("request_id", requestid.FromContext(ctx))),
+		ctx:     ctx,
+		tenant:  r.Header.Get(tenantHeader),
+
+		subscriptions: make(map[taskKey]struct{}),
+
+		replies: make(chan any, repliesBuffer),
+		closing: make(chan struct{}),
+	}
+}
+
+func (c *conn) run() {
+	const op = "ws.run"
+
+	log := c.log.With(slog.String("op", op))
+
+	sub := c.broker.Subscribe(c.opts.SendBuffer, c.subscribed)
+	defer sub.Close()
+
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		c.read()
+	}()
+
+	code, reason := c.write(sub, readDone)
+	close(c.closing)
+	if code != 0 {
+		log.Info("Closing WebSocket connection", slog.String("reason", reason))
+
+		_ = c.ws.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(code, reason),
+			time.Now().Add(writeTimeout),
+		)
+	}
+	_ = c.ws.Close()
+	<-readDone
+}
+
+// read handles client messages until the connection fails or is closed.
+func (c *conn) read() {
+	c.ws.SetReadLimit(request.MaxBodySize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(c.opts.PongTimeout))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(c.opts.PongTimeout))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.log.Debug("WebSocket connection failed", slog.Any("error", err))
+			}
+			return
+		}
+		_ = c.ws.SetReadDeadline(time.Now().Add(c.opts.PongTimeout))
+
+		select {
+		case c.replies <- c.handle(data):
+		case <-c.closing:
+			return
+		}
+	}
+}
+
+// write writes replies, events and pings until the reader stops,
+// the server shuts down or the client falls behind. It returns
+// the code and the reason to close the connection with, zero
+// code if the connection is already unusable.
+func (c *conn) write(sub *events.Subscription, readDone <-chan struct{}) (code int, reason string) {
+	ticker := time.NewTicker(c.opts.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		var msg any
+		select {
+		case <-readDone:
+			return 0, ""
+		case <-c.stop:
+			return websocket.CloseGoingAway, "server is shutting down"
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return 0, ""
+			}
+			continue
+		case msg = <-c.replies:
+		case event, ok := <-sub.Events():
+			if !ok {
+				c.log.Warn("Dropping slow WebSocket client", slog.Any("error", sub.Err()))
+				return websocket.ClosePolicyViolation, "slow consumer: events were not read in time"
+			}
+			msg = toEventMessage(event)
+		}
+
+		_ = c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := c.ws.WriteJSON(msg); err != nil {
+			return 0, ""
+		}
+	}
+}
+
+// subscribed reports whether the connection is subscribed
+// to the task of the event, it filters the events of the broker.
+func (c *conn) subscribed(event events.Event) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.subscriptions[taskKey{namespace: event.Namespace, uuid: event.TaskUUID}]
+	return ok
+}
+
+// handle handles a client message and returns the reply.
+func (c *conn) handle(data []byte) any {
+	var msg clientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return errorReply("", response.ProblemValidation, "Message must be a JSON object")
+	}
+
+	if msg.Namespace == "" {
+		msg.Namespace = domain.DefaultNamespace
+	}
+	if !domain.ValidNamespace(msg.Namespace) {
+		return errorReply(msg.ID, response.ProblemValidation, "namespace must be a DNS label of at most 63 characters")
+	}
+
+	switch msg.Type {
+	case typeSubscribe, typeUnsubscribe, typeCancel:
+		if _, err := uuid.Parse(msg.UUID); err != nil {
+			return errorReply(msg.ID, response.ProblemValidation, "uuid must be a valid UUID")
+		}
+	}
+
+	switch msg.Type {
+	case typeSubscribe:
+		return c.subscribe(msg)
+	case typeUnsubscribe:
+		return c.unsubscribe(msg)
+	case typeCancel:
+		return c.cancel(msg)
+	case typeCreate:
+		return c.create(msg)
+	default:
+		return errorReply(msg.ID, response.ProblemValidation,
+			"type must be one of: subscribe unsubscribe cancel create")
+	}
+}
+
+func (c *conn) subscribe(msg clientMessage) any {
+	key := taskKey{namespace: msg.Namespace, uuid: msg.UUID}
+
+	// Subscribe before reading the task, so no change
+	// between the read and the subscription is missed.
+	c.mu.Lock()
+	_, subscribed := c.subscriptions[key]
+	if !subscribed && len(c.subscriptions) >= c.opts.MaxSubscriptions {
+		c.mu.Unlock()
+		return errorReply(msg.ID, response.ProblemTooManyRequests,
+			"A connection subscribes to at most "+strconv.Itoa(c.opts.MaxSubscriptions)+" tasks")
+	}
+	c.subscriptions[key] = struct{}{}
+	c.mu.Unlock()
+
+	task, err := c.taskService.Get(c.ctx, msg.Namespace, msg.UUID)
+	if err != nil {
+		if !subscribed {
+			c.remove(key)
+		}
+		return c.serviceErrorReply(msg.ID, err)
+	}
+
+	return ackMessage{
+		Type:      typeAck,
+		ID:        msg.ID,
+		Namespace: task.Namespace,
+		TaskUUID:  task.UUID,
+		Status:    task.Status,
+		Version:   task.Version,
+	}
+}
+
+func (c *conn) unsubscribe(msg clientMessage) any {
+	c.remove(taskKey{namespace: msg.Namespace, uuid: msg.UUID})
+
+	return ackMessage{
+		Type:      typeAck,
+		ID:        msg.ID,
+		Namespace: msg.Namespace,
+		TaskUUID:  msg.UUID,
+	}
+}
+
+func (c *conn) remove(key taskKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.subscriptions, key)
+}
+
+func (c *conn) cancel(msg clientMessage) any {
+	if reply, ok := c.requireScope(msg.ID, auth.ScopeTasksCancel); !ok {
+		return reply
+	}
+
+	if err := c.taskService.Cancel(c.ctx, msg.Namespace, msg.UUID, 0); err != nil {
+		return c.serviceErrorReply(msg.ID, err)
+	}
+
+	return ackMessage{
+		Type:      typeAck,
+		ID:        msg.ID,
+		Namespace: msg.Namespace,
+		TaskUUID:  msg.UUID,
+	}
+}
+
+func (c *conn) create(msg clientMessage) any {
+	if reply, ok := c.requireScope(msg.ID, auth.ScopeTasksCreate); !ok {
+		return reply
+	}
+	if len(msg.TaskType) > maxTypeLength {
+		return errorReply(msg.ID, response.ProblemValidation,
+			"task_type must be at most "+strconv.Itoa(maxTypeLength)+" characters long")
+	}
+	if msg.Priority < domain.MinPriority || msg.Priority > domain.MaxPriority {
+		return errorReply(msg.ID, response.ProblemValidation,
+			"priority must be between "+strconv.Itoa(domain.MinPriority)+" and "+strconv.Itoa(domain.MaxPriority))
+	}
+	if err := domain.ValidateLabels(msg.Labels); err != nil {
+		return errorReply(msg.ID, response.ProblemValidation, err.Error())
+	}
+	if len(msg.Description) > domain.MaxDescriptionLength {
+		return errorReply(msg.ID, response.ProblemValidation,
+			"description must be at most "+strconv.Itoa(domain.MaxDescriptionLength)+" characters long")
+	}
+
+	// An authenticated client always creates tasks of its own tenant.
+	var owner string
+	tenant := c.tenant
+	if principal, ok := auth.FromContext(c.ctx); ok {
+		owner, tenant = principal.Name, principal.Tenant
+	}
+	if len(tenant) > maxTenantLength {
+		return errorReply(msg.ID, response.ProblemValidation,
+			tenantHeader+" must be at most "+strconv.Itoa(maxTenantLength)+" characters long")
+	}
+
+	taskUUID, err := c.taskService.CreateTask(c.ctx, service.CreateTaskParams{
+		Namespace:   msg.Namespace,
+		Type:        msg.TaskType,
+		Payload:     msg.Payload,
+		Tenant:      tenant,
+		Owner:       owner,
+		Priority:    msg.Priority,
+		Labels:      msg.Labels,
+		Description: msg.Description,
+	})
+	if err != nil {
+		return c.serviceErrorReply(msg.ID, err)
+	}
+
+	return ackMessage{
+		Type:      typeAck,
+		ID:        msg.ID,
+		Namespace: msg.Namespace,
+		TaskUUID:  taskUUID,
+		Status:    domain.StatusPending,
+	}
+}
+
+// requireScope returns an error reply if the principal lacks the scope.
+// Without a principal authentication is disabled and any message is allowed.
+func (c *conn) requireScope(id string, scope auth.Scope) (errorMessage, bool) {
+	principal, ok := auth.FromContext(c.ctx)
+	if ok && !principal.HasScope(scope) {
+		return errorReply(id, response.ProblemForbidden, "Credentials lack the "+string(scope)+" scope"), false
+	}
+	return errorMessage{}, true
+}
+
+// serviceErrorReply maps errors of the task service to error replies.
+// Unexpected errors are logged and reported without details.
+func (c *conn) serviceErrorReply(id string, err error) errorMessage {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return errorReply(id, response.ProblemNotFound, "Task not found")
+	case errors.Is(err, service.ErrCantCancel):
+		return errorReply(id, response.ProblemCantCancel, "Task cannot be canceled because it has already finished")
+	case errors.Is(err, service.ErrConflict):
+		return errorReply(id, response.ProblemStatusConflict, "Task status changed concurrently, try again")
+	case errors.Is(err, service.ErrTenantLimit):
+		return errorReply(id, response.ProblemTooManyRequests, "Too many queued or running tasks for the tenant")
+	case errors.Is(err, service.ErrNamespaceQuota):
+		return errorReply(id, response.ProblemQuotaExceeded, "The namespace has reached its task quota")
+	case errors.Is(err, service.ErrNamespaceAccess):
+		return errorReply(id, response.ProblemForbidden, "Credentials do not grant access to the namespace")
+	default:
+		c.log.Error("Failed to handle WebSocket message", slog.Any("error", err))
+		return errorReply(id, response.ProblemInternal, "Unexpected error occurred.")
+	}
+}
@@ -0,0 +1,95 @@
+package ws
+
+// Package ws serves the WebSocket endpoint that lets one connection
+// subscribe to events of many tasks and control them.
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/service"
+)
+
+// Options configures the connections of the endpoint.
+type Options struct {
+	// PingInterval is how often the server pings connections.
+	PingInterval time.Duration
+	// PongTimeout is how long the server waits for any frame
+	// from the client before it considers the connection dead.
+	PongTimeout time.Duration
+	// SendBuffer is the number of events waiting to be written
+	// to a connection. A client that lets it fill up is disconnected.
+	SendBuffer int
+	// MaxSubscriptions limits the number of tasks a connection subscribes to.
+	MaxSubscriptions int
+}
+
+type handler struct {
+	log         *slog.Logger
+	taskService service.TaskService
+	broker      *events.Broker
+	opts        Options
+	upgrader    websocket.Upgrader
+	// stop is closed when the server shuts down.
+	stop <-chan struct{}
+	// conns tracks open connections.
+	conns *sync.WaitGroup
+}
+
+// NewHandler returns the handler of the endpoint. Connections
+// are closed with the going away code once stop is closed,
+// conns lets the server wait for them to be closed.
+func NewHandler(
+	log *slog.Logger,
+	taskService service.TaskService,
+	broker *events.Broker,
+	opts Options,
+	stop <-chan struct{},
+	conns *sync.WaitGroup,
+) *handler {
+	return &handler{
+		log:         log,
+		taskService: taskService,
+		broker:      broker,
+		opts:        opts,
+		stop:        stop,
+		conns:       conns,
+	}
+}
+
+// RegisterRoutes registers the endpoint under /ws. It is
+// authenticated like the rest of the group it is registered in.
+func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/ws", middleware.RequireScope(auth.ScopeTasksRead), h.serve)
+}
+
+func (h *handler) serve(c *gin.Context) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		response.NewErr(c, response.ProblemBadRequest, "The endpoint requires a WebSocket upgrade")
+		return
+	}
+
+	// The upgrader writes the response to the hijacked connection,
+	// record its status for the access log.
+	c.Status(http.StatusSwitchingProtocols)
+
+	wsConn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already responded with an HTTP error.
+		_ = c.Error(err)
+		return
+	}
+
+	h.conns.Add(1)
+	defer h.conns.Done()
+
+	newConn(h, wsConn, c.Request).run()
+}
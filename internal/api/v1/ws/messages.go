@@ -0,0 +1,99 @@
+package ws
+
+import (
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/events"
+)
+
+// Types of the messages sent by clients.
+const (
+	typeSubscribe   = "subscribe"
+	typeUnsubscribe = "unsubscribe"
+	typeCancel      = "cancel"
+	typeCreate      = "create"
+)
+
+// Types of the replies to client messages, events are sent
+// with the type of the event.
+const (
+	typeAck   = "ack"
+	typeError = "error"
+)
+
+// clientMessage is a message sent by a client. ID is echoed in the
+// reply so the client can match them. Namespace defaults to
+// [domain.DefaultNamespace], UUID addresses an existing task,
+// TaskType, Payload, Priority, Labels and Description describe a task
+// to create.
+type clientMessage struct {
+	ID          string            `json:"id"`
+	Type        string            `json:"type"`
+	Namespace   string            `json:"namespace"`
+	UUID        string            `json:"uuid"`
+	TaskType    string            `json:"task_type"`
+	Payload     any               `json:"payload"`
+	Priority    int               `json:"priority"`
+	Labels      map[string]string `json:"labels"`
+	Description string            `json:"description"`
+}
+
+// ackMessage confirms that a client message was handled.
+// Replies to subscribe and create carry the current status of the task.
+type ackMessage struct {
+	Type      string            `json:"type"`
+	ID        string            `json:"id,omitempty"`
+	Namespace string            `json:"namespace"`
+	TaskUUID  string            `json:"task_uuid"`
+	Status    domain.TaskStatus `json:"status,omitempty"`
+	// Version is the version of the task with Status. Events
+	// of the task with a version not above it are stale.
+	Version uint64 `json:"version,omitempty"`
+}
+
+// errorMessage reports that a client message was rejected.
+// Code is a code of the error catalog of the HTTP API.
+type errorMessage struct {
+	Type   string `json:"type"`
+	ID     string `json:"id,omitempty"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+func errorReply(id string, problem response.ProblemType, detail string) errorMessage {
+	return errorMessage{
+		Type:   typeError,
+		ID:     id,
+		Code:   problem.Code,
+		Detail: detail,
+	}
+}
+
+type eventMessage struct {
+	Type      events.Type       `json:"type"`
+	Namespace string            `json:"namespace"`
+	TaskUUID  string            `json:"task_uuid"`
+	Time      time.Time         `json:"time"`
+	Status    domain.TaskStatus `json:"status,omitempty"`
+	Version   uint64            `json:"version,omitempty"`
+	Progress  *int              `json:"progress,omitempty"`
+	Message   string            `json:"message,omitempty"`
+}
+
+func toEventMessage(event events.Event) eventMessage {
+	msg := eventMessage{
+		Type:      event.Type,
+		Namespace: event.Namespace,
+		TaskUUID:  event.TaskUUID,
+		Time:      event.Time,
+		Status:    event.Status,
+		Version:   event.Version,
+		Message:   event.Message,
+	}
+	if event.Type == events.TypeProgress {
+		msg.Progress = &event.Progress
+	}
+	return msg
+}
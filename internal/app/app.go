@@ -1,52 +1,378 @@
 package app
 
 import (
+	"context"
 	"log/slog"
+	"net/http"
+	"time"
 
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/ws"
+	grpcapp "github.com/passwordhash/task-manager-api/internal/app/grpc"
 	httpapp "github.com/passwordhash/task-manager-api/internal/app/http"
+	"github.com/passwordhash/task-manager-api/internal/auth"
 	"github.com/passwordhash/task-manager-api/internal/config"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/metrics"
 	"github.com/passwordhash/task-manager-api/internal/service/task"
 	"github.com/passwordhash/task-manager-api/internal/storage/inmemory"
+	"github.com/passwordhash/task-manager-api/internal/storage/notifying"
+	"github.com/passwordhash/task-manager-api/internal/storage/traced"
+	"github.com/passwordhash/task-manager-api/internal/tracing"
+	"github.com/passwordhash/task-manager-api/internal/worker"
+	"github.com/passwordhash/task-manager-api/internal/worker/autoscaler"
 	"github.com/passwordhash/task-manager-api/internal/worker/executor"
 	"github.com/passwordhash/task-manager-api/internal/worker/pool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 type App struct {
 	HTTPSrv *httpapp.App
+	// GRPCSrv is nil when the gRPC API is disabled.
+	GRPCSrv *grpcapp.App
+	// Autoscaler is nil when autoscaling is disabled.
+	Autoscaler *autoscaler.Autoscaler
+
+	log     *slog.Logger
+	pools   worker.PoolGroups
+	apiKeys *auth.APIKeys
+	// jwks is nil when bearer token authentication is disabled.
+	jwks            *auth.JWKS
+	jwksRefreshRate time.Duration
+	// shutdownTracing is nil when tracing is disabled.
+	shutdownTracing func(context.Context) error
 }
 
 func New(
 	log *slog.Logger,
 	cfg *config.Config,
 ) *App {
-	taskStorage := inmemory.NewTaskStorage()
+	var shutdownTracing func(context.Context) error
+	broker := events.NewBroker()
+	taskStorage := notifying.NewTaskStorage(inmemory.NewTaskStorage(), broker)
+	if cfg.Tracing.Enabled {
+		var err error
+		shutdownTracing, err = tracing.Setup(context.Background(), tracing.Options{
+			ServiceName: cfg.Tracing.ServiceName,
+			Exporter:    cfg.Tracing.Exporter,
+			Endpoint:    cfg.Tracing.Endpoint,
+			Insecure:    cfg.Tracing.Insecure,
+			File:        cfg.Tracing.File,
+			SampleRatio: cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			panic("failed to set up tracing: " + err.Error())
+		}
+
+		taskStorage = traced.NewTaskStorage(taskStorage)
+	}
 
-	exec := executor.New()
+	exec := executor.New(broker)
+
+	tenantLimits := pool.TenantLimits{
+		Default: pool.TenantLimit{
+			Weight:     cfg.Tenants.Weight,
+			MaxQueued:  cfg.Tenants.MaxQueued,
+			MaxRunning: cfg.Tenants.MaxRunning,
+		},
+		Overrides: make(map[string]pool.TenantLimit, len(cfg.Tenants.Overrides)),
+	}
+	for _, override := range cfg.Tenants.Overrides {
+		tenantLimits.Overrides[override.Name] = pool.TenantLimit{
+			Weight:     override.Weight,
+			MaxQueued:  override.MaxQueued,
+			MaxRunning: override.MaxRunning,
+		}
+	}
+
+	namespaceLimits := pool.NamespaceLimits{
+		MaxRunning: cfg.Namespaces.MaxRunning,
+		Overrides:  make(map[string]int, len(cfg.Namespaces.Overrides)),
+	}
+	namespaceQuotas := task.NamespaceQuotas{
+		MaxTasks:  cfg.Namespaces.MaxTasks,
+		Overrides: make(map[string]int, len(cfg.Namespaces.Overrides)),
+	}
+	for _, override := range cfg.Namespaces.Overrides {
+		namespaceLimits.Overrides[override.Name] = override.MaxRunning
+		namespaceQuotas.Overrides[override.Name] = override.MaxTasks
+	}
 
 	workerPool := pool.New(
 		log.WithGroup("worker"),
 		cfg.App.Workers,
 		cfg.App.TaskQueueSize,
+		pool.Limits{Types: cfg.App.TypeLimits, Tenants: tenantLimits, Namespaces: namespaceLimits},
 		exec,
 		taskStorage,
 	)
 
+	dedicated := make([]pool.Group, 0, len(cfg.App.Groups))
+	for _, group := range cfg.App.Groups {
+		dedicated = append(dedicated, pool.Group{
+			Name: group.Name,
+			Pool: pool.New(
+				log.WithGroup("worker").With(slog.String("group", group.Name)),
+				group.Workers,
+				group.QueueSize,
+				pool.Limits{Types: group.TypeLimits, Tenants: tenantLimits, Namespaces: namespaceLimits},
+				exec,
+				taskStorage,
+			),
+			Types: group.Types,
+		})
+	}
+
+	pools, err := pool.NewGroups(workerPool, dedicated...)
+	if err != nil {
+		panic("failed to configure worker groups: " + err.Error())
+	}
+
 	taskService := task.NewSimulatedTaskService(
 		log.WithGroup("service"),
-		workerPool,
+		pools,
 		taskStorage,
+		namespaceQuotas,
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metrics.NewPoolCollector(pools),
+	)
+
+	var poolAutoscaler *autoscaler.Autoscaler
+	if cfg.Autoscale.Enabled {
+		poolAutoscaler = autoscaler.New(
+			log.WithGroup("autoscaler"),
+			workerPool,
+			autoscaler.Options{
+				MinWorkers:        cfg.Autoscale.MinWorkers,
+				MaxWorkers:        cfg.Autoscale.MaxWorkers,
+				Interval:          cfg.Autoscale.Interval,
+				TargetQueueWait:   cfg.Autoscale.TargetQueueWait,
+				MaxBacklog:        cfg.Autoscale.MaxBacklog,
+				Step:              cfg.Autoscale.Step,
+				ScaleUpCooldown:   cfg.Autoscale.ScaleUpCooldown,
+				ScaleDownCooldown: cfg.Autoscale.ScaleDownCooldown,
+			},
+		)
+		registry.MustRegister(metrics.NewAutoscalerCollector(poolAutoscaler))
+	}
+
+	var apiKeys *auth.APIKeys
+	if cfg.Auth.Enabled {
+		keys, err := loadAPIKeys(cfg.Auth)
+		if err != nil {
+			panic("failed to load API keys: " + err.Error())
+		}
+
+		apiKeys, err = auth.NewAPIKeys(keys)
+		if err != nil {
+			panic("failed to load API keys: " + err.Error())
+		}
+	}
+
+	var (
+		jwks   *auth.JWKS
+		tokens *auth.JWTVerifier
 	)
+	if cfg.Auth.JWT.Enabled {
+		jwks, err = loadJWKS(cfg.Auth.JWT)
+		if err != nil {
+			panic("failed to load JWKS: " + err.Error())
+		}
+
+		tokens = auth.NewJWTVerifier(jwks, auth.JWTOptions{
+			Issuer:      cfg.Auth.JWT.Issuer,
+			Audience:    cfg.Auth.JWT.Audience,
+			TenantClaim: cfg.Auth.JWT.TenantClaim,
+			ScopesClaim: cfg.Auth.JWT.ScopesClaim,
+			Leeway:      cfg.Auth.JWT.Leeway,
+
+			NamespacesClaim: cfg.Auth.JWT.NamespacesClaim,
+		})
+	}
+
+	var readLimiter, createLimiter *middleware.RateLimiter
+	if cfg.HTTP.RateLimit.Enabled {
+		readLimiter = middleware.NewRateLimiter(middleware.RateLimit{
+			Rate:  cfg.HTTP.RateLimit.Read.Rate,
+			Burst: cfg.HTTP.RateLimit.Read.Burst,
+		})
+		createLimiter = middleware.NewRateLimiter(middleware.RateLimit{
+			Rate:  cfg.HTTP.RateLimit.Create.Rate,
+			Burst: cfg.HTTP.RateLimit.Create.Burst,
+		})
+	}
 
 	httpApp := httpapp.New(
 		log,
-		workerPool,
+		pools,
 		taskService,
+		broker,
+		registry,
+		apiKeys,
+		tokens,
+		readLimiter,
+		createLimiter,
+		middleware.NewIdempotencyKeys(cfg.HTTP.IdempotencyTTL),
+		cfg.HTTP.TrustedProxies,
 		cfg.HTTP.Port,
 		cfg.HTTP.ReadTimeout,
 		cfg.HTTP.WriteTimeout,
+		ws.Options{
+			PingInterval:     cfg.HTTP.WebSocket.PingInterval,
+			PongTimeout:      cfg.HTTP.WebSocket.PongTimeout,
+			SendBuffer:       cfg.HTTP.WebSocket.SendBuffer,
+			MaxSubscriptions: cfg.HTTP.WebSocket.MaxSubscriptions,
+		},
 	)
 
+	var grpcApp *grpcapp.App
+	if cfg.GRPC.Enabled {
+		grpcApp = grpcapp.New(
+			log,
+			taskService,
+			apiKeys,
+			tokens,
+			cfg.GRPC.Port,
+			cfg.GRPC.WatchInterval,
+		)
+	}
+
 	return &App{
-		HTTPSrv: httpApp,
+		HTTPSrv:    httpApp,
+		GRPCSrv:    grpcApp,
+		Autoscaler: poolAutoscaler,
+		log:        log,
+		pools:      pools,
+		apiKeys:    apiKeys,
+
+		jwks:            jwks,
+		jwksRefreshRate: cfg.Auth.JWT.RefreshInterval,
+		shutdownTracing: shutdownTracing,
+	}
+}
+
+// Stop gracefully stops the servers and flushes pending spans.
+func (a *App) Stop(ctx context.Context) {
+	const op = "app.Stop"
+
+	log := a.log.With(slog.String("op", op))
+
+	// The HTTP application stops the worker pools,
+	// so gRPC calls must be finished first.
+	if a.GRPCSrv != nil {
+		a.GRPCSrv.Stop(ctx)
+	}
+	a.HTTPSrv.Stop(ctx)
+
+	if a.shutdownTracing != nil {
+		if err := a.shutdownTracing(ctx); err != nil {
+			log.Error("Failed to flush traces", slog.Any("error", err))
+		}
+	}
+}
+
+// jwksFetchTimeout limits loading of the JWKS on startup.
+const jwksFetchTimeout = 10 * time.Second
+
+// loadJWKS loads the JWKS from the file or the URL of the config.
+func loadJWKS(cfg config.JWTConfig) (*auth.JWKS, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+
+	if cfg.JWKSFile != "" {
+		return auth.NewJWKSFromFile(ctx, cfg.JWKSFile)
+	}
+	return auth.NewJWKSFromURL(ctx, cfg.JWKSURL, &http.Client{Timeout: jwksFetchTimeout})
+}
+
+// RunKeyRefresh periodically reloads the JWKS to pick up rotated
+// signing keys until ctx is done. It returns at once
+// if bearer token authentication is disabled.
+func (a *App) RunKeyRefresh(ctx context.Context) {
+	if a.jwks == nil || a.jwksRefreshRate <= 0 {
+		return
+	}
+
+	a.jwks.Run(ctx, a.log.WithGroup("auth"), a.jwksRefreshRate)
+}
+
+// loadAPIKeys returns the keys from the config and from the keys file.
+func loadAPIKeys(cfg config.AuthConfig) ([]auth.APIKey, error) {
+	keys := make([]auth.APIKey, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		keys = append(keys, auth.APIKey{
+			Name:   key.Name,
+			SHA256: key.SHA256,
+			Tenant: key.Tenant,
+			Scopes: key.Scopes,
+
+			Namespaces: key.Namespaces,
+		})
 	}
+
+	if cfg.KeysFile != "" {
+		fileKeys, err := auth.LoadAPIKeys(cfg.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	return keys, nil
+}
+
+// Reload applies the settings of cfg that can be changed at runtime:
+// the number of workers in the default and existing dedicated worker
+// groups, the API keys and the JWKS. With autoscaling enabled the autoscaler keeps
+// adjusting the default group from the new size. Enabling or disabling
+// authentication requires a restart.
+func (a *App) Reload(cfg *config.Config) {
+	const op = "app.Reload"
+
+	log := a.log.With(slog.String("op", op))
+
+	sizes := map[string]int{worker.DefaultGroup: cfg.App.Workers}
+	for _, group := range cfg.App.Groups {
+		sizes[group.Name] = group.Workers
+	}
+
+	for name, workers := range sizes {
+		groupPool, ok := a.pools.Get(name)
+		if !ok {
+			log.Warn("New worker groups require a restart", slog.String("group", name))
+			continue
+		}
+
+		if err := groupPool.Resize(workers); err != nil {
+			log.Error("Failed to resize worker pool", slog.String("group", name), slog.Any("error", err))
+			continue
+		}
+	}
+
+	if a.apiKeys != nil {
+		keys, err := loadAPIKeys(cfg.Auth)
+		if err == nil {
+			err = a.apiKeys.Replace(keys)
+		}
+		if err != nil {
+			log.Error("Failed to reload API keys, keeping the current ones", slog.Any("error", err))
+		}
+	}
+
+	if a.jwks != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+		defer cancel()
+
+		if err := a.jwks.Refresh(ctx); err != nil {
+			log.Error("Failed to reload JWKS, keeping the current keys", slog.Any("error", err))
+		}
+	}
+
+	log.Info("Configuration reloaded", slog.Int("workers", cfg.App.Workers))
 }
@@ -0,0 +1,116 @@
+package grpcapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/api/grpc/interceptor"
+	"github.com/passwordhash/task-manager-api/internal/api/grpc/tasks"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type App struct {
+	log    *slog.Logger
+	server *grpc.Server
+	port   int
+	// stopWatches is closed on Stop to end running watches,
+	// which would otherwise hold up the graceful stop.
+	stopWatches chan struct{}
+}
+
+// New returns the gRPC application. Calls are authenticated with API
+// keys if apiKeys is not nil and with bearer tokens if tokens is not nil.
+// If both are nil, authentication is disabled. WatchTask checks
+// watched tasks for changes every watchInterval.
+func New(
+	log *slog.Logger,
+	taskManager service.TaskService,
+	apiKeys *auth.APIKeys,
+	tokens *auth.JWTVerifier,
+	port int,
+	watchInterval time.Duration,
+) *App {
+	accessLog := log.WithGroup("grpc")
+	unary := []grpc.UnaryServerInterceptor{interceptor.UnaryAccessLog(accessLog)}
+	stream := []grpc.StreamServerInterceptor{interceptor.StreamAccessLog(accessLog)}
+	if apiKeys != nil || tokens != nil {
+		authenticator := interceptor.NewAuthenticator(apiKeys, tokens, tasks.Scopes)
+		unary = append(unary, authenticator.Unary())
+		stream = append(stream, authenticator.Stream())
+	}
+
+	stopWatches := make(chan struct{})
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	tasks.NewServer(taskManager, watchInterval, stopWatches).Register(server)
+	reflection.Register(server)
+
+	return &App{
+		log:         log,
+		server:      server,
+		port:        port,
+		stopWatches: stopWatches,
+	}
+}
+
+// MustRun starts the gRPC server and panics if it fails to start.
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		panic("failed to run gRPC server: " + err.Error())
+	}
+}
+
+// Run starts the gRPC server and listens on the specified port.
+func (a *App) Run() error {
+	const op = "grpcapp.Run"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int("port", a.port),
+	)
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(a.port))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("Starting gRPC server")
+
+	return a.server.Serve(listener)
+}
+
+// Stop gracefully stops the gRPC server. Running watches end at once,
+// other calls still running when ctx is done are canceled.
+func (a *App) Stop(ctx context.Context) {
+	const op = "grpcapp.Stop"
+
+	log := a.log.With(slog.String("op", op))
+
+	log.Info("Stopping gRPC server")
+
+	close(a.stopWatches)
+
+	stopped := make(chan struct{})
+	go func() {
+		a.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		log.Info("gRPC server stopped gracefully")
+	case <-ctx.Done():
+		a.server.Stop()
+		log.Warn("gRPC server stopped, pending calls were canceled")
+	}
+}
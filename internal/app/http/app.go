@@ -3,6 +3,7 @@ package httpapp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -10,41 +11,113 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/admin"
+	"github.com/passwordhash/task-manager-api/internal/api/dashboard"
+	"github.com/passwordhash/task-manager-api/internal/api/health"
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+	"github.com/passwordhash/task-manager-api/internal/api/openapi"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/response"
 	tasks "github.com/passwordhash/task-manager-api/internal/api/v1/tasks"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/ws"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/events"
 	"github.com/passwordhash/task-manager-api/internal/service"
 	"github.com/passwordhash/task-manager-api/internal/worker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 const shutdownTimeout = 5 * time.Second
 
+// tracerName names the server in the spans of HTTP requests.
+const tracerName = "task-manager-api"
+
+// apiVersion is the version of the API in the OpenAPI document.
+const apiVersion = "1.0.0"
+
+const (
+	openAPIPath = "/openapi.json"
+	// docsPath serves the Swagger UI.
+	docsPath = "/docs"
+	// dashboardPath serves the web UI for tasks and worker pools.
+	dashboardPath = "/dashboard"
+)
+
 type App struct {
 	log         *slog.Logger
-	taskPool    worker.TaskPool
+	pools       worker.PoolGroups
 	taskManager service.TaskService
+	broker      *events.Broker
+	metrics     prometheus.Gatherer
+	apiKeys     *auth.APIKeys
+	tokens      *auth.JWTVerifier
+	// readLimiter and createLimiter are nil when rate limiting is disabled.
+	readLimiter   *middleware.RateLimiter
+	createLimiter *middleware.RateLimiter
+	idempotency   *middleware.IdempotencyKeys
+	// trustedProxies may set the client address in X-Forwarded-For.
+	trustedProxies []string
 
 	port         int
 	readTimeout  time.Duration
 	writeTimeout time.Duration
+	wsOptions    ws.Options
+	// stopStreams is closed on shutdown to end event streams and close
+	// WebSocket connections, which the server does not track once they
+	// are hijacked, streams lets Stop wait for them.
+	stopStreams chan struct{}
+	streams     sync.WaitGroup
 
 	mu     sync.Mutex
 	server *http.Server
 }
 
+// New returns the HTTP application. The /api and /admin routes accept
+// API keys if apiKeys is not nil and bearer tokens if tokens is not nil.
+// If both are nil, authentication is disabled. The /api routes
+// are rate limited if readLimiter and createLimiter are not nil
+// and remember responses to requests with idempotency keys in idempotency.
+// The client address is only taken from X-Forwarded-For and X-Real-IP
+// of requests sent by trustedProxies.
+// Event streams and the WebSocket endpoint deliver the events of broker.
 func New(
 	log *slog.Logger,
-	taskPool worker.TaskPool,
+	pools worker.PoolGroups,
 	taskManager service.TaskService,
+	broker *events.Broker,
+	metrics prometheus.Gatherer,
+	apiKeys *auth.APIKeys,
+	tokens *auth.JWTVerifier,
+	readLimiter *middleware.RateLimiter,
+	createLimiter *middleware.RateLimiter,
+	idempotency *middleware.IdempotencyKeys,
+	trustedProxies []string,
 	port int,
 	readTimeout time.Duration,
 	writeTimeout time.Duration,
+	wsOptions ws.Options,
 ) *App {
 	return &App{
-		log:          log,
-		taskPool:     taskPool,
-		taskManager:  taskManager,
+		log:         log,
+		pools:       pools,
+		taskManager: taskManager,
+		broker:      broker,
+		metrics:     metrics,
+		apiKeys:     apiKeys,
+		tokens:      tokens,
+
+		readLimiter:   readLimiter,
+		createLimiter: createLimiter,
+		idempotency:   idempotency,
+
+		trustedProxies: trustedProxies,
+
 		port:         port,
 		readTimeout:  readTimeout,
 		writeTimeout: writeTimeout,
+		wsOptions:    wsOptions,
+		stopStreams:  make(chan struct{}),
 	}
 }
 
@@ -65,21 +138,16 @@ func (a *App) Run(ctx context.Context) error {
 		slog.Int("port", a.port),
 	)
 
-	log.Info("Starting task pool pool")
+	for _, name := range a.pools.Names() {
+		log.Info("Starting task pool", slog.String("group", name))
 
-	a.taskPool.Start(ctx)
+		groupPool, _ := a.pools.Get(name)
+		groupPool.Start(ctx)
+	}
 
 	log.Info("Starting HTTP server")
 
-	router := gin.New()
-	router.Use(gin.Recovery())
-
-	api := router.Group("/api")
-	v1 := api.Group("/v1")
-
-	tasksHandler := tasks.NewHandler(a.taskManager)
-
-	tasksHandler.RegisterRoutes(v1)
+	router := a.newRouter()
 
 	srv := &http.Server{
 		Addr:         ":" + strconv.Itoa(a.port),
@@ -87,6 +155,7 @@ func (a *App) Run(ctx context.Context) error {
 		ReadTimeout:  a.readTimeout,
 		WriteTimeout: a.writeTimeout,
 	}
+	srv.RegisterOnShutdown(func() { close(a.stopStreams) })
 
 	a.mu.Lock()
 	a.server = srv
@@ -95,6 +164,94 @@ func (a *App) Run(ctx context.Context) error {
 	return srv.ListenAndServe()
 }
 
+// Handler returns the handler of all routes of the application,
+// e.g. to serve them in tests without starting the server.
+func (a *App) Handler() http.Handler {
+	return a.newRouter()
+}
+
+// newRouter returns the router with all routes of the application.
+func (a *App) newRouter() *gin.Engine {
+	router := gin.New()
+	// Let handlers passing *gin.Context as context.Context
+	// see values stored by middlewares in the request context.
+	router.ContextWithFallback = true
+	// The proxies are validated with the configuration.
+	if err := router.SetTrustedProxies(a.trustedProxies); err != nil {
+		panic("invalid trusted proxies: " + err.Error())
+	}
+	router.Use(
+		otelgin.Middleware(tracerName),
+		middleware.RequestID(),
+		middleware.AccessLog(a.log.WithGroup("http")),
+		gin.CustomRecovery(func(c *gin.Context, recovered any) {
+			_ = c.Error(fmt.Errorf("panic: %v", recovered))
+			response.NewErr(c, response.ProblemInternal, "Unexpected error occurred.")
+			c.Abort()
+		}),
+	)
+	router.NoRoute(func(c *gin.Context) {
+		response.NewErr(c, response.ProblemRouteNotFound, "No route matches "+c.Request.Method+" "+c.Request.URL.Path)
+	})
+
+	healthHandler := health.NewHandler(a.pools)
+	healthHandler.RegisterRoutes(router)
+
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(a.metrics, promhttp.HandlerOpts{})))
+
+	doc := a.openAPI()
+	router.GET(openAPIPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+	router.GET(docsPath+"/*filepath", gin.WrapH(http.StripPrefix(docsPath, openapi.UIHandler(openAPIPath))))
+	router.GET(dashboardPath+"/*filepath", gin.WrapH(http.StripPrefix(dashboardPath, dashboard.Handler())))
+
+	adminGroup := router.Group("/admin")
+	if a.authEnabled() {
+		adminGroup.Use(middleware.Authenticate(a.apiKeys, a.tokens))
+	}
+	adminGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+	adminHandler := admin.NewHandler(a.pools)
+	adminHandler.RegisterRoutes(adminGroup)
+
+	api := router.Group("/api")
+	if a.authEnabled() {
+		api.Use(middleware.Authenticate(a.apiKeys, a.tokens))
+	}
+	if a.readLimiter != nil && a.createLimiter != nil {
+		api.Use(middleware.RateLimitByMethod(a.readLimiter, a.createLimiter))
+	}
+	api.Use(middleware.Idempotency(a.idempotency))
+	v1 := api.Group("/v1")
+
+	tasksHandler := tasks.NewHandler(a.taskManager, a.broker, a.stopStreams)
+
+	tasksHandler.RegisterRoutes(v1)
+
+	wsHandler := ws.NewHandler(a.log.WithGroup("ws"), a.taskManager, a.broker, a.wsOptions, a.stopStreams, &a.streams)
+	wsHandler.RegisterRoutes(v1)
+
+	return router
+}
+
+// openAPI returns the document describing the routes of newRouter.
+func (a *App) openAPI() *openapi.Document {
+	var routes []openapi.Route
+	routes = append(routes, health.Routes()...)
+	routes = append(routes, admin.Routes("/admin")...)
+	routes = append(routes, tasks.Routes("/api/v1")...)
+
+	return openapi.Build(openapi.Info{
+		Title:       "Task Manager API",
+		Description: "Management of long-running I/O tasks.",
+		Version:     apiVersion,
+	}, routes)
+}
+
+func (a *App) authEnabled() bool {
+	return a.apiKeys != nil || a.tokens != nil
+}
+
 // Stop gracefully stops the HTTP server.
 func (a *App) Stop(ctx context.Context) {
 	const op = "httpapp.Stop"
@@ -103,11 +260,22 @@ func (a *App) Stop(ctx context.Context) {
 
 	log.Info("Stopping HTTP server")
 
-	if err := a.taskPool.Stop(ctx); err != nil {
-		log.Error("Failed to stop task pool", slog.Any("error", err))
-	} else {
-		log.Info("Task pool stopped gracefully")
+	var wg sync.WaitGroup
+	for _, name := range a.pools.Names() {
+		groupPool, _ := a.pools.Get(name)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := groupPool.Stop(ctx); err != nil {
+				log.Error("Failed to stop task pool", slog.String("group", name), slog.Any("error", err))
+			} else {
+				log.Info("Task pool stopped gracefully", slog.String("group", name))
+			}
+		}()
 	}
+	wg.Wait()
 
 	a.mu.Lock()
 	server := a.server
@@ -119,4 +287,16 @@ func (a *App) Stop(ctx context.Context) {
 	} else {
 		log.Info("HTTP server stopped gracefully")
 	}
+
+	// Shutdown has told the WebSocket connections to close, but does not wait for them.
+	closed := make(chan struct{})
+	go func() {
+		a.streams.Wait()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		log.Warn("WebSocket connections were not closed in time")
+	}
 }
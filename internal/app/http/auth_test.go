@@ -0,0 +1,222 @@
+package httpapp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/service/task"
+	"github.com/passwordhash/task-manager-api/internal/storage/inmemory"
+	"github.com/passwordhash/task-manager-api/internal/worker/executor"
+	"github.com/passwordhash/task-manager-api/internal/worker/pool"
+)
+
+// newServiceApp returns an application backed by the in-memory storage.
+// Its worker pool is not started, so created tasks stay pending.
+func newServiceApp(t *testing.T, apiKeys *auth.APIKeys) *App {
+	t.Helper()
+
+	log := slog.New(slog.DiscardHandler)
+	broker := events.NewBroker()
+	storage := inmemory.NewTaskStorage()
+
+	pools, err := pool.NewGroups(pool.New(log, 1, 100, pool.Limits{}, executor.New(broker), storage))
+	if err != nil {
+		t.Fatalf("create worker groups: %v", err)
+	}
+	service := task.NewSimulatedTaskService(log, pools, storage, task.NamespaceQuotas{})
+
+	a := newTestApp()
+	a.pools = pools
+	a.taskManager = service
+	a.apiKeys = apiKeys
+	return a
+}
+
+func newTestKeys(t *testing.T) *auth.APIKeys {
+	t.Helper()
+
+	tasksScopes := []string{"tasks:create", "tasks:read", "tasks:update", "tasks:cancel", "tasks:delete"}
+	keys, err := auth.NewAPIKeys([]auth.APIKey{
+		{Name: "alice", SHA256: auth.HashAPIKey("alice-key"), Scopes: tasksScopes},
+		{Name: "bob", SHA256: auth.HashAPIKey("bob-key"), Scopes: tasksScopes},
+		{Name: "reader", SHA256: auth.HashAPIKey("reader-key"), Scopes: []string{"tasks:read"}},
+		{Name: "creator", SHA256: auth.HashAPIKey("creator-key"), Scopes: []string{"tasks:create"}},
+		{Name: "admin", SHA256: auth.HashAPIKey("admin-key"), Scopes: []string{"admin"}},
+	})
+	if err != nil {
+		t.Fatalf("create API keys: %v", err)
+	}
+	return keys
+}
+
+// serve sends the request with the API key, if any, to the handler.
+func serve(t *testing.T, handler http.Handler, method, path, key string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	if key != "" {
+		req.Header.Set(middleware.APIKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func createTask(t *testing.T, handler http.Handler, key string) string {
+	t.Helper()
+
+	rec := serve(t, handler, http.MethodPost, "/api/v1/tasks/", key)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create task with %s: status %d: %s", key, rec.Code, rec.Body)
+	}
+
+	var resp struct {
+		TaskUUID string `json:"task_uuid"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode create response: %v", err)
+	}
+	return resp.TaskUUID
+}
+
+func listTasks(t *testing.T, handler http.Handler, key string) map[string]bool {
+	t.Helper()
+
+	rec := serve(t, handler, http.MethodGet, "/api/v1/tasks/", key)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list tasks with %s: status %d: %s", key, rec.Code, rec.Body)
+	}
+
+	var resp struct {
+		Tasks []struct {
+			UUID string `json:"uuid"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode list response: %v", err)
+	}
+
+	uuids := make(map[string]bool, len(resp.Tasks))
+	for _, task := range resp.Tasks {
+		uuids[task.UUID] = true
+	}
+	return uuids
+}
+
+func TestAuthenticationIsRequired(t *testing.T) {
+	router := newServiceApp(t, newTestKeys(t)).newRouter()
+
+	tests := []struct {
+		name string
+		path string
+		key  string
+	}{
+		{name: "missing key", path: "/api/v1/tasks/"},
+		{name: "wrong key", path: "/api/v1/tasks/", key: "wrong-key"},
+		{name: "admin API without key", path: "/admin/pool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, router, http.MethodGet, tt.path, tt.key)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestMissingScopeIsForbidden(t *testing.T) {
+	router := newServiceApp(t, newTestKeys(t)).newRouter()
+	taskUUID := createTask(t, router, "alice-key")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+	}{
+		{name: "create", method: http.MethodPost, path: "/api/v1/tasks/", key: "reader-key"},
+		{name: "cancel", method: http.MethodPost, path: "/api/v1/tasks/" + taskUUID + "/cancel", key: "reader-key"},
+		{name: "delete", method: http.MethodDelete, path: "/api/v1/tasks/" + taskUUID, key: "reader-key"},
+		// Editing a task needs its own scope, creating tasks is not enough.
+		{name: "patch", method: http.MethodPatch, path: "/api/v1/tasks/" + taskUUID, key: "creator-key"},
+		{name: "admin API", method: http.MethodGet, path: "/admin/pool", key: "alice-key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, router, tt.method, tt.path, tt.key)
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("status %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+
+	if rec := serve(t, router, http.MethodGet, "/api/v1/tasks/", "reader-key"); rec.Code != http.StatusOK {
+		t.Errorf("list with the read scope: status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestTasksOfOtherOwnersAreHidden(t *testing.T) {
+	router := newServiceApp(t, newTestKeys(t)).newRouter()
+	aliceTask := createTask(t, router, "alice-key")
+	bobTask := createTask(t, router, "bob-key")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "get", method: http.MethodGet, path: "/api/v1/tasks/" + aliceTask + "/status"},
+		{name: "history", method: http.MethodGet, path: "/api/v1/tasks/" + aliceTask + "/history"},
+		{name: "cancel", method: http.MethodPost, path: "/api/v1/tasks/" + aliceTask + "/cancel"},
+		{name: "delete", method: http.MethodDelete, path: "/api/v1/tasks/" + aliceTask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, router, tt.method, tt.path, "bob-key")
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("status %d, want %d", rec.Code, http.StatusNotFound)
+			}
+		})
+	}
+
+	if got := listTasks(t, router, "bob-key"); got[aliceTask] || !got[bobTask] {
+		t.Errorf("bob lists %v, want only %s", got, bobTask)
+	}
+
+	// The task is untouched by the requests of bob.
+	if rec := serve(t, router, http.MethodGet, "/api/v1/tasks/"+aliceTask+"/status", "alice-key"); rec.Code != http.StatusOK {
+		t.Errorf("get by the owner: status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAdminSeesAllTasks(t *testing.T) {
+	router := newServiceApp(t, newTestKeys(t)).newRouter()
+	aliceTask := createTask(t, router, "alice-key")
+	bobTask := createTask(t, router, "bob-key")
+
+	if got := listTasks(t, router, "admin-key"); !got[aliceTask] || !got[bobTask] {
+		t.Errorf("admin lists %v, want %s and %s", got, aliceTask, bobTask)
+	}
+
+	for _, taskUUID := range []string{aliceTask, bobTask} {
+		if rec := serve(t, router, http.MethodGet, "/api/v1/tasks/"+taskUUID+"/status", "admin-key"); rec.Code != http.StatusOK {
+			t.Errorf("get %s: status %d, want %d", taskUUID, rec.Code, http.StatusOK)
+		}
+	}
+	if rec := serve(t, router, http.MethodPost, "/api/v1/tasks/"+aliceTask+"/cancel", "admin-key"); rec.Code != http.StatusOK {
+		t.Errorf("cancel: status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(t, router, http.MethodDelete, "/api/v1/tasks/"+aliceTask, "admin-key"); rec.Code != http.StatusOK {
+		t.Errorf("delete: status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(t, router, http.MethodGet, "/admin/pool", "admin-key"); rec.Code != http.StatusOK {
+		t.Errorf("admin API: status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,119 @@
+package httpapp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+	"github.com/passwordhash/task-manager-api/internal/api/openapi"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/ws"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// undocumented lists the routes intentionally left out of the OpenAPI document.
+var undocumented = map[string]bool{
+	"/metrics":                   true,
+	openAPIPath:                  true,
+	docsPath + "/*filepath":      true,
+	dashboardPath + "/*filepath": true,
+	// The WebSocket protocol cannot be described by OpenAPI.
+	"/api/v1/ws": true,
+}
+
+func newTestApp() *App {
+	gin.SetMode(gin.TestMode)
+	return New(slog.New(slog.DiscardHandler), nil, nil, events.NewBroker(), prometheus.NewRegistry(), nil, nil, nil, nil, middleware.NewIdempotencyKeys(0), nil, 0, 0, 0, ws.Options{})
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	a := newTestApp()
+
+	documented := make(map[string]bool)
+	for path, item := range a.openAPI().Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for _, route := range a.newRouter().Routes() {
+		if undocumented[route.Path] {
+			continue
+		}
+
+		key := route.Method + " " + openapi.Path(route.Path)
+		if !documented[key] {
+			t.Errorf("route %s is not described in the OpenAPI document", key)
+		}
+		delete(documented, key)
+	}
+
+	for key := range documented {
+		t.Errorf("OpenAPI document describes %s, but there is no such route", key)
+	}
+}
+
+func TestOpenAPIDocumentIsConsistent(t *testing.T) {
+	doc := newTestApp().openAPI()
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal document: %v", err)
+	}
+
+	ids := make(map[string]string)
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if op.OperationID == "" {
+				t.Errorf("%s %s has no operation ID", method, path)
+			}
+			if other, ok := ids[op.OperationID]; ok {
+				t.Errorf("operation ID %s is used by %s and %s %s", op.OperationID, other, method, path)
+			}
+			ids[op.OperationID] = method + " " + path
+
+			for _, param := range op.Parameters {
+				if param.In == "path" && !strings.Contains(path, "{"+param.Name+"}") {
+					t.Errorf("%s %s describes unknown path parameter %s", method, path, param.Name)
+				}
+			}
+		}
+	}
+
+	// Every reference must point to a schema of the components.
+	var refs []string
+	var collect func(v any)
+	collect = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				refs = append(refs, ref)
+			}
+			for _, child := range v {
+				collect(child)
+			}
+		case []any:
+			for _, child := range v {
+				collect(child)
+			}
+		}
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal document: %v", err)
+	}
+	collect(raw)
+
+	if len(refs) == 0 {
+		t.Fatal("document has no schema references")
+	}
+	for _, ref := range refs {
+		name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+		if _, exists := doc.Components.Schemas[name]; !ok || !exists {
+			t.Errorf("unresolved reference %s", ref)
+		}
+	}
+}
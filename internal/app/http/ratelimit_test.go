@@ -0,0 +1,49 @@
+package httpapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+)
+
+func TestRateLimitIgnoresForwardedForOfUntrustedClients(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		// wantLimited is whether the second request, sent from the same
+		// address with another X-Forwarded-For, is rejected.
+		wantLimited bool
+	}{
+		{name: "no trusted proxies", wantLimited: true},
+		{name: "other proxy", trustedProxies: []string{"10.0.0.1"}, wantLimited: true},
+		{name: "trusted proxy", trustedProxies: []string{"192.0.2.0/24"}, wantLimited: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newServiceApp(t, nil)
+			a.readLimiter = middleware.NewRateLimiter(middleware.RateLimit{Rate: 0.001, Burst: 1})
+			a.createLimiter = middleware.NewRateLimiter(middleware.RateLimit{Rate: 0.001, Burst: 1})
+			a.trustedProxies = tt.trustedProxies
+			router := a.newRouter()
+
+			var codes []int
+			for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+				// httptest requests come from 192.0.2.1.
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/", nil)
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				codes = append(codes, rec.Code)
+			}
+
+			if codes[0] != http.StatusOK {
+				t.Fatalf("first request: status %d, want %d", codes[0], http.StatusOK)
+			}
+			if limited := codes[1] == http.StatusTooManyRequests; limited != tt.wantLimited {
+				t.Errorf("second request: status %d, want limited %v", codes[1], tt.wantLimited)
+			}
+		})
+	}
+}
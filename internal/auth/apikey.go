@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIKey describes a client authenticated by a static key.
+// Only the SHA-256 hash of the key is kept.
+type APIKey struct {
+	Name string `yaml:"name"`
+	// SHA256 is the hex-encoded SHA-256 hash of the key.
+	SHA256 string `yaml:"sha256"`
+	// Tenant of the tasks created with the key, the key name if empty.
+	Tenant string   `yaml:"tenant"`
+	Scopes []string `yaml:"scopes"`
+	// Namespaces the key may access, all namespaces if empty.
+	Namespaces []string `yaml:"namespaces"`
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 hash of the key
+// in the form expected by [APIKey.SHA256].
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadAPIKeys reads keys from a YAML file with a top-level `keys` list.
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	const op = "auth.LoadAPIKeys"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var file struct {
+		Keys []APIKey `yaml:"keys"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return file.Keys, nil
+}
+
+// APIKeys authenticates clients by their API keys.
+// Keys can be replaced at runtime, it is safe for concurrent use.
+type APIKeys struct {
+	mu     sync.RWMutex
+	byHash map[string]Principal
+}
+
+// NewAPIKeys returns a key set with the given keys.
+// See [APIKeys.Replace] for the validation rules.
+func NewAPIKeys(keys []APIKey) (*APIKeys, error) {
+	a := &APIKeys{}
+	if err := a.Replace(keys); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Replace atomically replaces all keys. It returns an error and keeps
+// the current keys if a key has no name, an invalid hash,
+// an unknown scope, or if names or hashes are duplicated.
+func (a *APIKeys) Replace(keys []APIKey) error {
+	const op = "auth.APIKeys.Replace"
+
+	byHash := make(map[string]Principal, len(keys))
+	names := make(map[string]struct{}, len(keys))
+
+	for _, key := range keys {
+		if key.Name == "" {
+			return fmt.Errorf("%s: key without a name", op)
+		}
+		if _, exists := names[key.Name]; exists {
+			return fmt.Errorf("%s: duplicate key name %q", op, key.Name)
+		}
+		names[key.Name] = struct{}{}
+
+		hash := strings.ToLower(key.SHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return fmt.Errorf("%s: key %q: invalid sha256 hash", op, key.Name)
+		}
+		if _, exists := byHash[hash]; exists {
+			return fmt.Errorf("%s: key %q: duplicate hash", op, key.Name)
+		}
+
+		principal := Principal{
+			Name:   key.Name,
+			Tenant: key.Tenant,
+			Scopes: make([]Scope, 0, len(key.Scopes)),
+
+			Namespaces: slices.Clone(key.Namespaces),
+		}
+		if principal.Tenant == "" {
+			principal.Tenant = key.Name
+		}
+		for _, name := range key.Scopes {
+			scope, err := ParseScope(name)
+			if err != nil {
+				return fmt.Errorf("%s: key %q: %q: %w", op, key.Name, name, err)
+			}
+			principal.Scopes = append(principal.Scopes, scope)
+		}
+
+		byHash[hash] = principal
+	}
+
+	a.mu.Lock()
+	a.byHash = byHash
+	a.mu.Unlock()
+
+	return nil
+}
+
+// Authenticate returns the principal of the key
+// or [ErrUnauthenticated] if the key is unknown.
+func (a *APIKeys) Authenticate(key string) (Principal, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	principal, ok := a.byHash[HashAPIKey(key)]
+	if !ok {
+		return Principal{}, ErrUnauthenticated
+	}
+	return principal, nil
+}
@@ -0,0 +1,82 @@
+package auth
+
+// Package auth authenticates API clients and describes what they are allowed to do.
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+var (
+	// ErrUnauthenticated is returned when credentials are missing or invalid.
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	// ErrUnknownScope is returned when a key is configured with an unknown scope.
+	ErrUnknownScope = errors.New("unknown scope")
+)
+
+type Scope string
+
+const (
+	ScopeTasksCreate Scope = "tasks:create"
+	ScopeTasksRead   Scope = "tasks:read"
+	ScopeTasksUpdate Scope = "tasks:update"
+	ScopeTasksCancel Scope = "tasks:cancel"
+	ScopeTasksDelete Scope = "tasks:delete"
+	// ScopeAdmin grants every other scope, access to tasks
+	// of all owners and to the admin API.
+	ScopeAdmin Scope = "admin"
+)
+
+var knownScopes = []Scope{ScopeTasksCreate, ScopeTasksRead, ScopeTasksUpdate, ScopeTasksCancel, ScopeTasksDelete, ScopeAdmin}
+
+// ParseScope returns the scope with the given name
+// or [ErrUnknownScope] if there is no such scope.
+func ParseScope(name string) (Scope, error) {
+	scope := Scope(name)
+	if !slices.Contains(knownScopes, scope) {
+		return "", ErrUnknownScope
+	}
+	return scope, nil
+}
+
+// Principal is an authenticated API client.
+type Principal struct {
+	// Name identifies the client, tasks record it as their owner.
+	Name string
+	// Tenant the tasks created by the client belong to.
+	Tenant string
+	Scopes []Scope
+	// Namespaces the client may access, all namespaces if empty.
+	Namespaces []string
+}
+
+// HasScope reports whether the principal is granted the scope.
+func (p Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// IsAdmin reports whether the principal has the [ScopeAdmin] scope.
+func (p Principal) IsAdmin() bool {
+	return slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// CanAccess reports whether the principal may access tasks of the namespace.
+func (p Principal) CanAccess(namespace string) bool {
+	return p.IsAdmin() || len(p.Namespaces) == 0 || slices.Contains(p.Namespaces, namespace)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx. ok is false
+// if there is none, which means authentication is disabled.
+func FromContext(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
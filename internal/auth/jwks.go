@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is returned when a token is signed by a key missing from the JWKS.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// maxJWKSSize limits the size of a JWKS document fetched by URL.
+const maxJWKSSize = 1 << 20
+
+// jwk is a single JSON Web Key (RFC 7517) of the RSA, EC or OKP type.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS is a set of public keys used to verify token signatures.
+// The keys are loaded from a local file or a URL and can be
+// refreshed at runtime to pick up rotated keys.
+type JWKS struct {
+	source string
+	fetch  func(ctx context.Context) ([]byte, error)
+
+	mu   sync.RWMutex
+	keys map[string]any
+}
+
+// NewJWKSFromFile returns a JWKS read from the file at path.
+func NewJWKSFromFile(ctx context.Context, path string) (*JWKS, error) {
+	return newJWKS(ctx, path, func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	})
+}
+
+// NewJWKSFromURL returns a JWKS fetched from url.
+func NewJWKSFromURL(ctx context.Context, url string, client *http.Client) (*JWKS, error) {
+	return newJWKS(ctx, url, func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+
+		return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	})
+}
+
+func newJWKS(ctx context.Context, source string, fetch func(ctx context.Context) ([]byte, error)) (*JWKS, error) {
+	j := &JWKS{
+		source: source,
+		fetch:  fetch,
+	}
+	if err := j.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Refresh reloads the keys from the source. On failure
+// the current keys are kept and the error is returned.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	const op = "auth.JWKS.Refresh"
+
+	data, err := j.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", op, j.source, err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", op, j.source, err)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+
+	return nil
+}
+
+// Run refreshes the keys every interval until ctx is done.
+func (j *JWKS) Run(ctx context.Context, log *slog.Logger, interval time.Duration) {
+	const op = "auth.JWKS.Run"
+
+	log = log.With(slog.String("op", op), slog.String("source", j.source))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.Refresh(ctx); err != nil {
+				log.Error("Failed to refresh JWKS, keeping the current keys", slog.Any("error", err))
+				continue
+			}
+			log.Debug("JWKS refreshed")
+		}
+	}
+}
+
+// Key returns the public key with the given key ID.
+// If kid is empty and the set has a single key, that key is returned.
+func (j *JWKS) Key(kid string) (any, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, nil
+		}
+	}
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func parseJWKS(data []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		// Keys meant for encryption are not used to verify signatures.
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
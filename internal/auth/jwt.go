@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrTokenExpired is returned for tokens past their expiration time.
+var ErrTokenExpired = errors.New("token expired")
+
+type JWTOptions struct {
+	// Issuer and Audience, if set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// TenantClaim names the claim with the tenant of the client.
+	// The sub claim is used if the token has no such claim.
+	TenantClaim string
+	// ScopesClaim names the claim with the scopes of the client,
+	// either a space-separated string or an array of strings.
+	// Unknown scopes are ignored.
+	ScopesClaim string
+	// NamespacesClaim names the claim with the namespaces the client
+	// may access in the same format as the scopes. Without the claim
+	// the client may access all namespaces.
+	NamespacesClaim string
+	// Leeway is the allowed clock skew for time-based claims.
+	Leeway time.Duration
+}
+
+// JWTVerifier authenticates clients by bearer tokens
+// signed by one of the keys of a [JWKS].
+type JWTVerifier struct {
+	jwks   *JWKS
+	opts   JWTOptions
+	parser *jwt.Parser
+}
+
+func NewJWTVerifier(jwks *JWKS, opts JWTOptions) *JWTVerifier {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{
+			"RS256", "RS384", "RS512",
+			"PS256", "PS384", "PS512",
+			"ES256", "ES384", "ES512",
+			"EdDSA",
+		}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &JWTVerifier{
+		jwks:   jwks,
+		opts:   opts,
+		parser: jwt.NewParser(parserOpts...),
+	}
+}
+
+// Authenticate verifies the token and returns the principal described by its claims.
+// It returns an error wrapping [ErrUnauthenticated], and also [ErrTokenExpired]
+// for expired tokens, if the token is not valid.
+func (v *JWTVerifier) Authenticate(token string) (Principal, error) {
+	const op = "auth.JWTVerifier.Authenticate"
+
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.jwks.Key(kid)
+	})
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return Principal{}, fmt.Errorf("%s: %w: %w", op, ErrUnauthenticated, ErrTokenExpired)
+	}
+	if err != nil {
+		return Principal{}, fmt.Errorf("%s: %w: %v", op, ErrUnauthenticated, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return Principal{}, fmt.Errorf("%s: %w: token has no subject", op, ErrUnauthenticated)
+	}
+
+	principal := Principal{
+		Name:   subject,
+		Tenant: subject,
+		Scopes: v.scopes(claims),
+
+		Namespaces: stringsClaim(claims, v.opts.NamespacesClaim),
+	}
+	if tenant, ok := claims[v.opts.TenantClaim].(string); ok && tenant != "" {
+		principal.Tenant = tenant
+	}
+
+	return principal, nil
+}
+
+func (v *JWTVerifier) scopes(claims jwt.MapClaims) []Scope {
+	names := stringsClaim(claims, v.opts.ScopesClaim)
+
+	scopes := make([]Scope, 0, len(names))
+	for _, name := range names {
+		if scope, err := ParseScope(name); err == nil {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// stringsClaim returns the values of a claim holding either
+// a space-separated string or an array of strings.
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	var values []string
+	switch raw := claims[name].(type) {
+	case string:
+		values = strings.Fields(raw)
+	case []any:
+		for _, value := range raw {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testIssuer = "https://issuer.test"
+
+type testKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return testKey{kid: kid, key: key}
+}
+
+func (k testKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = k.kid
+
+	signed, err := token.SignedString(k.key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func writeJWKS(t *testing.T, path string, keys ...testKey) {
+	t.Helper()
+
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: k.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(k.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.E)).Bytes()),
+		})
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal JWKS: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+}
+
+func newTestVerifier(t *testing.T, keys ...testKey) (*JWTVerifier, *JWKS, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, keys...)
+
+	jwks, err := NewJWKSFromFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("load JWKS: %v", err)
+	}
+
+	verifier := NewJWTVerifier(jwks, JWTOptions{
+		Issuer:      testIssuer,
+		TenantClaim: "tenant",
+		ScopesClaim: "scope",
+	})
+	return verifier, jwks, path
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":    testIssuer,
+		"sub":    "svc-reports",
+		"tenant": "acme",
+		"scope":  "tasks:read tasks:create unknown:scope",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifierAuthenticate(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	verifier, _, _ := newTestVerifier(t, key)
+
+	principal, err := verifier.Authenticate(key.sign(t, validClaims()))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+
+	if principal.Name != "svc-reports" || principal.Tenant != "acme" {
+		t.Errorf("principal = %+v, want name svc-reports and tenant acme", principal)
+	}
+	if want := []Scope{ScopeTasksRead, ScopeTasksCreate}; !slices.Equal(principal.Scopes, want) {
+		t.Errorf("scopes = %v, want %v", principal.Scopes, want)
+	}
+}
+
+func TestJWTVerifierScopesArrayAndTenantFallback(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	verifier, _, _ := newTestVerifier(t, key)
+
+	claims := validClaims()
+	delete(claims, "tenant")
+	claims["scope"] = []string{"admin"}
+
+	principal, err := verifier.Authenticate(key.sign(t, claims))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+
+	if principal.Tenant != "svc-reports" {
+		t.Errorf("tenant = %q, want the subject", principal.Tenant)
+	}
+	if !principal.IsAdmin() {
+		t.Errorf("scopes = %v, want admin", principal.Scopes)
+	}
+}
+
+func TestJWTVerifierRejects(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	other := newTestKey(t, "key-2")
+	verifier, _, _ := newTestVerifier(t, key)
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://other.test"
+
+	noExpiry := validClaims()
+	delete(noExpiry, "exp")
+
+	noSubject := validClaims()
+	delete(noSubject, "sub")
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		expired bool
+	}{
+		{name: "expired", token: key.sign(t, expired), expired: true},
+		{name: "wrong issuer", token: key.sign(t, wrongIssuer)},
+		{name: "no expiry", token: key.sign(t, noExpiry)},
+		{name: "no subject", token: key.sign(t, noSubject)},
+		{name: "unknown key", token: other.sign(t, validClaims())},
+		{name: "symmetric algorithm", token: hmac},
+		{name: "malformed", token: "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Authenticate(tt.token)
+			if !errors.Is(err, ErrUnauthenticated) {
+				t.Fatalf("err = %v, want ErrUnauthenticated", err)
+			}
+			if got := errors.Is(err, ErrTokenExpired); got != tt.expired {
+				t.Errorf("errors.Is(err, ErrTokenExpired) = %v, want %v", got, tt.expired)
+			}
+		})
+	}
+}
+
+func TestJWKSRefreshRotatesKeys(t *testing.T) {
+	oldKey := newTestKey(t, "key-1")
+	newKey := newTestKey(t, "key-2")
+	verifier, jwks, path := newTestVerifier(t, oldKey)
+
+	if _, err := verifier.Authenticate(newKey.sign(t, validClaims())); err == nil {
+		t.Fatal("token signed by a key missing from the JWKS was accepted")
+	}
+
+	writeJWKS(t, path, newKey)
+	if err := jwks.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	if _, err := verifier.Authenticate(newKey.sign(t, validClaims())); err != nil {
+		t.Errorf("token signed by the rotated key: %v", err)
+	}
+	if _, err := verifier.Authenticate(oldKey.sign(t, validClaims())); err == nil {
+		t.Error("token signed by the removed key was accepted")
+	}
+
+	// A broken file keeps the current keys.
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+	if err := jwks.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh of a malformed JWKS succeeded")
+	}
+	if _, err := verifier.Authenticate(newKey.sign(t, validClaims())); err != nil {
+		t.Errorf("token after failed refresh: %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"net/netip"
 	"os"
 	"time"
 
@@ -9,20 +12,190 @@ import (
 )
 
 type Config struct {
-	App  AppConfig  `yaml:"app"`
-	HTTP HTTPConfig `yaml:"http"`
+	App        AppConfig        `yaml:"app"`
+	HTTP       HTTPConfig       `yaml:"http"`
+	GRPC       GRPCConfig       `yaml:"grpc"`
+	Autoscale  AutoscaleConfig  `yaml:"autoscale"`
+	Tenants    TenantsConfig    `yaml:"tenants"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Namespaces NamespacesConfig `yaml:"namespaces"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+
+	path string
 }
 
 type AppConfig struct {
 	Env           string `env:"ENV" yaml:"env" env-required:"true"`
 	Workers       int    `env:"WORKERS" yaml:"workers" env-required:"true"`
 	TaskQueueSize int    `env:"TASK_QUEUE_SIZE" yaml:"task_queue_size" env-required:"true"`
+
+	// TypeLimits limits the number of concurrently running tasks
+	// per task type in the default worker group.
+	TypeLimits map[string]int `yaml:"type_limits"`
+	// Groups are dedicated worker groups with their own workers and queue.
+	// Tasks of types not listed in any group go to the default group.
+	Groups []WorkerGroupConfig `yaml:"groups"`
+}
+
+type WorkerGroupConfig struct {
+	Name       string         `yaml:"name"`
+	Workers    int            `yaml:"workers"`
+	QueueSize  int            `yaml:"queue_size"`
+	Types      []string       `yaml:"types"`
+	TypeLimits map[string]int `yaml:"type_limits"`
 }
 
 type HTTPConfig struct {
 	Port         int           `env:"PORT" yaml:"port" env-required:"true"`
 	WriteTimeout time.Duration `env:"WRITE_TIMEOUT" yaml:"write_timeout" env-default:"10"`
 	ReadTimeout  time.Duration `env:"READ_TIMEOUT" yaml:"read_timeout" env-default:"10"`
+
+	// IdempotencyTTL is how long responses to requests
+	// with an Idempotency-Key header are remembered.
+	IdempotencyTTL time.Duration `env:"IDEMPOTENCY_TTL" yaml:"idempotency_ttl" env-default:"24h"`
+
+	// TrustedProxies are the addresses and CIDR ranges of reverse proxies
+	// whose X-Forwarded-For and X-Real-IP headers give the client address.
+	// Without them the headers are ignored, since any client could set them
+	// to get a rate limit bucket of its own.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" yaml:"trusted_proxies"`
+
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	WebSocket WebSocketConfig `yaml:"websocket"`
+}
+
+// WebSocketConfig configures connections of the /api/v1/ws endpoint.
+// A connection that sends nothing, not even pongs, for PongTimeout
+// is closed, so it must be longer than PingInterval. A client that
+// leaves SendBuffer events unread is disconnected.
+type WebSocketConfig struct {
+	PingInterval     time.Duration `env:"WS_PING_INTERVAL" yaml:"ping_interval" env-default:"15s"`
+	PongTimeout      time.Duration `env:"WS_PONG_TIMEOUT" yaml:"pong_timeout" env-default:"45s"`
+	SendBuffer       int           `env:"WS_SEND_BUFFER" yaml:"send_buffer" env-default:"256"`
+	MaxSubscriptions int           `env:"WS_MAX_SUBSCRIPTIONS" yaml:"max_subscriptions" env-default:"100"`
+}
+
+// GRPCConfig configures the gRPC API served next to the HTTP one.
+type GRPCConfig struct {
+	Enabled bool `env:"GRPC_ENABLED" yaml:"enabled" env-default:"false"`
+	Port    int  `env:"GRPC_PORT" yaml:"port" env-default:"50051"`
+	// WatchInterval is how often watched tasks are checked for changes.
+	WatchInterval time.Duration `env:"GRPC_WATCH_INTERVAL" yaml:"watch_interval" env-default:"500ms"`
+}
+
+// RateLimitConfig configures token bucket rate limiting of the /api routes
+// per client: per API key or token subject, or per IP address without
+// authentication. Create limits requests that create or change tasks,
+// Read limits GET requests.
+type RateLimitConfig struct {
+	Enabled bool            `env:"RATE_LIMIT_ENABLED" yaml:"enabled" env-default:"false"`
+	Create  RateLimitBucket `yaml:"create" env-prefix:"RATE_LIMIT_CREATE_"`
+	Read    RateLimitBucket `yaml:"read" env-prefix:"RATE_LIMIT_READ_"`
+}
+
+// RateLimitBucket allows Burst requests at once and Rate requests
+// per second on average. A zero Rate means no limit.
+type RateLimitBucket struct {
+	Rate  float64 `env:"RATE" yaml:"rate"`
+	Burst int     `env:"BURST" yaml:"burst"`
+}
+
+// AutoscaleConfig configures the controller that adjusts the number
+// of workers between MinWorkers and MaxWorkers based on the queue wait
+// time and backlog length. When disabled, AppConfig.Workers is used as is.
+type AutoscaleConfig struct {
+	Enabled           bool          `env:"AUTOSCALE_ENABLED" yaml:"enabled" env-default:"false"`
+	MinWorkers        int           `env:"AUTOSCALE_MIN_WORKERS" yaml:"min_workers" env-default:"1"`
+	MaxWorkers        int           `env:"AUTOSCALE_MAX_WORKERS" yaml:"max_workers" env-default:"10"`
+	Interval          time.Duration `env:"AUTOSCALE_INTERVAL" yaml:"interval" env-default:"5s"`
+	TargetQueueWait   time.Duration `env:"AUTOSCALE_TARGET_QUEUE_WAIT" yaml:"target_queue_wait" env-default:"1s"`
+	MaxBacklog        int           `env:"AUTOSCALE_MAX_BACKLOG" yaml:"max_backlog" env-default:"10"`
+	Step              int           `env:"AUTOSCALE_STEP" yaml:"step" env-default:"1"`
+	ScaleUpCooldown   time.Duration `env:"AUTOSCALE_SCALE_UP_COOLDOWN" yaml:"scale_up_cooldown" env-default:"15s"`
+	ScaleDownCooldown time.Duration `env:"AUTOSCALE_SCALE_DOWN_COOLDOWN" yaml:"scale_down_cooldown" env-default:"1m"`
+}
+
+// TenantsConfig configures fair sharing of worker pools between tenants.
+// Weight, MaxQueued and MaxRunning apply to every tenant in every worker
+// group, zero limits mean no limit. Overrides change them for single tenants.
+type TenantsConfig struct {
+	Weight     int              `env:"TENANT_WEIGHT" yaml:"weight" env-default:"1"`
+	MaxQueued  int              `env:"TENANT_MAX_QUEUED" yaml:"max_queued" env-default:"0"`
+	MaxRunning int              `env:"TENANT_MAX_RUNNING" yaml:"max_running" env-default:"0"`
+	Overrides  []TenantOverride `yaml:"overrides"`
+}
+
+type TenantOverride struct {
+	Name       string `yaml:"name"`
+	Weight     int    `yaml:"weight"`
+	MaxQueued  int    `yaml:"max_queued"`
+	MaxRunning int    `yaml:"max_running"`
+}
+
+// TracingConfig configures OpenTelemetry tracing. Spans are exported
+// to an OTLP/HTTP collector at Endpoint ("otlp"), printed to stdout
+// ("stdout") or appended to File ("file").
+type TracingConfig struct {
+	Enabled     bool    `env:"TRACING_ENABLED" yaml:"enabled" env-default:"false"`
+	ServiceName string  `env:"TRACING_SERVICE_NAME" yaml:"service_name" env-default:"task-manager-api"`
+	Exporter    string  `env:"TRACING_EXPORTER" yaml:"exporter" env-default:"stdout"`
+	Endpoint    string  `env:"TRACING_ENDPOINT" yaml:"endpoint"`
+	Insecure    bool    `env:"TRACING_INSECURE" yaml:"insecure" env-default:"false"`
+	File        string  `env:"TRACING_FILE" yaml:"file" env-default:"traces.jsonl"`
+	SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" yaml:"sample_ratio" env-default:"1"`
+}
+
+// NamespacesConfig configures quotas applied to every namespace:
+// the number of stored tasks and of tasks running at the same time
+// in each worker group. Zero quotas mean no limit.
+// Overrides change them for single namespaces.
+type NamespacesConfig struct {
+	MaxTasks   int                 `env:"NAMESPACE_MAX_TASKS" yaml:"max_tasks" env-default:"0"`
+	MaxRunning int                 `env:"NAMESPACE_MAX_RUNNING" yaml:"max_running" env-default:"0"`
+	Overrides  []NamespaceOverride `yaml:"overrides"`
+}
+
+type NamespaceOverride struct {
+	Name       string `yaml:"name"`
+	MaxTasks   int    `yaml:"max_tasks"`
+	MaxRunning int    `yaml:"max_running"`
+}
+
+// AuthConfig configures API key authentication of the /api and /admin routes.
+// Keys are taken from Keys and from the YAML file KeysFile, if set.
+// Bearer token authentication is configured separately in JWT,
+// both methods can be enabled at the same time.
+type AuthConfig struct {
+	Enabled  bool           `env:"AUTH_ENABLED" yaml:"enabled" env-default:"false"`
+	KeysFile string         `env:"AUTH_KEYS_FILE" yaml:"keys_file"`
+	Keys     []APIKeyConfig `yaml:"keys"`
+	JWT      JWTConfig      `yaml:"jwt"`
+}
+
+// JWTConfig configures authentication by JWT bearer tokens verified
+// against the keys of a JWKS read from JWKSFile or fetched from JWKSURL.
+// The keys are reloaded every RefreshInterval and on SIGHUP.
+type JWTConfig struct {
+	Enabled         bool          `env:"AUTH_JWT_ENABLED" yaml:"enabled" env-default:"false"`
+	JWKSFile        string        `env:"AUTH_JWT_JWKS_FILE" yaml:"jwks_file"`
+	JWKSURL         string        `env:"AUTH_JWT_JWKS_URL" yaml:"jwks_url"`
+	RefreshInterval time.Duration `env:"AUTH_JWT_REFRESH_INTERVAL" yaml:"refresh_interval" env-default:"1m"`
+	Issuer          string        `env:"AUTH_JWT_ISSUER" yaml:"issuer"`
+	Audience        string        `env:"AUTH_JWT_AUDIENCE" yaml:"audience"`
+	TenantClaim     string        `env:"AUTH_JWT_TENANT_CLAIM" yaml:"tenant_claim" env-default:"tenant"`
+	ScopesClaim     string        `env:"AUTH_JWT_SCOPES_CLAIM" yaml:"scopes_claim" env-default:"scope"`
+	NamespacesClaim string        `env:"AUTH_JWT_NAMESPACES_CLAIM" yaml:"namespaces_claim" env-default:"namespaces"`
+	Leeway          time.Duration `env:"AUTH_JWT_LEEWAY" yaml:"leeway" env-default:"30s"`
+}
+
+type APIKeyConfig struct {
+	Name string `yaml:"name"`
+	// SHA256 is the hex-encoded SHA-256 hash of the key.
+	SHA256 string   `yaml:"sha256"`
+	Tenant string   `yaml:"tenant"`
+	Scopes []string `yaml:"scopes"`
+	// Namespaces the key may access, all namespaces if empty.
+	Namespaces []string `yaml:"namespaces"`
 }
 
 // MustLoad загружает конфигурацию из файла, путь к которому указан в флаге `config`
@@ -42,15 +215,104 @@ func MustLoad() *Config {
 		panic("there is no config file: " + cfgPath)
 	}
 
-	cfg := &Config{}
-
-	if err := cleanenv.ReadConfig(cfgPath, cfg); err != nil {
+	cfg, err := load(cfgPath)
+	if err != nil {
 		panic("failed to load config: " + err.Error())
 	}
 
 	return cfg
 }
 
+// Reload reads the configuration again from the file it was loaded from.
+// The current configuration is left untouched.
+func (c *Config) Reload() (*Config, error) {
+	return load(c.path)
+}
+
+func load(path string) (*Config, error) {
+	cfg := &Config{path: path}
+
+	if err := cleanenv.ReadConfig(path, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// validate checks the settings that would make the application
+// misbehave instead of failing to start.
+func (c *Config) validate() error {
+	var errs []error
+
+	if c.App.Workers < 1 {
+		errs = append(errs, errors.New("app.workers must be positive"))
+	}
+	if c.App.TaskQueueSize < 1 {
+		errs = append(errs, errors.New("app.task_queue_size must be positive"))
+	}
+	for i, group := range c.App.Groups {
+		if group.Name == "" {
+			errs = append(errs, fmt.Errorf("app.groups[%d].name must not be empty", i))
+		}
+		if group.Workers < 1 {
+			errs = append(errs, fmt.Errorf("app.groups[%d].workers must be positive", i))
+		}
+		if group.QueueSize < 1 {
+			errs = append(errs, fmt.Errorf("app.groups[%d].queue_size must be positive", i))
+		}
+	}
+
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if !validProxy(proxy) {
+			errs = append(errs, fmt.Errorf("http.trusted_proxies: %q is not an IP address or CIDR range", proxy))
+		}
+	}
+
+	if ws := c.HTTP.WebSocket; ws.PingInterval <= 0 {
+		errs = append(errs, errors.New("http.websocket.ping_interval must be positive"))
+	} else if ws.PongTimeout <= ws.PingInterval {
+		errs = append(errs, fmt.Errorf("http.websocket.pong_timeout %s must be longer than ping_interval %s", ws.PongTimeout, ws.PingInterval))
+	}
+	if c.HTTP.WebSocket.SendBuffer < 1 {
+		errs = append(errs, errors.New("http.websocket.send_buffer must be positive"))
+	}
+
+	if c.GRPC.Enabled && c.GRPC.WatchInterval <= 0 {
+		errs = append(errs, errors.New("grpc.watch_interval must be positive"))
+	}
+
+	if a := c.Autoscale; a.Enabled {
+		if a.MinWorkers < 1 {
+			errs = append(errs, errors.New("autoscale.min_workers must be positive"))
+		}
+		if a.MaxWorkers < a.MinWorkers {
+			errs = append(errs, fmt.Errorf("autoscale.max_workers %d is less than min_workers %d", a.MaxWorkers, a.MinWorkers))
+		}
+		if a.Interval <= 0 {
+			errs = append(errs, errors.New("autoscale.interval must be positive"))
+		}
+		if a.Step < 1 {
+			errs = append(errs, errors.New("autoscale.step must be positive"))
+		}
+		if a.ScaleUpCooldown < 0 || a.ScaleDownCooldown < 0 {
+			errs = append(errs, errors.New("autoscale cooldowns must not be negative"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validProxy(proxy string) bool {
+	if _, err := netip.ParsePrefix(proxy); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(proxy)
+	return err == nil
+}
+
 // fetchConfigPath получает путь к конфигурационному файлу из флага `config` или
 // переменной окружения `CONFIG_PATH`. Если путь не указан, возвращает пустую строку.
 func fetchConfigPath() string {
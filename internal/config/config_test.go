@@ -0,0 +1,196 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const baseConfig = `
+app:
+    env: local
+    workers: 2
+    task_queue_size: 10
+http:
+    port: 8080
+    write_timeout: 5s
+    read_timeout: 5s
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+// withGroups returns baseConfig with the worker groups.
+func withGroups(groups string) string {
+	return strings.Replace(baseConfig, "task_queue_size: 10\n", "task_queue_size: 10\n    groups: "+groups+"\n", 1)
+}
+
+func TestLoadValidatesAutoscale(t *testing.T) {
+	tests := []struct {
+		name      string
+		autoscale string
+		wantErr   string
+	}{
+		{name: "valid", autoscale: "{enabled: true, min_workers: 1, max_workers: 4, interval: 1s, step: 1}"},
+		{name: "disabled", autoscale: "{enabled: false, interval: 0s}"},
+		{name: "negative interval", autoscale: "{enabled: true, interval: -1s}", wantErr: "autoscale.interval"},
+		{name: "min above max", autoscale: "{enabled: true, min_workers: 5, max_workers: 2}", wantErr: "autoscale.max_workers"},
+		{name: "negative step", autoscale: "{enabled: true, step: -1}", wantErr: "autoscale.step"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(writeConfig(t, baseConfig+"autoscale: "+tt.autoscale+"\n"))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("load returned %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReloadValidates(t *testing.T) {
+	path := writeConfig(t, baseConfig)
+	cfg, err := load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(baseConfig+"autoscale: {enabled: true, interval: -1s}\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := cfg.Reload(); err == nil {
+		t.Error("Reload accepted an invalid config")
+	}
+}
+
+func TestLoadValidatesWorkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "valid group",
+			config: withGroups("[{name: reports, workers: 2, queue_size: 5}]"),
+		},
+		{
+			name:    "no workers",
+			config:  strings.Replace(baseConfig, "workers: 2", "workers: -1", 1),
+			wantErr: "app.workers",
+		},
+		{
+			name:    "no queue",
+			config:  strings.Replace(baseConfig, "task_queue_size: 10", "task_queue_size: -1", 1),
+			wantErr: "app.task_queue_size",
+		},
+		{
+			name:    "group without workers",
+			config:  withGroups("[{name: reports, queue_size: 5}]"),
+			wantErr: "app.groups[0].workers",
+		},
+		{
+			name:    "group without queue",
+			config:  withGroups("[{name: reports, workers: 2}]"),
+			wantErr: "app.groups[0].queue_size",
+		},
+		{
+			name:    "group without name",
+			config:  withGroups("[{workers: 2, queue_size: 5}]"),
+			wantErr: "app.groups[0].name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(writeConfig(t, tt.config))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("load returned %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadValidatesWebSocket(t *testing.T) {
+	tests := []struct {
+		name      string
+		websocket string
+		wantErr   string
+	}{
+		{name: "defaults", websocket: "{}"},
+		{name: "valid", websocket: "{ping_interval: 1s, pong_timeout: 3s, send_buffer: 16}"},
+		{name: "negative ping interval", websocket: "{ping_interval: -1s}", wantErr: "http.websocket.ping_interval"},
+		{name: "pong timeout not above ping interval", websocket: "{ping_interval: 10s, pong_timeout: 10s}", wantErr: "http.websocket.pong_timeout"},
+		{name: "negative send buffer", websocket: "{send_buffer: -1}", wantErr: "http.websocket.send_buffer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := strings.Replace(baseConfig, "read_timeout: 5s\n", "read_timeout: 5s\n    websocket: "+tt.websocket+"\n", 1)
+			_, err := load(writeConfig(t, content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("load returned %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadValidatesGRPC(t *testing.T) {
+	tests := []struct {
+		name    string
+		grpc    string
+		wantErr string
+	}{
+		{name: "valid", grpc: "{enabled: true, watch_interval: 100ms}"},
+		{name: "disabled", grpc: "{enabled: false, watch_interval: -1s}"},
+		{name: "negative watch interval", grpc: "{enabled: true, watch_interval: -1s}", wantErr: "grpc.watch_interval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(writeConfig(t, baseConfig+"grpc: "+tt.grpc+"\n"))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("load returned %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadValidatesTrustedProxies(t *testing.T) {
+	valid := strings.Replace(baseConfig, "read_timeout: 5s\n", "read_timeout: 5s\n    trusted_proxies: [10.0.0.1, 10.1.0.0/16, \"::1\"]\n", 1)
+	if _, err := load(writeConfig(t, valid)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	invalid := strings.Replace(baseConfig, "read_timeout: 5s\n", "read_timeout: 5s\n    trusted_proxies: [proxy.local]\n", 1)
+	if _, err := load(writeConfig(t, invalid)); err == nil || !strings.Contains(err.Error(), "http.trusted_proxies") {
+		t.Fatalf("load returned %v, want an error about http.trusted_proxies", err)
+	}
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Limits of the metadata of tasks set by clients.
+const (
+	MaxLabels = 64
+	// MaxLabelLength limits label values and the names of label keys.
+	MaxLabelLength = 63
+	// MaxLabelPrefixLength limits the optional DNS prefix of label keys.
+	MaxLabelPrefixLength = 253
+
+	MaxDescriptionLength = 1024
+
+	// MinPriority and MaxPriority bound the priority of tasks,
+	// DefaultPriority is the priority of tasks created without one.
+	MinPriority     = -100
+	MaxPriority     = 100
+	DefaultPriority = 0
+)
+
+var (
+	labelNamePattern   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?$`)
+	labelPrefixPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+)
+
+// ValidateLabelKey checks that key is a label key: a name of up to
+// [MaxLabelLength] alphanumeric characters, '-', '_' or '.', starting
+// and ending with an alphanumeric character, optionally prefixed with
+// a lower-case DNS subdomain and '/', e.g. "example.com/batch".
+// The error describes what is wrong with the key.
+func ValidateLabelKey(key string) error {
+	name := key
+	if prefix, rest, ok := strings.Cut(key, "/"); ok {
+		if len(prefix) > MaxLabelPrefixLength || !labelPrefixPattern.MatchString(prefix) {
+			return errors.New("must have a lower-case DNS subdomain of at most " +
+				strconv.Itoa(MaxLabelPrefixLength) + " characters as prefix")
+		}
+		name = rest
+	}
+
+	if name == "" {
+		return errors.New("must not be empty")
+	}
+	return validateLabelName(name)
+}
+
+// ValidateLabels checks the number of labels and every key and value,
+// see [ValidateLabelKey]. The error describes the first invalid label
+// in the order of the keys.
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return errors.New("labels must have at most " + strconv.Itoa(MaxLabels) + " labels")
+	}
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		if err := ValidateLabelKey(key); err != nil {
+			return fmt.Errorf("label key %q %w", key, err)
+		}
+		if err := ValidateLabelValue(labels[key]); err != nil {
+			return fmt.Errorf("value of label %q %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ValidateLabelValue checks that value is empty or a valid label name,
+// see [ValidateLabelKey].
+func ValidateLabelValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	return validateLabelName(value)
+}
+
+func validateLabelName(name string) error {
+	if len(name) > MaxLabelLength {
+		return errors.New("must be at most " + strconv.Itoa(MaxLabelLength) + " characters long")
+	}
+	if !labelNamePattern.MatchString(name) {
+		return errors.New("must consist of alphanumeric characters, '-', '_' or '.' " +
+			"and start and end with an alphanumeric character")
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "regexp"
+
+// NamespacePattern matches namespace names: DNS labels of at most 63 characters.
+const NamespacePattern = `^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`
+
+var namespaceRe = regexp.MustCompile(NamespacePattern)
+
+// ValidNamespace reports whether name is a valid namespace name.
+func ValidNamespace(name string) bool {
+	return namespaceRe.MatchString(name)
+}
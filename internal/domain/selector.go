@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SelectorOperator is the operator of a [Requirement].
+type SelectorOperator string
+
+const (
+	OpEquals       SelectorOperator = "="
+	OpNotEquals    SelectorOperator = "!="
+	OpIn           SelectorOperator = "in"
+	OpNotIn        SelectorOperator = "notin"
+	OpExists       SelectorOperator = "exists"
+	OpDoesNotExist SelectorOperator = "!"
+)
+
+// Requirement is a condition on one label of a task.
+type Requirement struct {
+	Key      string
+	Operator SelectorOperator
+	// Values holds the value of [OpEquals] and [OpNotEquals]
+	// and the set of [OpIn] and [OpNotIn].
+	Values []string
+}
+
+// Matches reports whether the labels satisfy the requirement.
+// As in Kubernetes, [OpNotEquals] and [OpNotIn] match tasks without the label.
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case OpEquals, OpIn:
+		return ok && slices.Contains(r.Values, value)
+	case OpNotEquals, OpNotIn:
+		return !ok || !slices.Contains(r.Values, value)
+	case OpExists:
+		return ok
+	case OpDoesNotExist:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// Positive reports whether the requirement only matches tasks having
+// the label, so the tasks can be looked up by it.
+func (r Requirement) Positive() bool {
+	return r.Operator == OpEquals || r.Operator == OpIn || r.Operator == OpExists
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case OpEquals, OpNotEquals:
+		return r.Key + string(r.Operator) + r.Values[0]
+	case OpIn, OpNotIn:
+		return r.Key + " " + string(r.Operator) + " (" + strings.Join(r.Values, ",") + ")"
+	case OpDoesNotExist:
+		return "!" + r.Key
+	default:
+		return r.Key
+	}
+}
+
+// Selector selects tasks by their labels with Kubernetes-style
+// requirements, all of which must match. An empty selector
+// matches every task.
+type Selector []Requirement
+
+// Matches reports whether the labels satisfy every requirement.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) String() string {
+	requirements := make([]string, 0, len(s))
+	for _, r := range s {
+		requirements = append(requirements, r.String())
+	}
+	return strings.Join(requirements, ",")
+}
+
+// ParseSelector parses a comma-separated list of requirements:
+// "key=value" (or "key==value"), "key!=value", "key in (v1,v2)",
+// "key notin (v1,v2)", "key" and "!key", e.g. "env=prod,batch in (a,b),!draft".
+// Keys and values must be valid labels, see [ValidateLabelKey].
+// The error describes what is wrong with the selector.
+func ParseSelector(s string) (Selector, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	parts, err := splitRequirements(s)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := make(Selector, 0, len(parts))
+	for _, part := range parts {
+		r, err := parseRequirement(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("requirement %q: %w", strings.TrimSpace(part), err)
+		}
+		selector = append(selector, r)
+	}
+
+	return selector, nil
+}
+
+// splitRequirements splits s by commas outside of parentheses.
+func splitRequirements(s string) ([]string, error) {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, errors.New("nested parentheses are not allowed")
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, errors.New("unbalanced parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, errors.New("unbalanced parentheses")
+	}
+
+	return append(parts, s[start:]), nil
+}
+
+func parseRequirement(part string) (Requirement, error) {
+	if part == "" {
+		return Requirement{}, errors.New("must not be empty")
+	}
+
+	if key, ok := strings.CutPrefix(part, "!"); ok && !strings.Contains(key, "=") {
+		return newRequirement(strings.TrimSpace(key), OpDoesNotExist)
+	}
+
+	if strings.Contains(part, "(") {
+		key, rest, _ := strings.Cut(part, " ")
+		rest = strings.TrimSpace(rest)
+
+		op := OpIn
+		set, ok := strings.CutPrefix(rest, string(OpIn))
+		if !ok {
+			op = OpNotIn
+			set, ok = strings.CutPrefix(rest, string(OpNotIn))
+		}
+		set = strings.TrimSpace(set)
+		if !ok || !strings.HasPrefix(set, "(") || !strings.HasSuffix(set, ")") {
+			return Requirement{}, errors.New(`must be "key in (values)" or "key notin (values)"`)
+		}
+
+		set = strings.TrimSpace(set[1 : len(set)-1])
+		if set == "" {
+			return Requirement{}, errors.New("must list at least one value")
+		}
+
+		var values []string
+		for value := range strings.SplitSeq(set, ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+		return newRequirement(key, op, values...)
+	}
+
+	if key, value, ok := strings.Cut(part, "!="); ok {
+		return newRequirement(strings.TrimSpace(key), OpNotEquals, strings.TrimSpace(value))
+	}
+	if key, value, ok := strings.Cut(part, "="); ok {
+		value = strings.TrimPrefix(value, "=")
+		return newRequirement(strings.TrimSpace(key), OpEquals, strings.TrimSpace(value))
+	}
+
+	return newRequirement(part, OpExists)
+}
+
+func newRequirement(key string, op SelectorOperator, values ...string) (Requirement, error) {
+	if err := ValidateLabelKey(key); err != nil {
+		return Requirement{}, fmt.Errorf("key %w", err)
+	}
+	for _, value := range values {
+		if err := ValidateLabelValue(value); err != nil {
+			return Requirement{}, fmt.Errorf("value %q %w", value, err)
+		}
+	}
+
+	return Requirement{Key: key, Operator: op, Values: values}, nil
+}
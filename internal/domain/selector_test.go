@@ -0,0 +1,66 @@
+package domain
+
+import "testing"
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+		wantErr  bool
+	}{
+		{selector: "", want: ""},
+		{selector: "env=prod", want: "env=prod"},
+		{selector: "env == prod", want: "env=prod"},
+		{selector: "env!=prod", want: "env!=prod"},
+		{selector: "env=prod, batch in (a, b),!draft", want: "env=prod,batch in (a,b),!draft"},
+		{selector: "example.com/batch notin (a),release", want: "example.com/batch notin (a),release"},
+		{selector: "env=", want: "env="},
+		{selector: "env in ()", wantErr: true},
+		{selector: "env in (a", wantErr: true},
+		{selector: "env in ((a))", wantErr: true},
+		{selector: "env is (a)", wantErr: true},
+		{selector: "env=prod,", wantErr: true},
+		{selector: "-env", wantErr: true},
+		{selector: "env=not valid", wantErr: true},
+	}
+	for _, tt := range tests {
+		selector, err := ParseSelector(tt.selector)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSelector(%q) error = %v, want error %v", tt.selector, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && selector.String() != tt.want {
+			t.Errorf("ParseSelector(%q) = %q, want %q", tt.selector, selector.String(), tt.want)
+		}
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "batch": "a"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=prod", want: true},
+		{selector: "env=dev", want: false},
+		{selector: "env!=dev", want: true},
+		{selector: "release!=v1", want: true},
+		{selector: "batch in (a,b)", want: true},
+		{selector: "batch notin (a,b)", want: false},
+		{selector: "release notin (v1)", want: true},
+		{selector: "env", want: true},
+		{selector: "!draft", want: true},
+		{selector: "env=prod,release", want: false},
+	}
+	for _, tt := range tests {
+		selector, err := ParseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q): %v", tt.selector, err)
+		}
+		if got := selector.Matches(labels); got != tt.want {
+			t.Errorf("%q matches %v = %v, want %v", tt.selector, labels, got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,26 @@
 package domain
 
 import (
-	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 )
 
 type TaskStatus string
 
+const (
+	// DefaultTaskType is the type of tasks created without an explicit type.
+	DefaultTaskType = "default"
+
+	// DefaultTenant owns tasks created without an explicit tenant.
+	DefaultTenant = "default"
+
+	// DefaultNamespace holds tasks managed through the routes
+	// without an explicit namespace.
+	DefaultNamespace = "default"
+)
+
 const (
 	StatusPending   TaskStatus = "pending"
 	StatusRunning              = "running"
@@ -17,17 +30,176 @@ const (
 )
 
 type Task struct {
-	UUID      string
+	UUID string
+	// Namespace isolates tasks of different teams: a task is only
+	// visible through its own namespace.
+	Namespace string
+	Type      string
+	Payload   any
+	Tenant    string
+	// Owner is the name of the API client that created the task,
+	// empty if authentication is disabled.
+	Owner string
+	// Labels and Description are free-form metadata set by clients,
+	// see [ValidateLabelKey] and [ValidateLabelValue].
+	Labels      map[string]string
+	Description string
+	// Priority orders queued tasks of a tenant: tasks with a higher
+	// priority run first, tasks of the same priority in the order
+	// they were queued. The shares of tenants are not affected.
+	Priority int
+	// RunAt holds the task in the queue until this time,
+	// a zero RunAt lets it run as soon as a worker is free.
+	RunAt     time.Time
 	Status    TaskStatus
 	CreatedAt time.Time
 	StartedAt time.Time
 	UpdatedAt time.Time
 	Result    any
 	Error     error
+
+	// RequestID is the ID of the request that created the task,
+	// it ties logs of the task execution to the request.
+	RequestID string
+	// TraceContext is the trace context of the request that created
+	// the task in the W3C Trace Context format, so the execution
+	// of the task can be traced as its continuation.
+	TraceContext map[string]string
+
+	// RetryOf is the UUID of the task this one re-runs, empty for original tasks.
+	RetryOf string
+	// Retries lists UUIDs of the tasks created as re-runs of this one.
+	Retries []string
+
+	// History lists the status transitions and edits of the task
+	// in the order they happened, starting with its creation.
+	// It is append-only.
+	History []Transition
+
+	// Version is set to 1 when the task is stored and grows with
+	// every change of the stored task, so clients can detect that
+	// the task changed since they read it.
+	Version uint64
+}
+
+const (
+	// ActorSystem is the actor of transitions made by the service
+	// itself, e.g. by the worker executing the task.
+	ActorSystem = "system"
+
+	// ActorAnonymous is the actor of transitions requested
+	// by clients when authentication is disabled.
+	ActorAnonymous = "anonymous"
+)
+
+// Transition is a change of the status of a task, or an edit
+// of the task that keeps its status, see [Transition.IsEdit].
+type Transition struct {
+	// From is empty for the transition creating the task.
+	From TaskStatus
+	To   TaskStatus
+	At   time.Time
+	// Actor is the name of the API client that caused the transition,
+	// or [ActorSystem].
+	Actor  string
+	Reason string
+}
+
+// IsEdit reports whether the transition records an edit of the task
+// rather than a change of its status.
+func (t Transition) IsEdit() bool {
+	return t.From == t.To
+}
+
+// QueueWait returns the time the task waited in the queue before it
+// started, or until it was canceled or rejected if it never started.
+// The wait of a task still in the queue is measured until now.
+func (t *Task) QueueWait() time.Duration {
+	queued, ok := t.transitionTo(StatusPending)
+	if !ok {
+		return 0
+	}
+	if started, ok := t.transitionTo(StatusRunning); ok {
+		return started.At.Sub(queued.At)
+	}
+	if finished, ok := t.finished(); ok {
+		return finished.At.Sub(queued.At)
+	}
+	return time.Since(queued.At)
+}
+
+// ExecutionTime returns the time the task has been running,
+// zero if it never started.
+func (t *Task) ExecutionTime() time.Duration {
+	started, ok := t.transitionTo(StatusRunning)
+	if !ok {
+		return 0
+	}
+	if finished, ok := t.finished(); ok {
+		return finished.At.Sub(started.At)
+	}
+	return time.Since(started.At)
+}
+
+// transitionTo returns the first transition of the task to the status.
+func (t *Task) transitionTo(status TaskStatus) (Transition, bool) {
+	for _, transition := range t.History {
+		if transition.To == status {
+			return transition, true
+		}
+	}
+	return Transition{}, false
+}
+
+// finished returns the transition of the task to its terminal status.
+// Edits may follow it.
+func (t *Task) finished() (Transition, bool) {
+	for _, transition := range t.History {
+		if transition.To.IsTerminal() && !transition.IsEdit() {
+			return transition, true
+		}
+	}
+	return Transition{}, false
+}
+
+// IsTerminal reports whether the status is final, i.e. the task
+// will not be executed anymore.
+func (s TaskStatus) IsTerminal() bool {
+	switch s {
+	case StatusCompleted, StatusFailed, StatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// transitions lists the statuses a task may move to from each status.
+// Terminal statuses have no way out: a task is re-run as a new task.
+var transitions = map[TaskStatus][]TaskStatus{
+	StatusPending: {StatusRunning, StatusCanceled, StatusFailed},
+	StatusRunning: {StatusCompleted, StatusFailed, StatusCanceled},
+}
+
+// CanTransitionTo reports whether a task in the status may move to next.
+// Staying in the same status is not a transition.
+func (s TaskStatus) CanTransitionTo(next TaskStatus) bool {
+	return slices.Contains(transitions[s], next)
+}
+
+// CompareCreation orders tasks by creation time, tasks created
+// at the same time by UUID, so the order is stable.
+func CompareCreation(a, b Task) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.UUID, b.UUID)
 }
 
 func (t *Task) RunningDuration() time.Duration {
-	fmt.Println(t.StartedAt.String())
+	if t.StartedAt.IsZero() {
+		// The task never started, e.g. it was rejected by the worker pool.
+		return 0
+	}
 	switch t.Status {
 	case StatusPending:
 		return 0
@@ -43,7 +215,11 @@ func (t *Task) RunningDuration() time.Duration {
 func (t *Task) LogValue() slog.Value {
 	return slog.GroupValue(
 		slog.String("uuid", t.UUID),
+		slog.String("namespace", t.Namespace),
+		slog.String("type", t.Type),
+		slog.String("tenant", t.Tenant),
 		slog.String("status", string(t.Status)),
+		slog.String("request_id", t.RequestID),
 		slog.Time("created_at", t.CreatedAt),
 		slog.Time("updated_at", t.UpdatedAt),
 	)
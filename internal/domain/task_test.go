@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskDurationsFromHistory(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	transition := func(from, to TaskStatus, after time.Duration) Transition {
+		return Transition{From: from, To: to, At: created.Add(after)}
+	}
+
+	tests := []struct {
+		name          string
+		history       []Transition
+		wantQueueWait time.Duration
+		wantExecution time.Duration
+	}{
+		{
+			name: "completed",
+			history: []Transition{
+				transition("", StatusPending, 0),
+				transition(StatusPending, StatusRunning, 2*time.Second),
+				transition(StatusRunning, StatusCompleted, 7*time.Second),
+			},
+			wantQueueWait: 2 * time.Second,
+			wantExecution: 5 * time.Second,
+		},
+		{
+			name: "canceled in the queue",
+			history: []Transition{
+				transition("", StatusPending, 0),
+				transition(StatusPending, StatusCanceled, 3*time.Second),
+			},
+			wantQueueWait: 3 * time.Second,
+			wantExecution: 0,
+		},
+		{
+			name: "edited",
+			history: []Transition{
+				transition("", StatusPending, 0),
+				transition(StatusPending, StatusPending, time.Second),
+				transition(StatusPending, StatusRunning, 2*time.Second),
+				transition(StatusRunning, StatusRunning, 4*time.Second),
+				transition(StatusRunning, StatusCompleted, 7*time.Second),
+				transition(StatusCompleted, StatusCompleted, 9*time.Second),
+			},
+			wantQueueWait: 2 * time.Second,
+			wantExecution: 5 * time.Second,
+		},
+		{
+			name:          "without history",
+			wantQueueWait: 0,
+			wantExecution: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := Task{History: tt.history}
+			if got := task.QueueWait(); got != tt.wantQueueWait {
+				t.Errorf("QueueWait() = %v, want %v", got, tt.wantQueueWait)
+			}
+			if got := task.ExecutionTime(); got != tt.wantExecution {
+				t.Errorf("ExecutionTime() = %v, want %v", got, tt.wantExecution)
+			}
+		})
+	}
+}
+
+func TestRunningTaskDurationsGrow(t *testing.T) {
+	started := time.Now().Add(-time.Minute)
+	task := Task{History: []Transition{
+		{To: StatusPending, At: started.Add(-time.Second)},
+		{From: StatusPending, To: StatusRunning, At: started},
+	}}
+
+	if got := task.QueueWait(); got != time.Second {
+		t.Errorf("QueueWait() = %v, want 1s", got)
+	}
+	if got := task.ExecutionTime(); got < time.Minute {
+		t.Errorf("ExecutionTime() = %v, want at least a minute", got)
+	}
+}
+
+func TestCanTransitionTo(t *testing.T) {
+	statuses := []TaskStatus{StatusPending, StatusRunning, StatusCompleted, StatusFailed, StatusCanceled}
+	allowed := map[[2]TaskStatus]bool{
+		{StatusPending, StatusRunning}:   true,
+		{StatusPending, StatusCanceled}:  true,
+		{StatusPending, StatusFailed}:    true,
+		{StatusRunning, StatusCompleted}: true,
+		{StatusRunning, StatusFailed}:    true,
+		{StatusRunning, StatusCanceled}:  true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[[2]TaskStatus{from, to}]
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSlowConsumer is the reason a subscription is dropped when
+// its subscriber does not receive events as fast as they are published.
+var ErrSlowConsumer = errors.New("subscriber does not keep up with events")
+
+// Broker fans events out to subscriptions. It never waits
+// for subscribers: a subscription whose buffer is full when
+// an event arrives is dropped with [ErrSlowConsumer].
+type Broker struct {
+	mu            sync.Mutex
+	subscriptions map[*Subscription]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives the events accepted by its filter.
+type Subscription struct {
+	broker *Broker
+	filter func(Event) bool
+	events chan Event
+	// err is the reason the subscription was dropped,
+	// nil if it was closed by the subscriber. It is set
+	// before events is closed.
+	err error
+}
+
+// Subscribe returns a subscription to the events for which filter
+// returns true. Up to buffer events wait for the subscriber before
+// the subscription is dropped. The filter is called on the publishing
+// goroutine and must be fast and must not call the broker.
+func (b *Broker) Subscribe(buffer int, filter func(Event) bool) *Subscription {
+	s := &Subscription{
+		broker: b,
+		filter: filter,
+		events: make(chan Event, buffer),
+	}
+
+	b.mu.Lock()
+	b.subscriptions[s] = struct{}{}
+	b.mu.Unlock()
+
+	return s
+}
+
+// Publish delivers the event to the matching subscriptions
+// and drops the ones that cannot take it.
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscriptions {
+		if !s.filter(event) {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			s.err = ErrSlowConsumer
+			b.remove(s)
+		}
+	}
+}
+
+// remove closes the subscription. b.mu must be held.
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subscriptions[s]; !ok {
+		return
+	}
+	delete(b.subscriptions, s)
+	close(s.events)
+}
+
+// Events returns the channel of the events. It is closed
+// when the subscription is closed or dropped.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns the reason the subscription was dropped once
+// the events channel is closed, nil if it was closed with Close.
+func (s *Subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	return s.err
+}
+
+// Close stops the delivery of events. Closing a closed
+// or dropped subscription is a no-op.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.remove(s)
+}
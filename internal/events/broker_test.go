@@ -0,0 +1,80 @@
+package events
+
+import (
+	"errors"
+	"testing"
+)
+
+func forTask(uuid string) func(Event) bool {
+	return func(event Event) bool { return event.TaskUUID == uuid }
+}
+
+func TestBrokerDeliversMatchingEvents(t *testing.T) {
+	b := NewBroker()
+	sub := b.Subscribe(4, forTask("a"))
+	defer sub.Close()
+
+	b.Publish(Event{Type: TypeStatus, TaskUUID: "b"})
+	b.Publish(Event{Type: TypeProgress, TaskUUID: "a", Progress: 50})
+
+	select {
+	case event := <-sub.Events():
+		if event.TaskUUID != "a" || event.Progress != 50 {
+			t.Fatalf("got event %+v, want progress 50 of task a", event)
+		}
+	default:
+		t.Fatal("no event delivered")
+	}
+
+	select {
+	case event := <-sub.Events():
+		t.Fatalf("got unexpected event %+v", event)
+	default:
+	}
+}
+
+func TestBrokerDropsSlowConsumer(t *testing.T) {
+	b := NewBroker()
+	slow := b.Subscribe(2, forTask("a"))
+	fast := b.Subscribe(8, forTask("a"))
+	defer fast.Close()
+
+	for range 3 {
+		b.Publish(Event{Type: TypeLog, TaskUUID: "a"})
+	}
+
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	if received != 2 {
+		t.Errorf("slow subscriber received %d events before being dropped, want 2", received)
+	}
+	if !errors.Is(slow.Err(), ErrSlowConsumer) {
+		t.Errorf("got drop reason %v, want %v", slow.Err(), ErrSlowConsumer)
+	}
+
+	// Other subscribers are not affected.
+	if len(fast.Events()) != 3 {
+		t.Errorf("fast subscriber has %d events, want 3", len(fast.Events()))
+	}
+
+	// Closing a dropped subscription is a no-op.
+	slow.Close()
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	b := NewBroker()
+	sub := b.Subscribe(1, forTask("a"))
+	sub.Close()
+	sub.Close()
+
+	b.Publish(Event{Type: TypeStatus, TaskUUID: "a"})
+
+	if _, ok := <-sub.Events(); ok {
+		t.Error("closed subscription received an event")
+	}
+	if sub.Err() != nil {
+		t.Errorf("got reason %v for a closed subscription, want nil", sub.Err())
+	}
+}
@@ -0,0 +1,46 @@
+package events
+
+// Package events distributes events of task execution: status changes,
+// progress and log lines, to the subscribers interested in them.
+
+import (
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+)
+
+type Type string
+
+const (
+	// TypeStatus reports that a task changed its status.
+	TypeStatus Type = "status"
+	// TypeProgress reports how much of a running task is done.
+	TypeProgress Type = "progress"
+	// TypeLog carries a log line of a running task.
+	TypeLog Type = "log"
+)
+
+type Event struct {
+	Type      Type
+	Namespace string
+	TaskUUID  string
+	Time      time.Time
+
+	// Status is the new status of the task, set for status events.
+	Status domain.TaskStatus
+	// Version is the version of the task with the new status, set for
+	// status events. Status events of a task are published in the order
+	// of their versions, so a subscriber that also reads the task can drop
+	// the events with a version not above the one it has read.
+	Version uint64
+	// Progress is the done share of the task in percent, set for progress events.
+	Progress int
+	// Message is the text of log events.
+	Message string
+}
+
+// Publisher accepts events for delivery. Publish must not block,
+// since it is called on the paths executing tasks.
+type Publisher interface {
+	Publish(event Event)
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"github.com/passwordhash/task-manager-api/internal/worker/autoscaler"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type autoscalerCollector struct {
+	autoscaler *autoscaler.Autoscaler
+
+	minWorkers *prometheus.Desc
+	maxWorkers *prometheus.Desc
+	target     *prometheus.Desc
+	queueWait  *prometheus.Desc
+	scales     *prometheus.Desc
+}
+
+// NewAutoscalerCollector returns a collector that exports
+// the decisions of the autoscaler at scrape time.
+func NewAutoscalerCollector(a *autoscaler.Autoscaler) prometheus.Collector {
+	return &autoscalerCollector{
+		autoscaler: a,
+		minWorkers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "autoscaler", "min_workers"),
+			"Minimum number of workers the autoscaler keeps.",
+			nil, nil,
+		),
+		maxWorkers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "autoscaler", "max_workers"),
+			"Maximum number of workers the autoscaler allows.",
+			nil, nil,
+		),
+		target: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "autoscaler", "target_workers"),
+			"Number of workers the autoscaler last asked for.",
+			nil, nil,
+		),
+		queueWait: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "autoscaler", "observed_queue_wait_seconds"),
+			"Average queue wait observed during the last autoscaler interval.",
+			nil, nil,
+		),
+		scales: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "autoscaler", "decisions_total"),
+			"Number of pool resizes made by the autoscaler.",
+			[]string{"direction"}, nil,
+		),
+	}
+}
+
+func (c *autoscalerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.minWorkers
+	ch <- c.maxWorkers
+	ch <- c.target
+	ch <- c.queueWait
+	ch <- c.scales
+}
+
+func (c *autoscalerCollector) Collect(ch chan<- prometheus.Metric) {
+	state := c.autoscaler.State()
+
+	ch <- prometheus.MustNewConstMetric(c.minWorkers, prometheus.GaugeValue, float64(state.MinWorkers))
+	ch <- prometheus.MustNewConstMetric(c.maxWorkers, prometheus.GaugeValue, float64(state.MaxWorkers))
+	ch <- prometheus.MustNewConstMetric(c.target, prometheus.GaugeValue, float64(state.Target))
+	ch <- prometheus.MustNewConstMetric(c.queueWait, prometheus.GaugeValue, state.QueueWait.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.scales, prometheus.CounterValue, float64(state.ScaleUps), "up")
+	ch <- prometheus.MustNewConstMetric(c.scales, prometheus.CounterValue, float64(state.ScaleDowns), "down")
+}
@@ -0,0 +1,153 @@
+package metrics
+
+// Package metrics contains Prometheus collectors of the application.
+
+import (
+	"github.com/passwordhash/task-manager-api/internal/worker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "task_manager"
+
+type poolCollector struct {
+	pools worker.PoolGroups
+
+	paused  *prometheus.Desc
+	workers *prometheus.Desc
+	busy    *prometheus.Desc
+	queued  *prometheus.Desc
+	resizes *prometheus.Desc
+
+	dequeued  *prometheus.Desc
+	queueWait *prometheus.Desc
+
+	typeLimit   *prometheus.Desc
+	typeRunning *prometheus.Desc
+
+	tenantQueued  *prometheus.Desc
+	tenantRunning *prometheus.Desc
+
+	namespaceRunning *prometheus.Desc
+}
+
+// NewPoolCollector returns a collector that exports the state
+// of every worker group at scrape time.
+func NewPoolCollector(pools worker.PoolGroups) prometheus.Collector {
+	groupLabels := []string{"group"}
+	typeLabels := []string{"group", "type"}
+	tenantLabels := []string{"group", "tenant"}
+	namespaceLabels := []string{"group", "namespace"}
+
+	return &poolCollector{
+		pools: pools,
+		paused: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "paused"),
+			"Whether the worker pool is paused (1) or running (0).",
+			groupLabels, nil,
+		),
+		workers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "workers"),
+			"Number of workers in the pool.",
+			groupLabels, nil,
+		),
+		busy: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "busy_workers"),
+			"Number of workers executing a task.",
+			groupLabels, nil,
+		),
+		queued: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "queue_length"),
+			"Number of tasks waiting in the queue.",
+			groupLabels, nil,
+		),
+		resizes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "resizes_total"),
+			"Number of times the worker pool was resized.",
+			groupLabels, nil,
+		),
+		dequeued: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "dequeued_total"),
+			"Number of tasks taken from the queue by workers.",
+			groupLabels, nil,
+		),
+		queueWait: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "queue_wait_seconds_total"),
+			"Total time tasks spent waiting in the queue.",
+			groupLabels, nil,
+		),
+		typeLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "type_concurrency_limit"),
+			"Maximum number of concurrently running tasks of the type.",
+			typeLabels, nil,
+		),
+		typeRunning: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "type_running_tasks"),
+			"Number of running tasks of the type.",
+			typeLabels, nil,
+		),
+		tenantQueued: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "tenant_queue_length"),
+			"Number of tasks of the tenant waiting in the queue.",
+			tenantLabels, nil,
+		),
+		tenantRunning: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "tenant_running_tasks"),
+			"Number of running tasks of the tenant.",
+			tenantLabels, nil,
+		),
+		namespaceRunning: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pool", "namespace_running_tasks"),
+			"Number of running tasks of the namespace.",
+			namespaceLabels, nil,
+		),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.paused
+	ch <- c.workers
+	ch <- c.busy
+	ch <- c.queued
+	ch <- c.resizes
+	ch <- c.dequeued
+	ch <- c.queueWait
+	ch <- c.typeLimit
+	ch <- c.typeRunning
+	ch <- c.tenantQueued
+	ch <- c.tenantRunning
+	ch <- c.namespaceRunning
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, group := range c.pools.Names() {
+		groupPool, _ := c.pools.Get(group)
+		state := groupPool.State()
+
+		var paused float64
+		if state.Paused {
+			paused = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.paused, prometheus.GaugeValue, paused, group)
+		ch <- prometheus.MustNewConstMetric(c.workers, prometheus.GaugeValue, float64(state.Workers), group)
+		ch <- prometheus.MustNewConstMetric(c.busy, prometheus.GaugeValue, float64(state.Busy), group)
+		ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(state.Queued), group)
+		ch <- prometheus.MustNewConstMetric(c.resizes, prometheus.CounterValue, float64(state.Resizes), group)
+		ch <- prometheus.MustNewConstMetric(c.dequeued, prometheus.CounterValue, float64(state.Dequeued), group)
+		ch <- prometheus.MustNewConstMetric(c.queueWait, prometheus.CounterValue, state.QueueWaitTotal.Seconds(), group)
+
+		for taskType, limit := range state.TypeLimits {
+			ch <- prometheus.MustNewConstMetric(c.typeLimit, prometheus.GaugeValue, float64(limit), group, taskType)
+			ch <- prometheus.MustNewConstMetric(c.typeRunning, prometheus.GaugeValue, float64(state.RunningByType[taskType]), group, taskType)
+		}
+
+		for tenant, queued := range state.QueuedByTenant {
+			ch <- prometheus.MustNewConstMetric(c.tenantQueued, prometheus.GaugeValue, float64(queued), group, tenant)
+			ch <- prometheus.MustNewConstMetric(c.tenantRunning, prometheus.GaugeValue, float64(state.RunningByTenant[tenant]), group, tenant)
+		}
+
+		for ns, running := range state.RunningByNamespace {
+			ch <- prometheus.MustNewConstMetric(c.namespaceRunning, prometheus.GaugeValue, float64(running), group, ns)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package requestid
+
+// Package requestid carries the ID of the request being served in a context,
+// so logs of every layer handling the request can be correlated.
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// maxLength limits the length of request IDs accepted from clients.
+const maxLength = 128
+
+type key struct{}
+
+// New returns a new random request ID.
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether id can be used as a request ID:
+// it is not empty, not too long and consists of printable ASCII.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// WithContext returns a copy of ctx carrying the request ID.
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, key{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, empty if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(key{}).(string)
+	return id
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/passwordhash/task-manager-api/internal/domain"
 )
@@ -14,29 +15,151 @@ var (
 	// ErrAlreadyExist is returned when a task with the same UUID already exists.
 	ErrAlreadyExist = errors.New("entity already exists")
 
-	// ErrCantCancel is returned when a task cannot be canceled, for example,
-	// if it is already completed or canceled.
+	// ErrCantCancel is returned when a task cannot be canceled
+	// because it is already in a terminal status.
 	ErrCantCancel = errors.New("task cannot be canceled")
 
 	ErrCantSubmit = errors.New("task cannot be submitted to worker pool")
+
+	// ErrConflict is returned when a task changed its status concurrently
+	// and the requested change no longer applies to it.
+	ErrConflict = errors.New("task status conflict")
+
+	// ErrVersionMismatch is returned when a change is conditional on
+	// a version of the task, but the task has changed since.
+	ErrVersionMismatch = errors.New("task version mismatch")
+
+	// ErrTenantLimit is returned when a task is rejected because its tenant
+	// already has the maximum number of queued or running tasks.
+	ErrTenantLimit = errors.New("tenant task limit exceeded")
+
+	// ErrCantRetry is returned when a task cannot be re-run because
+	// it has not reached a terminal status yet.
+	ErrCantRetry = errors.New("task cannot be retried")
+
+	// ErrCantUpdate is returned when the priority or the start time
+	// of a task is changed after it has left the queue.
+	ErrCantUpdate = errors.New("task cannot be updated")
+
+	// ErrTooManyLabels is returned when a task would get more
+	// than [domain.MaxLabels] labels.
+	ErrTooManyLabels = errors.New("too many labels")
+
+	// ErrCantDelete is returned when a task cannot be deleted because
+	// it has not reached a terminal status yet.
+	ErrCantDelete = errors.New("task cannot be deleted")
+
+	// ErrNamespaceQuota is returned when a task is rejected because its
+	// namespace already stores the maximum number of tasks.
+	ErrNamespaceQuota = errors.New("namespace task quota exceeded")
+
+	// ErrNamespaceAccess is returned when the principal from ctx
+	// may not access the namespace.
+	ErrNamespaceAccess = errors.New("namespace access denied")
 )
 
+// CreateTaskParams describes a task to create.
+type CreateTaskParams struct {
+	// Namespace stores the task, [domain.DefaultNamespace] if empty.
+	Namespace string
+	// Type routes the task to its worker group, [domain.DefaultTaskType] if empty.
+	Type    string
+	Payload any
+	// Tenant owns the task and gets its fair share of workers,
+	// [domain.DefaultTenant] if empty.
+	Tenant string
+	// Owner is the name of the API client creating the task.
+	Owner string
+	// Priority orders the task in the queue of its tenant.
+	Priority int
+	// Labels group tasks, they are selected by [domain.Selector].
+	Labels      map[string]string
+	Description string
+}
+
+// UpdateTaskParams describes changes of a task, nil fields are kept.
+type UpdateTaskParams struct {
+	// Labels are merged into the labels of the task,
+	// a nil value removes the label.
+	Labels map[string]*string
+	// ClearLabels removes all labels of the task before Labels are merged.
+	ClearLabels bool
+	Description *string
+	// Priority can only be changed while the task is pending.
+	Priority *int
+	// RunAt holds the task in the queue until then, a zero RunAt
+	// lets it run right away. It can only be changed while the task is pending.
+	RunAt *time.Time
+}
+
 // TaskService defines the interface for task-related operations.
+//
+// Every operation is scoped by a namespace: tasks of other namespaces
+// are reported as not existing. [ErrNamespaceAccess] is returned if
+// the principal from ctx may not access the namespace.
+//
+// If ctx carries an auth.Principal without the admin scope,
+// only tasks owned by it are visible: other tasks are reported
+// as not existing.
 type TaskService interface {
 	// CreateTask creates a new task with status [domain.StatusPending] and returns its UUID.
-	// If task cannot be submitted to the worker pool, it returns [ErrCantSubmit].
-	CreateTask(ctx context.Context) (uuid string, err error)
+	// If task cannot be submitted to the worker pool, it returns [ErrCantSubmit],
+	// or [ErrTenantLimit] if the tenant has too many queued or running tasks.
+	// If the namespace stores too many tasks, it returns [ErrNamespaceQuota].
+	CreateTask(ctx context.Context, params CreateTaskParams) (uuid string, err error)
 
 	// Get retrieves a task by its UUID.
 	// Returns [ErrNotFound] if the task does not exist.
-	Get(ctx context.Context, uuid string) (task domain.Task, err error)
+	Get(ctx context.Context, namespace string, uuid string) (task domain.Task, err error)
 
-	// GetAll retrieves all tasks from the storage.
-	GetAll(ctx context.Context) (tasks []domain.Task, err error)
+	// GetAll retrieves the tasks of the namespace matching the selector
+	// ordered by [domain.CompareCreation]. An empty selector matches every task.
+	GetAll(ctx context.Context, namespace string, selector domain.Selector) (tasks []domain.Task, err error)
 
-	// Cancel cancels a task with the specified UUID.
+	// Cancel cancels a pending or running task with the specified UUID.
+	// If version is not zero, the task is canceled only if it still has
+	// this version, see [domain.Task.Version].
 	// Return [ErrNotFound] if the task does not exist,
-	// [ErrCantCancel] if the task cannot be canceled
+	// [ErrVersionMismatch] if the task has another version,
+	// [ErrCantCancel] if the task is in a terminal status
 	// or some internal error.
-	Cancel(ctx context.Context, uuid string) error
+	Cancel(ctx context.Context, namespace string, uuid string, version uint64) error
+
+	// CancelAll cancels the pending and running tasks of the namespace
+	// matching the selector and returns the UUIDs of the canceled tasks.
+	// Tasks that finish meanwhile are skipped. On an error the tasks
+	// canceled before it are returned with it.
+	CancelAll(ctx context.Context, namespace string, selector domain.Selector) (uuids []string, err error)
+
+	// Update changes the metadata of a task with the specified UUID,
+	// records the edit in its history and returns the updated task.
+	// If version is not zero, the task is updated only if it still has this version.
+	// A new priority or start time also reorders the queue of the worker pool.
+	// Returns [ErrNotFound] if the task does not exist,
+	// [ErrVersionMismatch] if the task has another version,
+	// [ErrTooManyLabels] if the task would get too many labels
+	// or [ErrCantUpdate] if the priority or start time of a task that is not pending is changed.
+	Update(ctx context.Context, namespace string, uuid string, params UpdateTaskParams, version uint64) (task domain.Task, err error)
+
+	// Retry creates a new attempt of a terminal task with the specified UUID
+	// with the same type and payload, links it to the original one
+	// and submits it to the worker pool.
+	// Returns [ErrNotFound] if the task does not exist,
+	// [ErrCantRetry] if the task is not in a terminal status,
+	// [ErrNamespaceQuota] if the namespace stores too many tasks
+	// or [ErrCantSubmit]/[ErrTenantLimit] if the new attempt cannot be submitted.
+	Retry(ctx context.Context, namespace string, uuid string) (retryUUID string, err error)
+
+	// Delete removes a terminal task with the specified UUID.
+	// If version is not zero, the task is deleted only if it still has this version.
+	// Returns [ErrNotFound] if the task does not exist,
+	// [ErrVersionMismatch] if the task has another version
+	// or [ErrCantDelete] if the task is not in a terminal status.
+	Delete(ctx context.Context, namespace string, uuid string, version uint64) error
+
+	// DeleteAll removes the terminal tasks of the namespace matching
+	// the selector and returns the UUIDs of the removed tasks.
+	// Tasks that are not in a terminal status are skipped. On an error
+	// the tasks removed before it are returned with it.
+	DeleteAll(ctx context.Context, namespace string, selector domain.Selector) (uuids []string, err error)
 }
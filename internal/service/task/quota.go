@@ -0,0 +1,16 @@
+package task
+
+// NamespaceQuotas limits the number of tasks stored per namespace.
+// Zero means no limit.
+type NamespaceQuotas struct {
+	MaxTasks  int
+	Overrides map[string]int
+}
+
+// For returns the maximum number of tasks stored in the namespace.
+func (q NamespaceQuotas) For(namespace string) int {
+	if limit, ok := q.Overrides[namespace]; ok && limit > 0 {
+		return limit
+	}
+	return q.MaxTasks
+}
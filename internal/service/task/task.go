@@ -5,67 +5,191 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/passwordhash/task-manager-api/internal/auth"
 	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/requestid"
 	"github.com/passwordhash/task-manager-api/internal/service"
 	"github.com/passwordhash/task-manager-api/internal/storage"
+	"github.com/passwordhash/task-manager-api/internal/tracing"
 	"github.com/passwordhash/task-manager-api/internal/worker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/passwordhash/task-manager-api/internal/service/task")
+
 type simulatedTaskService struct {
-	log        *slog.Logger
-	workerPool worker.TaskPool
-	storage    storage.Task
+	log     *slog.Logger
+	pools   worker.PoolGroups
+	storage storage.Task
+	quotas  NamespaceQuotas
+
+	// saveMu serializes checking the namespace quota and saving
+	// a task, so concurrent creates can't exceed the quota.
+	saveMu sync.Mutex
+	// queueMu serializes storing a priority or start time and moving
+	// the task in the queue, so the queue follows the last stored ones.
+	queueMu sync.Mutex
 }
 
 func NewSimulatedTaskService(
 	log *slog.Logger,
-	workerPool worker.TaskPool,
+	pools worker.PoolGroups,
 	storage storage.Task,
+	quotas NamespaceQuotas,
 ) service.TaskService {
 	return &simulatedTaskService{
-		log:        log,
-		workerPool: workerPool,
-		storage:    storage,
+		log:     log,
+		pools:   pools,
+		storage: storage,
+		quotas:  quotas,
 	}
 }
 
-func (m *simulatedTaskService) CreateTask(ctx context.Context) (string, error) {
+func (m *simulatedTaskService) CreateTask(ctx context.Context, params service.CreateTaskParams) (taskUUID string, err error) {
 	const op = "task.CreateTask"
 
-	log := m.log.With(slog.String("op", op))
+	ctx, span := tracer.Start(ctx, "TaskService.CreateTask")
+	defer func() { tracing.End(span, err) }()
+
+	namespace := params.Namespace
+	if namespace == "" {
+		namespace = domain.DefaultNamespace
+	}
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.String("namespace", namespace),
+		slog.String("request_id", requestid.FromContext(ctx)),
+	)
 
+	if err := authorize(ctx, op, namespace); err != nil {
+		return "", err
+	}
+
+	taskType := params.Type
+	if taskType == "" {
+		taskType = domain.DefaultTaskType
+	}
+	tenant := params.Tenant
+	if tenant == "" {
+		tenant = domain.DefaultTenant
+	}
+
+	now := time.Now()
 	task := domain.Task{
-		UUID:      uuid.NewString(),
-		CreatedAt: time.Now(),
-		Status:    domain.StatusPending,
+		UUID:        uuid.NewString(),
+		Namespace:   namespace,
+		Type:        taskType,
+		Payload:     params.Payload,
+		Tenant:      tenant,
+		Owner:       params.Owner,
+		Priority:    params.Priority,
+		Labels:      maps.Clone(params.Labels),
+		Description: params.Description,
+		CreatedAt:   now,
+		Status:      domain.StatusPending,
+		RequestID:   requestid.FromContext(ctx),
+		History: []domain.Transition{{
+			To:     domain.StatusPending,
+			At:     now,
+			Actor:  actor(ctx),
+			Reason: "created",
+		}},
 	}
+	span.SetAttributes(taskAttributes(task)...)
 
-	err := m.storage.Save(ctx, task)
+	if err := m.saveAndSubmit(ctx, log, op, &task); err != nil {
+		return "", err
+	}
+
+	log.Info("Task created and saved", "task", task)
+
+	return task.UUID, nil
+}
+
+func (m *simulatedTaskService) Retry(ctx context.Context, namespace string, taskUUID string) (retryUUID string, err error) {
+	const op = "task.Retry"
+
+	ctx, span := tracer.Start(ctx, "TaskService.Retry", trace.WithAttributes(
+		attribute.String("task.namespace", namespace),
+		attribute.String("task.retry_of", taskUUID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.String("namespace", namespace),
+		slog.String("task_uuid", taskUUID),
+		slog.String("request_id", requestid.FromContext(ctx)),
+	)
+
+	original, err := m.get(ctx, log, op, namespace, taskUUID)
 	if err != nil {
-		return "", m.handleStorageError(log, op, err)
+		return "", err
 	}
 
-	if err := m.workerPool.Submit(ctx, &task); err != nil {
-		log.Error("Failed to submit task to worker pool", slog.Any("error", err))
-		return "", fmt.Errorf("%s: %w", op, service.ErrCantSubmit)
+	if !original.Status.IsTerminal() {
+		log.Warn("Task is not in a terminal status", slog.Any("task_status", original.Status))
+		return "", fmt.Errorf("%s: %w", op, service.ErrCantRetry)
 	}
 
-	log.Info("Task created and saved", "task", task)
+	now := time.Now()
+	task := domain.Task{
+		UUID:        uuid.NewString(),
+		Namespace:   original.Namespace,
+		Type:        original.Type,
+		Payload:     original.Payload,
+		Tenant:      original.Tenant,
+		Owner:       original.Owner,
+		Labels:      maps.Clone(original.Labels),
+		Description: original.Description,
+		Priority:    original.Priority,
+		CreatedAt:   now,
+		Status:      domain.StatusPending,
+		RequestID:   requestid.FromContext(ctx),
+		RetryOf:     original.UUID,
+		History: []domain.Transition{{
+			To:     domain.StatusPending,
+			At:     now,
+			Actor:  actor(ctx),
+			Reason: "retry of " + original.UUID,
+		}},
+	}
+	span.SetAttributes(taskAttributes(task)...)
+
+	if err := m.saveAndSubmit(ctx, log, op, &task); err != nil {
+		return "", err
+	}
+
+	log.Info("Task retry created and saved", "task", task)
 
 	return task.UUID, nil
 }
 
-func (m *simulatedTaskService) Get(ctx context.Context, uuid string) (task domain.Task, err error) {
+func (m *simulatedTaskService) Get(ctx context.Context, namespace string, uuid string) (task domain.Task, err error) {
 	const op = "MockTaskService.Get"
 
-	log := m.log.With(slog.String("op", op), slog.String("task_uuid", uuid))
+	ctx, span := tracer.Start(ctx, "TaskService.Get", trace.WithAttributes(
+		attribute.String("task.namespace", namespace),
+		attribute.String("task.uuid", uuid),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	log := m.log.With(slog.String("op", op), slog.String("namespace", namespace), slog.String("task_uuid", uuid))
 
-	task, err = m.storage.Get(ctx, uuid)
+	task, err = m.get(ctx, log, op, namespace, uuid)
 	if err != nil {
-		return domain.Task{}, m.handleStorageError(log, op, err)
+		return domain.Task{}, err
 	}
 
 	log.Info("Retrieved task", slog.Any("task", task))
@@ -73,39 +197,90 @@ func (m *simulatedTaskService) Get(ctx context.Context, uuid string) (task domai
 	return task, nil
 }
 
-func (m *simulatedTaskService) GetAll(ctx context.Context) (tasks []domain.Task, err error) {
+func (m *simulatedTaskService) GetAll(ctx context.Context, namespace string, selector domain.Selector) (tasks []domain.Task, err error) {
 	const op = "MockTaskService.GetAll"
 
-	log := m.log.With(slog.String("op", op))
+	ctx, span := tracer.Start(ctx, "TaskService.GetAll", trace.WithAttributes(
+		attribute.String("task.namespace", namespace),
+		attribute.String("task.selector", selector.String()),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	log := m.log.With(slog.String("op", op), slog.String("namespace", namespace), slog.String("selector", selector.String()))
+
+	if err := authorize(ctx, op, namespace); err != nil {
+		return nil, err
+	}
 
-	tasks, err = m.storage.GetAll(ctx)
+	tasks, err = m.storage.GetAll(ctx, namespace, selector)
 	if err != nil {
 		return nil, m.handleStorageError(log, op, err)
 	}
 
+	tasks = slices.DeleteFunc(tasks, func(task domain.Task) bool {
+		return !visible(ctx, task)
+	})
+	slices.SortFunc(tasks, domain.CompareCreation)
+
 	log.Info("Retrieved all tasks", slog.Int("count", len(tasks)))
 
 	return tasks, nil
 }
 
-func (m *simulatedTaskService) Cancel(ctx context.Context, uuid string) error {
+func (m *simulatedTaskService) Cancel(ctx context.Context, namespace string, uuid string, version uint64) (err error) {
 	const op = "MockTaskService.Cancel"
 
-	log := m.log.With(slog.String("op", op), slog.String("task_uuid", uuid))
+	ctx, span := tracer.Start(ctx, "TaskService.Cancel", trace.WithAttributes(
+		attribute.String("task.namespace", namespace),
+		attribute.String("task.uuid", uuid),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	log := m.log.With(slog.String("op", op), slog.String("namespace", namespace), slog.String("task_uuid", uuid))
 
-	task, err := m.storage.Get(ctx, uuid)
+	task, err := m.get(ctx, log, op, namespace, uuid)
 	if err != nil {
-		return m.handleStorageError(log, op, err)
+		return err
 	}
 
-	if task.Status == domain.StatusCompleted || task.Status == domain.StatusCanceled {
-		log.Warn("Task is already completed or canceled", slog.Any("task_status", task.Status))
-		return fmt.Errorf("%s: %w", op, service.ErrCantCancel)
+	// The status is recorded first: once the task is canceled, the worker
+	// can't store another outcome. A task that moves on meanwhile is
+	// checked again, statuses only move forward so this ends.
+	for {
+		if version != 0 && task.Version != version {
+			log.Warn("Task version mismatch", slog.Uint64("version", task.Version), slog.Uint64("expected", version))
+			return fmt.Errorf("%s: %w", op, service.ErrVersionMismatch)
+		}
+		if !task.Status.CanTransitionTo(domain.StatusCanceled) {
+			log.Warn("Task can't be canceled", slog.Any("task_status", task.Status))
+			return fmt.Errorf("%s: %w", op, service.ErrCantCancel)
+		}
+
+		err = m.storage.Update(ctx, namespace, uuid, storage.TaskUpdate{
+			Status:          domain.StatusCanceled,
+			ExpectedStatus:  task.Status,
+			ExpectedVersion: version,
+			UpdatedAt:       time.Now(),
+			Error:           context.Canceled,
+			Actor:           actor(ctx),
+			Reason:          "canceled on request",
+		})
+		if !errors.Is(err, storage.ErrConflict) {
+			break
+		}
+
+		log.Debug("Task status changed concurrently", slog.Any("error", err))
+		if task, err = m.get(ctx, log, op, namespace, uuid); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return m.handleStorageError(log, op, err)
 	}
 
-	if err := m.workerPool.Cancel(ctx, uuid); err != nil {
-		log.Error("Failed to cancel task", slog.Any("error", err))
-		return fmt.Errorf("%s: failed to cancel task: %v", op, err)
+	if err := m.pools.ForType(task.Type).Cancel(ctx, uuid); err != nil {
+		// The task is canceled already, it only keeps running until it finishes.
+		log.Warn("Failed to stop canceled task", slog.Any("error", err))
 	}
 
 	log.Info("Task canceled successfully")
@@ -113,8 +288,363 @@ func (m *simulatedTaskService) Cancel(ctx context.Context, uuid string) error {
 	return nil
 }
 
+func (m *simulatedTaskService) CancelAll(ctx context.Context, namespace string, selector domain.Selector) (uuids []string, err error) {
+	const op = "task.CancelAll"
+
+	ctx, span := tracer.Start(ctx, "TaskService.CancelAll", trace.WithAttributes(
+		attribute.String("task.namespace", namespace),
+		attribute.String("task.selector", selector.String()),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	tasks, err := m.GetAll(ctx, namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, task := range tasks {
+		if task.Status.IsTerminal() {
+			continue
+		}
+
+		err := m.Cancel(ctx, namespace, task.UUID, 0)
+		if errors.Is(err, service.ErrCantCancel) || errors.Is(err, service.ErrNotFound) {
+			// The task finished or was deleted since it was listed.
+			continue
+		}
+		if err != nil {
+			return uuids, fmt.Errorf("%s: %w", op, err)
+		}
+		uuids = append(uuids, task.UUID)
+	}
+
+	m.log.Info("Tasks canceled by selector",
+		slog.String("op", op),
+		slog.String("namespace", namespace),
+		slog.String("selector", selector.String()),
+		slog.Int("count", len(uuids)),
+	)
+
+	return uuids, nil
+}
+
+func (m *simulatedTaskService) Update(
+	ctx context.Context,
+	namespace string,
+	uuid string,
+	params service.UpdateTaskParams,
+	version uint64,
+) (task domain.Task, err error) {
+	const op = "task.Update"
+
+	ctx, span := tracer.Start(ctx, "TaskService.Update", trace.WithAttributes(
+		attribute.String("task.namespace", namespace),
+		attribute.String("task.uuid", uuid),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	log := m.log.With(slog.String("op", op), slog.String("namespace", namespace), slog.String("task_uuid", uuid))
+
+	task, err = m.get(ctx, log, op, namespace, uuid)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if version != 0 && task.Version != version {
+		log.Warn("Task version mismatch", slog.Uint64("version", task.Version), slog.Uint64("expected", version))
+		return domain.Task{}, fmt.Errorf("%s: %w", op, service.ErrVersionMismatch)
+	}
+
+	reason := editReason(params)
+	if reason == "" {
+		return task, nil
+	}
+
+	update := storage.TaskUpdate{
+		Labels:          params.Labels,
+		ClearLabels:     params.ClearLabels,
+		Description:     params.Description,
+		Priority:        params.Priority,
+		RunAt:           params.RunAt,
+		ExpectedVersion: version,
+		UpdatedAt:       time.Now(),
+		Actor:           actor(ctx),
+		Reason:          reason,
+	}
+	requeue := params.Priority != nil || params.RunAt != nil
+	if requeue {
+		// Only the place in the queue depends on the priority and the start time.
+		if task.Status != domain.StatusPending {
+			log.Warn("Task priority or start time can't be changed", slog.Any("task_status", task.Status))
+			return domain.Task{}, fmt.Errorf("%s: %w", op, service.ErrCantUpdate)
+		}
+		update.ExpectedStatus = domain.StatusPending
+	}
+
+	labels := maps.Clone(task.Labels)
+	if labels == nil || params.ClearLabels {
+		labels = make(map[string]string)
+	}
+	for key, value := range params.Labels {
+		if value == nil {
+			delete(labels, key)
+		} else {
+			labels[key] = *value
+		}
+	}
+	if len(labels) > domain.MaxLabels {
+		log.Warn("Task would get too many labels", slog.Int("labels", len(labels)))
+		return domain.Task{}, fmt.Errorf("%s: %w", op, service.ErrTooManyLabels)
+	}
+
+	if requeue {
+		m.queueMu.Lock()
+		defer m.queueMu.Unlock()
+	}
+
+	err = m.storage.Update(ctx, namespace, uuid, update)
+	if errors.Is(err, storage.ErrConflict) {
+		log.Warn("Task left the queue before its priority or start time was changed", slog.Any("error", err))
+		return domain.Task{}, fmt.Errorf("%s: %w", op, service.ErrCantUpdate)
+	}
+	if err != nil {
+		return domain.Task{}, m.handleStorageError(log, op, err)
+	}
+
+	// A task taken by a worker meanwhile is not in the queue anymore,
+	// its priority and start time do not matter then.
+	if params.Priority != nil {
+		m.pools.ForType(task.Type).SetPriority(uuid, *params.Priority)
+	}
+	if params.RunAt != nil {
+		m.pools.ForType(task.Type).SetRunAt(uuid, *params.RunAt)
+	}
+
+	log.Info("Task updated", slog.String("reason", reason))
+
+	task, err = m.storage.Get(ctx, namespace, uuid)
+	if err != nil {
+		return domain.Task{}, m.handleStorageError(log, op, err)
+	}
+	return task, nil
+}
+
+func (m *simulatedTaskService) Delete(ctx context.Context, namespace string, uuid string, version uint64) (err error) {
+	const op = "task.Delete"
+
+	ctx, span := tracer.Start(ctx, "TaskService.Delete", trace.WithAttributes(
+		attribute.String("task.namespace", namespace),
+		attribute.String("task.uuid", uuid),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	log := m.log.With(slog.String("op", op), slog.String("namespace", namespace), slog.String("task_uuid", uuid))
+
+	task, err := m.get(ctx, log, op, namespace, uuid)
+	if err != nil {
+		return err
+	}
+
+	if version != 0 && task.Version != version {
+		log.Warn("Task version mismatch", slog.Uint64("version", task.Version), slog.Uint64("expected", version))
+		return fmt.Errorf("%s: %w", op, service.ErrVersionMismatch)
+	}
+	if !task.Status.IsTerminal() {
+		log.Warn("Task is not in a terminal status", slog.Any("task_status", task.Status))
+		return fmt.Errorf("%s: %w", op, service.ErrCantDelete)
+	}
+
+	if err := m.storage.Delete(ctx, namespace, uuid, version); err != nil {
+		return m.handleStorageError(log, op, err)
+	}
+
+	log.Info("Task deleted")
+
+	return nil
+}
+
+func (m *simulatedTaskService) DeleteAll(ctx context.Context, namespace string, selector domain.Selector) (uuids []string, err error) {
+	const op = "task.DeleteAll"
+
+	ctx, span := tracer.Start(ctx, "TaskService.DeleteAll", trace.WithAttributes(
+		attribute.String("task.namespace", namespace),
+		attribute.String("task.selector", selector.String()),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	tasks, err := m.GetAll(ctx, namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, task := range tasks {
+		if !task.Status.IsTerminal() {
+			continue
+		}
+
+		err := m.Delete(ctx, namespace, task.UUID, 0)
+		if errors.Is(err, service.ErrNotFound) {
+			// The task was deleted since it was listed.
+			continue
+		}
+		if err != nil {
+			return uuids, fmt.Errorf("%s: %w", op, err)
+		}
+		uuids = append(uuids, task.UUID)
+	}
+
+	m.log.Info("Tasks deleted by selector",
+		slog.String("op", op),
+		slog.String("namespace", namespace),
+		slog.String("selector", selector.String()),
+		slog.Int("count", len(uuids)),
+	)
+
+	return uuids, nil
+}
+
+// get retrieves a task of the namespace visible to the principal from ctx.
+// Tasks of other owners are reported as [service.ErrNotFound].
+func (m *simulatedTaskService) get(ctx context.Context, log *slog.Logger, op string, namespace string, uuid string) (domain.Task, error) {
+	if err := authorize(ctx, op, namespace); err != nil {
+		return domain.Task{}, err
+	}
+
+	task, err := m.storage.Get(ctx, namespace, uuid)
+	if err != nil {
+		return domain.Task{}, m.handleStorageError(log, op, err)
+	}
+
+	if !visible(ctx, task) {
+		log.Warn("Task belongs to another owner", slog.String("owner", task.Owner))
+		return domain.Task{}, fmt.Errorf("%s: task not found: %w", op, service.ErrNotFound)
+	}
+
+	return task, nil
+}
+
+// editReason describes the changes of a task for its history,
+// empty if nothing is changed.
+func editReason(params service.UpdateTaskParams) string {
+	var changes []string
+	if params.ClearLabels {
+		changes = append(changes, "labels removed")
+	}
+	for _, key := range slices.Sorted(maps.Keys(params.Labels)) {
+		if value := params.Labels[key]; value != nil {
+			changes = append(changes, fmt.Sprintf("label %s set to %q", key, *value))
+		} else {
+			changes = append(changes, "label "+key+" removed")
+		}
+	}
+	if params.Description != nil {
+		if *params.Description == "" {
+			changes = append(changes, "description removed")
+		} else {
+			changes = append(changes, "description changed")
+		}
+	}
+	if params.Priority != nil {
+		changes = append(changes, "priority set to "+strconv.Itoa(*params.Priority))
+	}
+	if params.RunAt != nil {
+		if params.RunAt.IsZero() {
+			changes = append(changes, "start time removed")
+		} else {
+			changes = append(changes, "start time set to "+params.RunAt.UTC().Format(time.RFC3339))
+		}
+	}
+	return strings.Join(changes, ", ")
+}
+
+// taskAttributes describe a task on spans.
+func taskAttributes(task domain.Task) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("task.uuid", task.UUID),
+		attribute.String("task.namespace", task.Namespace),
+		attribute.String("task.type", task.Type),
+		attribute.String("task.tenant", task.Tenant),
+	}
+}
+
+// authorize checks that the principal from ctx may access the namespace.
+func authorize(ctx context.Context, op string, namespace string) error {
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanAccess(namespace) {
+		return fmt.Errorf("%s: %s: %w", op, namespace, service.ErrNamespaceAccess)
+	}
+	return nil
+}
+
+// visible reports whether the principal from ctx may see the task.
+// Without a principal authentication is disabled and every task is visible.
+func visible(ctx context.Context, task domain.Task) bool {
+	principal, ok := auth.FromContext(ctx)
+	return !ok || principal.IsAdmin() || principal.Name == task.Owner
+}
+
+// actor returns the name of the principal from ctx for the history of tasks.
+func actor(ctx context.Context) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return principal.Name
+	}
+	return domain.ActorAnonymous
+}
+
+// saveAndSubmit persists a new task within the quota of its namespace
+// and submits it to the worker pool of the group its type is routed to.
+func (m *simulatedTaskService) saveAndSubmit(ctx context.Context, log *slog.Logger, op string, task *domain.Task) error {
+	// The task runs later on a worker, its execution is traced
+	// as a continuation of the current span.
+	task.TraceContext = tracing.Inject(ctx)
+
+	if err := m.save(ctx, log, op, task); err != nil {
+		return err
+	}
+
+	if err := m.pools.ForType(task.Type).Submit(ctx, task); err != nil {
+		log.Error("Failed to submit task to worker pool", slog.Any("error", err))
+
+		// The task will never run and the client is told it wasn't
+		// created, so it must not count towards the namespace quota
+		// or show up in the list.
+		if deleteErr := m.storage.Delete(ctx, task.Namespace, task.UUID, 0); deleteErr != nil {
+			log.Error("Failed to delete rejected task", slog.Any("error", deleteErr))
+		}
+
+		if errors.Is(err, worker.ErrTenantQueueFull) || errors.Is(err, worker.ErrTenantRunningFull) {
+			return fmt.Errorf("%s: %w", op, service.ErrTenantLimit)
+		}
+		return fmt.Errorf("%s: %w", op, service.ErrCantSubmit)
+	}
+
+	return nil
+}
+
+func (m *simulatedTaskService) save(ctx context.Context, log *slog.Logger, op string, task *domain.Task) error {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	if quota := m.quotas.For(task.Namespace); quota > 0 {
+		count, err := m.storage.Count(ctx, task.Namespace)
+		if err != nil {
+			return m.handleStorageError(log, op, err)
+		}
+		if count >= quota {
+			log.Warn("Namespace task quota exceeded", slog.Int("quota", quota))
+			return fmt.Errorf("%s: %w", op, service.ErrNamespaceQuota)
+		}
+	}
+
+	if err := m.storage.Save(ctx, *task); err != nil {
+		return m.handleStorageError(log, op, err)
+	}
+
+	return nil
+}
+
 // handleStorageError processes storage errors and returns a formatted error message.
-// It checks for specific storage errors like [storage.ErrNotFound] and [storage.ErrAlreadyExists].
+// It checks for specific storage errors like [storage.ErrNotFound], [storage.ErrAlreadyExists],
+// [storage.ErrVersionMismatch] and [storage.ErrConflict].
 func (m *simulatedTaskService) handleStorageError(log *slog.Logger, op string, err error) error {
 	if errors.Is(err, storage.ErrNotFound) {
 		log.Warn("Task not found", slog.Any("error", err))
@@ -124,6 +654,14 @@ func (m *simulatedTaskService) handleStorageError(log *slog.Logger, op string, e
 		log.Warn("Task already exists", slog.Any("error", err))
 		return fmt.Errorf("%s: task already exists: %w", op, service.ErrAlreadyExist)
 	}
+	if errors.Is(err, storage.ErrVersionMismatch) {
+		log.Warn("Task version mismatch", slog.Any("error", err))
+		return fmt.Errorf("%s: %w", op, service.ErrVersionMismatch)
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		log.Warn("Task status conflict", slog.Any("error", err))
+		return fmt.Errorf("%s: %w: %w", op, service.ErrConflict, err)
+	}
 	log.Error("Unexpected storage error", slog.Any("error", err))
 	return fmt.Errorf("%s: unexpected storage error: %v", op, err)
 }
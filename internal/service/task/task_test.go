@@ -0,0 +1,77 @@
+package task
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/service"
+	"github.com/passwordhash/task-manager-api/internal/storage/inmemory"
+	"github.com/passwordhash/task-manager-api/internal/worker"
+	"github.com/passwordhash/task-manager-api/internal/worker/executor"
+	"github.com/passwordhash/task-manager-api/internal/worker/pool"
+)
+
+// priorityPool records the last priority the queue got for each task.
+type priorityPool struct {
+	worker.TaskPool
+
+	mu         sync.Mutex
+	priorities map[string]int
+}
+
+func (p *priorityPool) SetPriority(taskID string, priority int) bool {
+	// Give concurrent updates a chance to overtake this one.
+	time.Sleep(time.Millisecond)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.priorities[taskID] = priority
+	return p.TaskPool.SetPriority(taskID, priority)
+}
+
+func TestConcurrentPriorityUpdatesKeepQueueInSync(t *testing.T) {
+	log := slog.New(slog.DiscardHandler)
+	storage := inmemory.NewTaskStorage()
+	// The pool is not started, so the task stays queued.
+	queue := &priorityPool{
+		TaskPool:   pool.New(log, 1, 10, pool.Limits{}, executor.New(events.NewBroker()), storage),
+		priorities: make(map[string]int),
+	}
+	pools, err := pool.NewGroups(queue)
+	if err != nil {
+		t.Fatalf("create worker groups: %v", err)
+	}
+	svc := NewSimulatedTaskService(log, pools, storage, NamespaceQuotas{})
+
+	ctx := context.Background()
+	taskUUID, err := svc.CreateTask(ctx, service.CreateTaskParams{})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for priority := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.Update(ctx, domain.DefaultNamespace, taskUUID, service.UpdateTaskParams{Priority: &priority}, 0); err != nil {
+				t.Errorf("Update: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	task, err := svc.Get(ctx, domain.DefaultNamespace, taskUUID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := queue.priorities[taskUUID]; got != task.Priority {
+		t.Errorf("task has priority %d in the queue, but %d in the storage", got, task.Priority)
+	}
+}
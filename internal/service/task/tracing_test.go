@@ -0,0 +1,99 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/service"
+	"github.com/passwordhash/task-manager-api/internal/storage/inmemory"
+	"github.com/passwordhash/task-manager-api/internal/storage/traced"
+	"github.com/passwordhash/task-manager-api/internal/worker/executor"
+	"github.com/passwordhash/task-manager-api/internal/worker/pool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanRecorder records the spans of the package. Tracers created before
+// the global tracer provider is set only follow the first one set,
+// so it is set once for all runs of the tests.
+var spanRecorder = sync.OnceValue(func() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+})
+
+func TestServiceRecordsSpans(t *testing.T) {
+	recorder := spanRecorder()
+	before := len(recorder.Ended())
+
+	log := slog.New(slog.DiscardHandler)
+	storage := traced.NewTaskStorage(inmemory.NewTaskStorage())
+	// The pool is not started, so the created task stays queued.
+	pools, err := pool.NewGroups(pool.New(log, 1, 10, pool.Limits{}, executor.New(events.NewBroker()), storage))
+	if err != nil {
+		t.Fatalf("create worker groups: %v", err)
+	}
+	svc := NewSimulatedTaskService(log, pools, storage, NamespaceQuotas{MaxTasks: 10})
+
+	ctx := context.Background()
+	if _, err := svc.CreateTask(ctx, service.CreateTaskParams{Type: "io"}); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := svc.Get(ctx, domain.DefaultNamespace, uuid.NewString()); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("Get of a missing task returned %v, want %v", err, service.ErrNotFound)
+	}
+
+	ended := recorder.Ended()[before:]
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range ended {
+		spans[span.Name()] = span
+	}
+
+	create := spans["TaskService.CreateTask"]
+	if create == nil {
+		t.Fatalf("no TaskService.CreateTask span in %v", spanNames(ended))
+	}
+	if create.Status().Code == codes.Error {
+		t.Errorf("TaskService.CreateTask span has error status: %s", create.Status().Description)
+	}
+	for _, name := range []string{"storage.Count", "storage.Save"} {
+		span := spans[name]
+		if span == nil {
+			t.Errorf("no %s span in %v", name, spanNames(ended))
+			continue
+		}
+		if span.Parent().SpanID() != create.SpanContext().SpanID() {
+			t.Errorf("%s span is not a child of the TaskService.CreateTask span", name)
+		}
+	}
+
+	for _, name := range []string{"TaskService.Get", "storage.Get"} {
+		span := spans[name]
+		if span == nil {
+			t.Errorf("no %s span in %v", name, spanNames(ended))
+			continue
+		}
+		if span.Status().Code != codes.Error {
+			t.Errorf("%s span has status %v, want %v", name, span.Status().Code, codes.Error)
+		}
+		if len(span.Events()) == 0 || span.Events()[0].Name != "exception" {
+			t.Errorf("%s span didn't record the error", name)
+		}
+	}
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	return names
+}
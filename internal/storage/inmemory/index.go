@@ -0,0 +1,92 @@
+package inmemory
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+)
+
+// labelIndex is an inverted index of the labels of the tasks
+// of a namespace: it maps label keys and values to the UUIDs
+// of the tasks having them.
+type labelIndex map[string]map[string]map[string]struct{}
+
+func (ix labelIndex) add(uuid string, labels map[string]string) {
+	for key, value := range labels {
+		values, ok := ix[key]
+		if !ok {
+			values = make(map[string]map[string]struct{})
+			ix[key] = values
+		}
+		uuids, ok := values[value]
+		if !ok {
+			uuids = make(map[string]struct{})
+			values[value] = uuids
+		}
+		uuids[uuid] = struct{}{}
+	}
+}
+
+func (ix labelIndex) remove(uuid string, labels map[string]string) {
+	for key, value := range labels {
+		uuids := ix[key][value]
+		delete(uuids, uuid)
+		if len(uuids) == 0 {
+			delete(ix[key], value)
+		}
+		if len(ix[key]) == 0 {
+			delete(ix, key)
+		}
+	}
+}
+
+// candidates returns the UUIDs of the tasks that satisfy the positive
+// requirements of the selector, see [domain.Requirement.Positive].
+// The tasks still have to be matched against the other requirements.
+// ok is false if the selector has no positive requirements
+// and every task is a candidate.
+func (ix labelIndex) candidates(selector domain.Selector) (uuids map[string]struct{}, ok bool) {
+	var sets []map[string]struct{}
+	for _, r := range selector {
+		if !r.Positive() {
+			continue
+		}
+
+		values := ix[r.Key]
+		if r.Operator != domain.OpExists {
+			values = make(map[string]map[string]struct{}, len(r.Values))
+			for _, value := range r.Values {
+				values[value] = ix[r.Key][value]
+			}
+		}
+		sets = append(sets, union(values))
+	}
+	if len(sets) == 0 {
+		return nil, false
+	}
+
+	// Intersecting from the smallest set keeps every step
+	// no larger than it.
+	slices.SortFunc(sets, func(a, b map[string]struct{}) int {
+		return len(a) - len(b)
+	})
+	uuids = sets[0]
+	for _, set := range sets[1:] {
+		maps.DeleteFunc(uuids, func(uuid string, _ struct{}) bool {
+			_, ok := set[uuid]
+			return !ok
+		})
+	}
+
+	return uuids, true
+}
+
+// union returns a new set with the UUIDs of all sets.
+func union(sets map[string]map[string]struct{}) map[string]struct{} {
+	result := make(map[string]struct{})
+	for _, set := range sets {
+		maps.Copy(result, set)
+	}
+	return result
+}
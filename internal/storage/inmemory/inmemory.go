@@ -3,6 +3,7 @@ package inmemory
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -12,13 +13,17 @@ import (
 )
 
 type taskStorage struct {
-	mu    sync.RWMutex
-	tasks map[string]*model.Task
+	mu sync.RWMutex
+	// tasks holds tasks by namespace and UUID.
+	tasks map[string]map[string]*model.Task
+	// labels indexes the labels of the tasks by namespace.
+	labels map[string]labelIndex
 }
 
 func NewTaskStorage() storage.Task {
 	return &taskStorage{
-		tasks: make(map[string]*model.Task),
+		tasks:  make(map[string]map[string]*model.Task),
+		labels: make(map[string]labelIndex),
 	}
 }
 
@@ -29,7 +34,13 @@ func (t *taskStorage) Save(_ context.Context, task domain.Task) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	_, exists := t.tasks[task.UUID]
+	tasks, ok := t.tasks[task.Namespace]
+	if !ok {
+		tasks = make(map[string]*model.Task)
+		t.tasks[task.Namespace] = tasks
+	}
+
+	_, exists := tasks[task.UUID]
 	if exists {
 		// If the task already exists, return an error.
 		// In this case it is unnecessary to check if the task is already in the storage,
@@ -37,20 +48,32 @@ func (t *taskStorage) Save(_ context.Context, task domain.Task) error {
 		return fmt.Errorf("%s: %w", op, storage.ErrAlreadyExists)
 	}
 
+	if task.RetryOf != "" {
+		original, exists := tasks[task.RetryOf]
+		if !exists {
+			return fmt.Errorf("%s: retried task %s: %w", op, task.RetryOf, storage.ErrNotFound)
+		}
+		original.Retries = append(original.Retries, task.UUID)
+		original.History = append(original.History, retryTransition(original, task))
+		original.Version++
+	}
+
 	storageTask := model.FromDomainToTask(task)
+	storageTask.Version = 1
 
-	t.tasks[task.UUID] = storageTask
+	tasks[task.UUID] = storageTask
+	t.labelIndex(task.Namespace).add(task.UUID, storageTask.Labels)
 
 	return nil
 }
 
-func (t *taskStorage) Get(_ context.Context, uuid string) (domain.Task, error) {
+func (t *taskStorage) Get(_ context.Context, namespace string, uuid string) (domain.Task, error) {
 	const op = "taskstorage.Get"
 
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	task, exists := t.tasks[uuid]
+	task, exists := t.tasks[namespace][uuid]
 	if !exists {
 		return domain.Task{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
 	}
@@ -58,7 +81,7 @@ func (t *taskStorage) Get(_ context.Context, uuid string) (domain.Task, error) {
 	return task.ToDomain(uuid), nil
 }
 
-func (t *taskStorage) GetAll(ctx context.Context) (tasks []domain.Task, err error) {
+func (t *taskStorage) GetAll(ctx context.Context, namespace string, selector domain.Selector) (tasks []domain.Task, err error) {
 	const op = "taskstorage.GetAll"
 
 	t.mu.RLock()
@@ -68,30 +91,76 @@ func (t *taskStorage) GetAll(ctx context.Context) (tasks []domain.Task, err erro
 		return nil, fmt.Errorf("%s: %w", op, ctx.Err())
 	}
 
-	for uuid, task := range t.tasks {
-		tasks = append(tasks, task.ToDomain(uuid))
+	namespaceTasks := t.tasks[namespace]
+	candidates, ok := t.labels[namespace].candidates(selector)
+	if !ok {
+		for uuid, task := range namespaceTasks {
+			if selector.Matches(task.Labels) {
+				tasks = append(tasks, task.ToDomain(uuid))
+			}
+		}
+		return tasks, nil
+	}
+
+	for uuid := range candidates {
+		if task, ok := namespaceTasks[uuid]; ok && selector.Matches(task.Labels) {
+			tasks = append(tasks, task.ToDomain(uuid))
+		}
 	}
 
 	return tasks, nil
 }
 
-func (t *taskStorage) Update(_ context.Context, uuid string, u storage.TaskUpdate) error {
+func (t *taskStorage) Count(_ context.Context, namespace string) (int, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.tasks[namespace]), nil
+}
+
+func (t *taskStorage) Update(_ context.Context, namespace string, uuid string, u storage.TaskUpdate) error {
 	const op = "taskstorage.Update"
 
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	task, exists := t.tasks[uuid]
+	task, exists := t.tasks[namespace][uuid]
 	if !exists {
 		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
 	}
 
+	if u.ExpectedVersion != 0 && u.ExpectedVersion != task.Version {
+		return fmt.Errorf("%s: version %d, expected %d: %w", op, task.Version, u.ExpectedVersion, storage.ErrVersionMismatch)
+	}
+
+	current := domain.TaskStatus(task.Status)
+	if (u.ExpectedStatus != "" && u.ExpectedStatus != current) ||
+		(u.Status != "" && !current.CanTransitionTo(u.Status)) {
+		return fmt.Errorf("%s: %w", op, &storage.ConflictError{
+			Current:  current,
+			Expected: u.ExpectedStatus,
+			Target:   u.Status,
+		})
+	}
+
+	now := time.Now()
+	at := u.UpdatedAt
+	if at.IsZero() {
+		at = now
+	}
+
 	if u.Status != "" {
-		if u.Status == domain.StatusRunning &&
-			domain.TaskStatus(task.Status) == domain.StatusPending {
-			now := time.Now()
+		if u.Status == domain.StatusRunning {
 			task.StartedAt = now
 		}
+
+		task.History = append(task.History, domain.Transition{
+			From:   current,
+			To:     u.Status,
+			At:     at,
+			Actor:  u.Actor,
+			Reason: u.Reason,
+		})
 		task.Status = string(u.Status)
 	}
 
@@ -108,6 +177,101 @@ func (t *taskStorage) Update(_ context.Context, uuid string, u storage.TaskUpdat
 		task.Error = u.Error
 	}
 
-	t.tasks[uuid] = task
+	edited := len(u.Labels) > 0 || u.ClearLabels || u.Description != nil || u.Priority != nil || u.RunAt != nil
+	if len(u.Labels) > 0 || u.ClearLabels {
+		t.labels[namespace].remove(uuid, task.Labels)
+		if u.ClearLabels {
+			task.Labels = nil
+		}
+		for key, value := range u.Labels {
+			if value == nil {
+				delete(task.Labels, key)
+				continue
+			}
+			if task.Labels == nil {
+				task.Labels = make(map[string]string)
+			}
+			task.Labels[key] = *value
+		}
+		t.labelIndex(namespace).add(uuid, task.Labels)
+	}
+	if u.Description != nil {
+		task.Description = *u.Description
+	}
+	if u.Priority != nil {
+		task.Priority = *u.Priority
+	}
+	if u.RunAt != nil {
+		task.RunAt = *u.RunAt
+	}
+	if edited && u.Status == "" {
+		task.History = append(task.History, domain.Transition{
+			From:   current,
+			To:     current,
+			At:     at,
+			Actor:  u.Actor,
+			Reason: u.Reason,
+		})
+	}
+
+	task.Version++
+
+	return nil
+}
+
+func (t *taskStorage) Delete(_ context.Context, namespace string, uuid string, version uint64) error {
+	const op = "taskstorage.Delete"
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tasks := t.tasks[namespace]
+	task, exists := tasks[uuid]
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if version != 0 && version != task.Version {
+		return fmt.Errorf("%s: version %d, expected %d: %w", op, task.Version, version, storage.ErrVersionMismatch)
+	}
+
+	if original, exists := tasks[task.RetryOf]; exists {
+		original.Retries = slices.DeleteFunc(original.Retries, func(retry string) bool {
+			return retry == uuid
+		})
+		original.Version++
+	}
+
+	delete(tasks, uuid)
+	t.labels[namespace].remove(uuid, task.Labels)
+
 	return nil
 }
+
+// retryTransition returns the entry of the history of the original task
+// recording that retry re-runs it. The retry is requested by the actor
+// of the first transition of retry.
+func retryTransition(original *model.Task, retry domain.Task) domain.Transition {
+	actor := domain.ActorSystem
+	if len(retry.History) > 0 {
+		actor = retry.History[0].Actor
+	}
+	status := domain.TaskStatus(original.Status)
+	return domain.Transition{
+		From:   status,
+		To:     status,
+		At:     retry.CreatedAt,
+		Actor:  actor,
+		Reason: "retried as " + retry.UUID,
+	}
+}
+
+// labelIndex returns the label index of the namespace, creating it
+// if needed. Must be called with mu held for writing.
+func (t *taskStorage) labelIndex(namespace string) labelIndex {
+	ix, ok := t.labels[namespace]
+	if !ok {
+		ix = make(labelIndex)
+		t.labels[namespace] = ix
+	}
+	return ix
+}
@@ -0,0 +1,288 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/storage"
+)
+
+func saveTask(t *testing.T, s storage.Task, status domain.TaskStatus) {
+	t.Helper()
+
+	err := s.Save(context.Background(), domain.Task{
+		UUID:      "task",
+		Namespace: domain.DefaultNamespace,
+		Status:    status,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func TestUpdateRejectsIllegalTransitions(t *testing.T) {
+	tests := []struct {
+		from, to domain.TaskStatus
+		expected domain.TaskStatus
+		wantErr  bool
+	}{
+		{from: domain.StatusPending, to: domain.StatusRunning},
+		{from: domain.StatusPending, to: domain.StatusRunning, expected: domain.StatusPending},
+		{from: domain.StatusRunning, to: domain.StatusCompleted, expected: domain.StatusRunning},
+		{from: domain.StatusCanceled, to: domain.StatusCompleted, expected: domain.StatusRunning, wantErr: true},
+		{from: domain.StatusCompleted, to: domain.StatusRunning, wantErr: true},
+		{from: domain.StatusPending, to: domain.StatusCompleted, wantErr: true},
+		{from: domain.StatusRunning, to: domain.StatusRunning, wantErr: true},
+	}
+	for _, tt := range tests {
+		s := NewTaskStorage()
+		saveTask(t, s, tt.from)
+
+		err := s.Update(context.Background(), domain.DefaultNamespace, "task", storage.TaskUpdate{
+			Status:         tt.to,
+			ExpectedStatus: tt.expected,
+			Result:         "result",
+		})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s -> %s expecting %q: error = %v, want error %v", tt.from, tt.to, tt.expected, err, tt.wantErr)
+			continue
+		}
+
+		if !tt.wantErr {
+			continue
+		}
+
+		var conflict *storage.ConflictError
+		if !errors.As(err, &conflict) || !errors.Is(err, storage.ErrConflict) {
+			t.Errorf("%s -> %s: error %v is not a conflict", tt.from, tt.to, err)
+		} else if conflict.Current != tt.from {
+			t.Errorf("%s -> %s: conflict reports status %s", tt.from, tt.to, conflict.Current)
+		}
+		task, _ := s.Get(context.Background(), domain.DefaultNamespace, "task")
+		if task.Status != tt.from || task.Result != nil {
+			t.Errorf("%s -> %s: rejected update changed the task to %s with result %v", tt.from, tt.to, task.Status, task.Result)
+		}
+	}
+}
+
+func TestConcurrentUpdatesApplyOnce(t *testing.T) {
+	s := NewTaskStorage()
+	saveTask(t, s, domain.StatusRunning)
+
+	// A worker finishing the task races with a client canceling it:
+	// only one of them may move it out of running.
+	statuses := []domain.TaskStatus{domain.StatusCompleted, domain.StatusCanceled, domain.StatusFailed}
+	errs := make([]error, 30)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.Update(context.Background(), domain.DefaultNamespace, "task", storage.TaskUpdate{
+				Status:         statuses[i%len(statuses)],
+				ExpectedStatus: domain.StatusRunning,
+			})
+		}()
+	}
+	wg.Wait()
+
+	applied := 0
+	for _, err := range errs {
+		if err == nil {
+			applied++
+		} else if !errors.Is(err, storage.ErrConflict) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if applied != 1 {
+		t.Errorf("%d updates applied, want 1", applied)
+	}
+
+	task, _ := s.Get(context.Background(), domain.DefaultNamespace, "task")
+	if len(task.History) != 1 || task.History[0].From != domain.StatusRunning {
+		t.Errorf("history is %+v, want a single transition from running", task.History)
+	}
+}
+
+func TestVersionGrowsWithChanges(t *testing.T) {
+	ctx := context.Background()
+	s := NewTaskStorage()
+	saveTask(t, s, domain.StatusPending)
+
+	version := func() uint64 {
+		task, _ := s.Get(ctx, domain.DefaultNamespace, "task")
+		return task.Version
+	}
+	if v := version(); v != 1 {
+		t.Fatalf("saved task has version %d, want 1", v)
+	}
+
+	err := s.Update(ctx, domain.DefaultNamespace, "task", storage.TaskUpdate{Status: domain.StatusCanceled, ExpectedVersion: 2})
+	if !errors.Is(err, storage.ErrVersionMismatch) {
+		t.Errorf("update of a stale version returned %v, want ErrVersionMismatch", err)
+	}
+	err = s.Update(ctx, domain.DefaultNamespace, "task", storage.TaskUpdate{Status: domain.StatusCanceled, ExpectedVersion: 1})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if v := version(); v != 2 {
+		t.Errorf("updated task has version %d, want 2", v)
+	}
+
+	retry := domain.Task{
+		UUID:      "retry",
+		Namespace: domain.DefaultNamespace,
+		CreatedAt: time.Now(),
+		RetryOf:   "task",
+		History:   []domain.Transition{{To: domain.StatusPending, Actor: "client"}},
+	}
+	if err := s.Save(ctx, retry); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if v := version(); v != 3 {
+		t.Errorf("retried task has version %d, want 3", v)
+	}
+	task, _ := s.Get(ctx, domain.DefaultNamespace, "task")
+	if last := task.History[len(task.History)-1]; !last.IsEdit() || last.Actor != "client" || last.Reason != "retried as retry" {
+		t.Errorf("last transition of the retried task is %+v, want the retry recorded by its actor", last)
+	}
+
+	if err := s.Delete(ctx, domain.DefaultNamespace, "task", 2); !errors.Is(err, storage.ErrVersionMismatch) {
+		t.Errorf("delete of a stale version returned %v, want ErrVersionMismatch", err)
+	}
+	if err := s.Delete(ctx, domain.DefaultNamespace, "task", 3); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+}
+
+func TestEditRecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	s := NewTaskStorage()
+	saveTask(t, s, domain.StatusCompleted)
+
+	set, description := "prod", "nightly report"
+	err := s.Update(ctx, domain.DefaultNamespace, "task", storage.TaskUpdate{
+		Labels:      map[string]*string{"env": &set, "draft": nil},
+		Description: &description,
+		Reason:      "label env set",
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// Priority of a finished task can't change, the status guards it.
+	priority := 10
+	err = s.Update(ctx, domain.DefaultNamespace, "task", storage.TaskUpdate{
+		Priority:       &priority,
+		ExpectedStatus: domain.StatusPending,
+	})
+	if !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("priority update of a completed task returned %v, want ErrConflict", err)
+	}
+
+	task, _ := s.Get(ctx, domain.DefaultNamespace, "task")
+	if task.Labels["env"] != "prod" || len(task.Labels) != 1 || task.Description != description || task.Priority != 0 {
+		t.Errorf("edited task has labels %v, description %q and priority %d", task.Labels, task.Description, task.Priority)
+	}
+	if len(task.History) != 1 || !task.History[0].IsEdit() || task.History[0].To != domain.StatusCompleted {
+		t.Errorf("history is %+v, want a single edit", task.History)
+	}
+	if task.Version != 2 {
+		t.Errorf("edited task has version %d, want 2", task.Version)
+	}
+}
+
+func TestGetAllBySelector(t *testing.T) {
+	ctx := context.Background()
+	s := NewTaskStorage()
+
+	save := func(uuid string, labels map[string]string) {
+		t.Helper()
+		err := s.Save(ctx, domain.Task{UUID: uuid, Namespace: domain.DefaultNamespace, Labels: labels})
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	save("prod-a", map[string]string{"env": "prod", "batch": "a"})
+	save("prod-b", map[string]string{"env": "prod", "batch": "b", "draft": ""})
+	save("dev", map[string]string{"env": "dev"})
+	save("none", nil)
+
+	selectUUIDs := func(s storage.Task, raw string) []string {
+		t.Helper()
+		selector, err := domain.ParseSelector(raw)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q): %v", raw, err)
+		}
+		tasks, err := s.GetAll(ctx, domain.DefaultNamespace, selector)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		var uuids []string
+		for _, task := range tasks {
+			uuids = append(uuids, task.UUID)
+		}
+		slices.Sort(uuids)
+		return uuids
+	}
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{selector: "", want: []string{"dev", "none", "prod-a", "prod-b"}},
+		{selector: "env=prod", want: []string{"prod-a", "prod-b"}},
+		{selector: "env=prod,!draft", want: []string{"prod-a"}},
+		{selector: "batch in (a,b),env", want: []string{"prod-a", "prod-b"}},
+		{selector: "env!=prod", want: []string{"dev", "none"}},
+		{selector: "env notin (dev),batch notin (b)", want: []string{"none", "prod-a"}},
+		{selector: "env=staging", want: nil},
+	}
+	for _, tt := range tests {
+		if got := selectUUIDs(s, tt.selector); !slices.Equal(got, tt.want) {
+			t.Errorf("%q selected %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+
+	// The index follows label changes and deletions.
+	staging := "staging"
+	err := s.Update(ctx, domain.DefaultNamespace, "none", storage.TaskUpdate{Labels: map[string]*string{"env": &staging}})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	err = s.Update(ctx, domain.DefaultNamespace, "prod-b", storage.TaskUpdate{Labels: map[string]*string{"env": nil}})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.Delete(ctx, domain.DefaultNamespace, "dev", 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if got, want := selectUUIDs(s, "env"), []string{"none", "prod-a"}; !slices.Equal(got, want) {
+		t.Errorf("after changes env selected %v, want %v", got, want)
+	}
+	if got, want := selectUUIDs(s, "env in (dev,staging)"), []string{"none"}; !slices.Equal(got, want) {
+		t.Errorf("after changes env in (dev,staging) selected %v, want %v", got, want)
+	}
+
+	batch := "c"
+	err = s.Update(ctx, domain.DefaultNamespace, "prod-a", storage.TaskUpdate{
+		ClearLabels: true,
+		Labels:      map[string]*string{"batch": &batch},
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, want := selectUUIDs(s, "env"), []string{"none"}; !slices.Equal(got, want) {
+		t.Errorf("after clearing labels env selected %v, want %v", got, want)
+	}
+	if got, want := selectUUIDs(s, "batch=c"), []string{"prod-a"}; !slices.Equal(got, want) {
+		t.Errorf("after clearing labels batch=c selected %v, want %v", got, want)
+	}
+}
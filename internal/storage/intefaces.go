@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/passwordhash/task-manager-api/internal/domain"
@@ -11,28 +12,106 @@ import (
 var (
 	ErrAlreadyExists = errors.New("already exists")
 	ErrNotFound      = errors.New("not found")
+
+	// ErrConflict is matched by [*ConflictError].
+	ErrConflict = errors.New("status conflict")
+
+	// ErrVersionMismatch is returned when the task has another version
+	// than the one a conditional change expects, see [domain.Task.Version].
+	ErrVersionMismatch = errors.New("version mismatch")
 )
 
+// ConflictError is returned by [Task.Update] when the task is not
+// in the expected status or can't move from its status to the new one,
+// see [domain.TaskStatus.CanTransitionTo].
+type ConflictError struct {
+	// Current is the status of the task at the moment of the update.
+	Current  domain.TaskStatus
+	Expected domain.TaskStatus
+	Target   domain.TaskStatus
+}
+
+func (e *ConflictError) Error() string {
+	if e.Expected != "" && e.Expected != e.Current {
+		return fmt.Sprintf("task is %s, not %s", e.Current, e.Expected)
+	}
+	return fmt.Sprintf("task can't move from %s to %s", e.Current, e.Target)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
 type TaskUpdate struct {
-	Status    domain.TaskStatus
+	Status domain.TaskStatus
+	// ExpectedStatus makes the update conditional: if set, the update
+	// is applied only while the task is in this status.
+	ExpectedStatus domain.TaskStatus
+	// ExpectedVersion, if not zero, makes the update conditional
+	// on the version of the task.
+	ExpectedVersion uint64
+
 	UpdatedAt time.Time
 	StartedAt time.Time
 	Result    any
 	Error     error
+
+	// Labels are merged into the labels of the task,
+	// a nil value removes the label.
+	Labels map[string]*string
+	// ClearLabels removes all labels of the task before Labels are merged.
+	ClearLabels bool
+	// Description, Priority and RunAt replace those of the task if not nil.
+	Description *string
+	Priority    *int
+	RunAt       *time.Time
+
+	// Actor and Reason describe the transition to Status
+	// recorded in the history of the task. An update of labels,
+	// description or priority without Status is recorded as an edit,
+	// see [domain.Transition.IsEdit].
+	Actor  string
+	Reason string
 }
 
 // Task defines the interface for task storage operations.
+//
+// Tasks are stored per namespace: every method only sees the tasks
+// of the given namespace, a task of another namespace is reported
+// as not existing.
 type Task interface {
-	// Save persists a task in the storage. If the task already exists,
-	// it returns an [ErrAlreadyExists]. Thread safety is guaranteed.
+	// Save persists a task with its history in the storage in task.Namespace
+	// with version 1. Every change of a stored task increments its version,
+	// including linking a retry to it. If the task already exists,
+	// it returns an [ErrAlreadyExists]. If the task is a re-run (RetryOf is set),
+	// it is linked to the original one, which must exist in the same namespace,
+	// otherwise [ErrNotFound] is returned. The link is recorded in the history
+	// of the original task. Thread safety is guaranteed.
 	Save(ctx context.Context, task domain.Task) (err error)
 
 	// Get retrieves a task by its UUID. If the task does not exist,
 	// it returns an [ErrNotFound]. Thread safety is guaranteed.
-	Get(ctx context.Context, uuid string) (task domain.Task, err error)
+	Get(ctx context.Context, namespace string, uuid string) (task domain.Task, err error)
+
+	// GetAll retrieves the tasks of the namespace whose labels match
+	// the selector, all of them for an empty selector. Thread safety is guaranteed.
+	GetAll(ctx context.Context, namespace string, selector domain.Selector) (tasks []domain.Task, err error)
+
+	// Count returns the number of tasks in the namespace. Thread safety is guaranteed.
+	Count(ctx context.Context, namespace string) (count int, err error)
 
-	// GetAll retrieves all tasks from the storage. Thread safety is guaranteed.
-	GetAll(ctx context.Context) (tasks []domain.Task, err error)
+	// Update applies the update to the task. A change of the status is
+	// appended to the history of the task. If the task does not exist,
+	// it returns an [ErrNotFound]. If the task has another version than
+	// a non-zero update.ExpectedVersion, it returns an [ErrVersionMismatch].
+	// If the task is not in update.ExpectedStatus or its status can't move
+	// to update.Status, it returns a [*ConflictError]. Nothing is changed
+	// on errors, the checks and the change are atomic. Thread safety is guaranteed.
+	Update(ctx context.Context, namespace string, uuid string, update TaskUpdate) (err error)
 
-	Update(ctx context.Context, uuid string, update TaskUpdate) (err error)
+	// Delete removes the task and unlinks it from the task it retries.
+	// If the task does not exist, it returns an [ErrNotFound]. If the task
+	// has another version than a non-zero version, it returns an [ErrVersionMismatch]
+	// and the task is kept. Thread safety is guaranteed.
+	Delete(ctx context.Context, namespace string, uuid string, version uint64) (err error)
 }
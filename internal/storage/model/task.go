@@ -1,39 +1,86 @@
 package model
 
 import (
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/passwordhash/task-manager-api/internal/domain"
 )
 
 type Task struct {
-	Status    string
-	CreatedAt time.Time
-	StartedAt time.Time
-	UpdatedAt time.Time
-	Result    any
-	Error     error
+	Namespace    string
+	Type         string
+	Payload      any
+	Tenant       string
+	Owner        string
+	Labels       map[string]string
+	Description  string
+	Priority     int
+	RunAt        time.Time
+	Status       string
+	CreatedAt    time.Time
+	StartedAt    time.Time
+	UpdatedAt    time.Time
+	Result       any
+	Error        error
+	RequestID    string
+	TraceContext map[string]string
+	RetryOf      string
+	Retries      []string
+	History      []domain.Transition
+	Version      uint64
 }
 
 func (task *Task) ToDomain(uuid string) domain.Task {
 	return domain.Task{
-		UUID:      uuid,
-		Status:    domain.TaskStatus(task.Status),
-		CreatedAt: task.CreatedAt,
-		StartedAt: task.StartedAt,
-		UpdatedAt: task.UpdatedAt,
-		Result:    task.Result,
-		Error:     task.Error,
+		UUID:         uuid,
+		Namespace:    task.Namespace,
+		Type:         task.Type,
+		Payload:      task.Payload,
+		Tenant:       task.Tenant,
+		Owner:        task.Owner,
+		Labels:       maps.Clone(task.Labels),
+		Description:  task.Description,
+		Priority:     task.Priority,
+		RunAt:        task.RunAt,
+		Status:       domain.TaskStatus(task.Status),
+		CreatedAt:    task.CreatedAt,
+		StartedAt:    task.StartedAt,
+		UpdatedAt:    task.UpdatedAt,
+		Result:       task.Result,
+		Error:        task.Error,
+		RequestID:    task.RequestID,
+		TraceContext: maps.Clone(task.TraceContext),
+		RetryOf:      task.RetryOf,
+		Retries:      slices.Clone(task.Retries),
+		History:      slices.Clone(task.History),
+		Version:      task.Version,
 	}
 }
 
 func FromDomainToTask(task domain.Task) *Task {
 	return &Task{
-		Status:    string(task.Status),
-		CreatedAt: task.CreatedAt,
-		StartedAt: task.StartedAt,
-		UpdatedAt: task.UpdatedAt,
-		Result:    task.Result,
-		Error:     task.Error,
+		Namespace:    task.Namespace,
+		Type:         task.Type,
+		Payload:      task.Payload,
+		Tenant:       task.Tenant,
+		Owner:        task.Owner,
+		Labels:       maps.Clone(task.Labels),
+		Description:  task.Description,
+		Priority:     task.Priority,
+		RunAt:        task.RunAt,
+		Status:       string(task.Status),
+		CreatedAt:    task.CreatedAt,
+		StartedAt:    task.StartedAt,
+		UpdatedAt:    task.UpdatedAt,
+		Result:       task.Result,
+		Error:        task.Error,
+		RequestID:    task.RequestID,
+		TraceContext: maps.Clone(task.TraceContext),
+		RetryOf:      task.RetryOf,
+		Retries:      slices.Clone(task.Retries),
+		History:      slices.Clone(task.History),
+		Version:      task.Version,
 	}
 }
@@ -0,0 +1,92 @@
+package notifying
+
+// Package notifying wraps a task storage to publish a status event
+// for every stored status of a task.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/storage"
+)
+
+type taskStorage struct {
+	storage.Task
+
+	publisher events.Publisher
+
+	// mu serializes changes with the publication of their events,
+	// so status events of a task are published in the order of
+	// the changes and carry the version each change produced.
+	mu sync.Mutex
+}
+
+// NewTaskStorage returns a storage that passes calls on to next
+// and publishes a status event once a task is saved or its status
+// is updated.
+func NewTaskStorage(next storage.Task, publisher events.Publisher) storage.Task {
+	return &taskStorage{Task: next, publisher: publisher}
+}
+
+func (t *taskStorage) Save(ctx context.Context, task domain.Task) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.Task.Save(ctx, task); err != nil {
+		return err
+	}
+
+	t.publish(ctx, task.Namespace, task.UUID, task.CreatedAt)
+
+	return nil
+}
+
+func (t *taskStorage) Update(ctx context.Context, namespace string, uuid string, update storage.TaskUpdate) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.Task.Update(ctx, namespace, uuid, update); err != nil {
+		return err
+	}
+
+	if update.Status != "" {
+		t.publish(ctx, namespace, uuid, update.UpdatedAt)
+	}
+
+	return nil
+}
+
+func (t *taskStorage) Delete(ctx context.Context, namespace string, uuid string, version uint64) error {
+	// Deleting a retry changes the version of the task it retries.
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.Task.Delete(ctx, namespace, uuid, version)
+}
+
+// publish publishes the stored status and version of the task.
+// t.mu must be held.
+func (t *taskStorage) publish(ctx context.Context, namespace string, uuid string, at time.Time) {
+	task, err := t.Task.Get(ctx, namespace, uuid)
+	if err != nil {
+		// The task was stored a moment ago and can't be changed
+		// without t.mu, so this only happens if the storage fails.
+		return
+	}
+
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	t.publisher.Publish(events.Event{
+		Type:      events.TypeStatus,
+		Namespace: namespace,
+		TaskUUID:  uuid,
+		Time:      at,
+		Status:    task.Status,
+		Version:   task.Version,
+	})
+}
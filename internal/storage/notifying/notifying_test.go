@@ -0,0 +1,121 @@
+package notifying
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/storage"
+	"github.com/passwordhash/task-manager-api/internal/storage/inmemory"
+)
+
+// recorder records published events.
+type recorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *recorder) Publish(event events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func TestStatusEventsCarryVersions(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recorder{}
+	s := NewTaskStorage(inmemory.NewTaskStorage(), publisher)
+
+	task := domain.Task{UUID: "a", Namespace: domain.DefaultNamespace, Status: domain.StatusPending, CreatedAt: time.Now()}
+	if err := s.Save(ctx, task); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	description := "edited"
+	updates := []storage.TaskUpdate{
+		{Status: domain.StatusRunning},
+		// An edit changes the version without an event.
+		{Description: &description},
+		{Status: domain.StatusCompleted},
+	}
+	for _, update := range updates {
+		if err := s.Update(ctx, task.Namespace, task.UUID, update); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	want := []struct {
+		status  domain.TaskStatus
+		version uint64
+	}{
+		{domain.StatusPending, 1},
+		{domain.StatusRunning, 2},
+		{domain.StatusCompleted, 4},
+	}
+	if len(publisher.events) != len(want) {
+		t.Fatalf("published %d events, want %d", len(publisher.events), len(want))
+	}
+	for i, event := range publisher.events {
+		if event.Status != want[i].status || event.Version != want[i].version {
+			t.Errorf("event %d is %s at version %d, want %s at version %d",
+				i, event.Status, event.Version, want[i].status, want[i].version)
+		}
+	}
+}
+
+func TestConcurrentUpdatesArePublishedInOrder(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recorder{}
+	s := NewTaskStorage(inmemory.NewTaskStorage(), publisher)
+
+	const tasks = 100
+	for i := range tasks {
+		task := domain.Task{UUID: strconv.Itoa(i), Namespace: domain.DefaultNamespace, Status: domain.StatusPending}
+		if err := s.Save(ctx, task); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	// A worker starting and completing each task races with a cancel.
+	var wg sync.WaitGroup
+	for i := range tasks {
+		uuid := strconv.Itoa(i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = s.Update(ctx, domain.DefaultNamespace, uuid, storage.TaskUpdate{Status: domain.StatusRunning, ExpectedStatus: domain.StatusPending})
+			_ = s.Update(ctx, domain.DefaultNamespace, uuid, storage.TaskUpdate{Status: domain.StatusCompleted, ExpectedStatus: domain.StatusRunning})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = s.Update(ctx, domain.DefaultNamespace, uuid, storage.TaskUpdate{Status: domain.StatusCanceled})
+		}()
+	}
+	wg.Wait()
+
+	last := make(map[string]events.Event)
+	for _, event := range publisher.events {
+		prev, ok := last[event.TaskUUID]
+		if ok && event.Version <= prev.Version {
+			t.Errorf("task %s: event %s at version %d published after %s at version %d",
+				event.TaskUUID, event.Status, event.Version, prev.Status, prev.Version)
+		}
+		last[event.TaskUUID] = event
+	}
+
+	for uuid, event := range last {
+		task, err := s.Get(ctx, domain.DefaultNamespace, uuid)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if event.Status != task.Status || event.Version != task.Version {
+			t.Errorf("task %s: last event is %s at version %d, but the task is %s at version %d",
+				uuid, event.Status, event.Version, task.Status, task.Version)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package traced
+
+// Package traced wraps a task storage to record a span for every call.
+
+import (
+	"context"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/storage"
+	"github.com/passwordhash/task-manager-api/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/passwordhash/task-manager-api/internal/storage")
+
+type taskStorage struct {
+	next storage.Task
+}
+
+// NewTaskStorage returns a storage that records a span
+// for every call and passes it on to next.
+func NewTaskStorage(next storage.Task) storage.Task {
+	return &taskStorage{next: next}
+}
+
+func (t *taskStorage) Save(ctx context.Context, task domain.Task) (err error) {
+	ctx, span := start(ctx, "storage.Save", task.Namespace, attribute.String("task.uuid", task.UUID))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Save(ctx, task)
+}
+
+func (t *taskStorage) Get(ctx context.Context, namespace string, uuid string) (_ domain.Task, err error) {
+	ctx, span := start(ctx, "storage.Get", namespace, attribute.String("task.uuid", uuid))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Get(ctx, namespace, uuid)
+}
+
+func (t *taskStorage) GetAll(ctx context.Context, namespace string, selector domain.Selector) (_ []domain.Task, err error) {
+	ctx, span := start(ctx, "storage.GetAll", namespace, attribute.String("task.selector", selector.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetAll(ctx, namespace, selector)
+}
+
+func (t *taskStorage) Count(ctx context.Context, namespace string) (_ int, err error) {
+	ctx, span := start(ctx, "storage.Count", namespace)
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Count(ctx, namespace)
+}
+
+func (t *taskStorage) Update(ctx context.Context, namespace string, uuid string, update storage.TaskUpdate) (err error) {
+	ctx, span := start(ctx, "storage.Update", namespace,
+		attribute.String("task.uuid", uuid),
+		attribute.String("task.status", string(update.Status)),
+		attribute.String("task.expected_status", string(update.ExpectedStatus)),
+		attribute.Int64("task.expected_version", int64(update.ExpectedVersion)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Update(ctx, namespace, uuid, update)
+}
+
+func (t *taskStorage) Delete(ctx context.Context, namespace string, uuid string, version uint64) (err error) {
+	ctx, span := start(ctx, "storage.Delete", namespace, attribute.String("task.uuid", uuid))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Delete(ctx, namespace, uuid, version)
+}
+
+func start(ctx context.Context, name string, namespace string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, attribute.String("task.namespace", namespace))...),
+	)
+}
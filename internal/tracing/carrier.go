@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Inject returns the trace context of ctx in a form that can be
+// stored on a task, nil if ctx has no span.
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns the span context stored by [Inject].
+func Extract(carrier map[string]string) trace.SpanContext {
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(carrier))
+	return trace.SpanContextFromContext(ctx)
+}
+
+// Fail records err on the span and marks the span as failed.
+func Fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		Fail(span, err)
+	}
+	span.End()
+}
@@ -0,0 +1,119 @@
+package tracing
+
+// Package tracing configures OpenTelemetry tracing of the application
+// and helps to carry trace context on tasks executed asynchronously.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters supported by [Setup].
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// ErrUnknownExporter is returned by [Setup] for an unsupported exporter.
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+// Options configures the tracer provider.
+type Options struct {
+	ServiceName string
+	// Exporter is one of ExporterOTLP, ExporterStdout and ExporterFile.
+	Exporter string
+	// Endpoint is the host:port of the OTLP/HTTP collector,
+	// the OTEL_EXPORTER_OTLP_* environment is used if empty.
+	Endpoint string
+	Insecure bool
+	// File is the path spans are appended to by ExporterFile.
+	File string
+	// SampleRatio is the fraction of new traces that are sampled.
+	// Spans of sampled remote parents are always sampled.
+	SampleRatio float64
+}
+
+// Setup installs a global tracer provider exporting spans as configured
+// and the W3C trace context propagator. The returned function flushes
+// pending spans and releases the exporter.
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	const op = "tracing.Setup"
+
+	exporter, closer, err := newExporter(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, io.Closer, error) {
+	switch opts.Exporter {
+	case ExporterOTLP:
+		var httpOpts []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(ctx, httpOpts...)
+		return exporter, nil, err
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, nil, err
+	case ExporterFile:
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return exporter, f, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownExporter, opts.Exporter)
+	}
+}
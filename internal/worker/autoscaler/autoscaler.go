@@ -0,0 +1,201 @@
+package autoscaler
+
+// Package autoscaler implements a controller that periodically adjusts
+// the number of workers of a pool between configured bounds, based on
+// the observed queue wait time and backlog length.
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+type Options struct {
+	MinWorkers int
+	MaxWorkers int
+	// Interval is the period between two decisions.
+	Interval time.Duration
+	// TargetQueueWait is the average queue wait above which the pool grows.
+	// The pool shrinks only when the wait is below half of it.
+	TargetQueueWait time.Duration
+	// MaxBacklog is the queue length above which the pool grows.
+	MaxBacklog int
+	// Step is the number of workers added or removed per decision.
+	Step int
+	// ScaleUpCooldown and ScaleDownCooldown are the minimum periods
+	// since the last change before the pool can grow or shrink again.
+	ScaleUpCooldown   time.Duration
+	ScaleDownCooldown time.Duration
+}
+
+// State is a snapshot of the autoscaler decisions.
+type State struct {
+	MinWorkers int
+	MaxWorkers int
+	// Target is the number of workers the autoscaler last asked for.
+	Target int
+	// QueueWait is the average queue wait observed during the last interval.
+	QueueWait  time.Duration
+	ScaleUps   int
+	ScaleDowns int
+}
+
+type Autoscaler struct {
+	log  *slog.Logger
+	pool worker.TaskPool
+	opts Options
+
+	mu         sync.Mutex
+	state      State
+	prev       worker.PoolState
+	lastChange time.Time
+}
+
+func New(
+	log *slog.Logger,
+	pool worker.TaskPool,
+	opts Options,
+) *Autoscaler {
+	if opts.Step < 1 {
+		opts.Step = 1
+	}
+
+	return &Autoscaler{
+		log:  log,
+		pool: pool,
+		opts: opts,
+		state: State{
+			MinWorkers: opts.MinWorkers,
+			MaxWorkers: opts.MaxWorkers,
+		},
+	}
+}
+
+// Run makes a decision every [Options.Interval] until ctx is done.
+func (a *Autoscaler) Run(ctx context.Context) {
+	const op = "autoscaler.Run"
+
+	log := a.log.With(slog.String("op", op))
+
+	log.Info("Autoscaler started",
+		slog.Int("min_workers", a.opts.MinWorkers),
+		slog.Int("max_workers", a.opts.MaxWorkers),
+		slog.Duration("interval", a.opts.Interval),
+	)
+
+	a.mu.Lock()
+	a.prev = a.pool.State()
+	a.state.Target = a.prev.Workers
+	a.mu.Unlock()
+
+	ticker := time.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Autoscaler stopped")
+			return
+		case now := <-ticker.C:
+			a.tick(now)
+		}
+	}
+}
+
+func (a *Autoscaler) State() State {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.state
+}
+
+func (a *Autoscaler) tick(now time.Time) {
+	const op = "autoscaler.tick"
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cur := a.pool.State()
+	wait := a.observedWait(cur)
+	a.prev = cur
+	a.state.QueueWait = wait
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int("workers", cur.Workers),
+		slog.Int("busy", cur.Busy),
+		slog.Int("queued", cur.Queued),
+		slog.Duration("queue_wait", wait),
+	)
+
+	target, reason := a.decide(now, cur, wait)
+	if target == cur.Workers {
+		log.Debug("Keeping pool size", slog.String("reason", reason))
+		return
+	}
+
+	if err := a.pool.Resize(target); err != nil {
+		log.Error("Failed to resize pool", slog.Any("error", err))
+		return
+	}
+
+	if target > cur.Workers {
+		a.state.ScaleUps++
+	} else {
+		a.state.ScaleDowns++
+	}
+	a.state.Target = target
+	a.lastChange = now
+
+	log.Info("Pool resized by autoscaler", slog.Int("target", target), slog.String("reason", reason))
+}
+
+// observedWait returns the average queue wait of tasks dequeued since
+// the previous tick. If nothing was dequeued while tasks kept waiting
+// in the queue, they waited for at least the whole interval.
+func (a *Autoscaler) observedWait(cur worker.PoolState) time.Duration {
+	dequeued := cur.Dequeued - a.prev.Dequeued
+	if dequeued > 0 {
+		return (cur.QueueWaitTotal - a.prev.QueueWaitTotal) / time.Duration(dequeued)
+	}
+	if cur.Queued > 0 && a.prev.Queued > 0 {
+		return a.opts.Interval
+	}
+	return 0
+}
+
+// decide returns the desired number of workers and the reason for it.
+func (a *Autoscaler) decide(now time.Time, cur worker.PoolState, wait time.Duration) (int, string) {
+	workers := cur.Workers
+	sinceChange := now.Sub(a.lastChange)
+
+	switch {
+	case workers < a.opts.MinWorkers:
+		return a.opts.MinWorkers, "below minimum"
+	case workers > a.opts.MaxWorkers:
+		return a.opts.MaxWorkers, "above maximum"
+	case cur.Paused:
+		return workers, "pool is paused"
+	case wait > a.opts.TargetQueueWait || cur.Queued > a.opts.MaxBacklog:
+		if workers == a.opts.MaxWorkers {
+			return workers, "at maximum"
+		}
+		if sinceChange < a.opts.ScaleUpCooldown {
+			return workers, "scale up cooldown"
+		}
+		return min(a.opts.MaxWorkers, workers+a.opts.Step), "queue is congested"
+	case cur.Queued == 0 && wait <= a.opts.TargetQueueWait/2 && cur.Busy < workers:
+		if workers == a.opts.MinWorkers {
+			return workers, "at minimum"
+		}
+		if sinceChange < a.opts.ScaleDownCooldown {
+			return workers, "scale down cooldown"
+		}
+		return max(a.opts.MinWorkers, cur.Busy, workers-a.opts.Step), "workers are idle"
+	default:
+		return workers, "within target"
+	}
+}
@@ -0,0 +1,199 @@
+package autoscaler
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+// fakePool reports a fixed state and records resizes.
+type fakePool struct {
+	worker.TaskPool
+
+	state   worker.PoolState
+	resizes []int
+}
+
+func (p *fakePool) State() worker.PoolState {
+	return p.state
+}
+
+func (p *fakePool) Resize(workers int) error {
+	p.resizes = append(p.resizes, workers)
+	p.state.Workers = workers
+	return nil
+}
+
+var testOptions = Options{
+	MinWorkers:        2,
+	MaxWorkers:        6,
+	Interval:          time.Second,
+	TargetQueueWait:   time.Second,
+	MaxBacklog:        10,
+	Step:              2,
+	ScaleUpCooldown:   10 * time.Second,
+	ScaleDownCooldown: time.Minute,
+}
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name  string
+		state worker.PoolState
+		wait  time.Duration
+		// sinceChange is the time since the last resize.
+		sinceChange time.Duration
+		want        int
+	}{
+		{
+			name:        "below minimum",
+			state:       worker.PoolState{Workers: 1},
+			sinceChange: time.Hour,
+			want:        2,
+		},
+		{
+			name:        "above maximum",
+			state:       worker.PoolState{Workers: 8, Queued: 100},
+			sinceChange: time.Hour,
+			want:        6,
+		},
+		{
+			name:        "paused",
+			state:       worker.PoolState{Workers: 3, Paused: true, Queued: 100},
+			sinceChange: time.Hour,
+			want:        3,
+		},
+		{
+			name:        "long queue wait",
+			state:       worker.PoolState{Workers: 3, Busy: 3, Queued: 1},
+			wait:        2 * time.Second,
+			sinceChange: time.Hour,
+			want:        5,
+		},
+		{
+			name:        "long backlog",
+			state:       worker.PoolState{Workers: 3, Busy: 3, Queued: 11},
+			sinceChange: time.Hour,
+			want:        5,
+		},
+		{
+			name:        "scale up is capped by maximum",
+			state:       worker.PoolState{Workers: 5, Busy: 5, Queued: 11},
+			sinceChange: time.Hour,
+			want:        6,
+		},
+		{
+			name:        "congested at maximum",
+			state:       worker.PoolState{Workers: 6, Busy: 6, Queued: 11},
+			sinceChange: time.Hour,
+			want:        6,
+		},
+		{
+			name:        "scale up cooldown",
+			state:       worker.PoolState{Workers: 3, Busy: 3, Queued: 11},
+			sinceChange: 5 * time.Second,
+			want:        3,
+		},
+		{
+			name:        "idle",
+			state:       worker.PoolState{Workers: 6},
+			sinceChange: time.Hour,
+			want:        4,
+		},
+		{
+			name:        "scale down keeps busy workers",
+			state:       worker.PoolState{Workers: 6, Busy: 5},
+			sinceChange: time.Hour,
+			want:        5,
+		},
+		{
+			name:        "scale down is capped by minimum",
+			state:       worker.PoolState{Workers: 3},
+			sinceChange: time.Hour,
+			want:        2,
+		},
+		{
+			name:        "idle at minimum",
+			state:       worker.PoolState{Workers: 2},
+			sinceChange: time.Hour,
+			want:        2,
+		},
+		{
+			name:        "scale down cooldown",
+			state:       worker.PoolState{Workers: 6},
+			sinceChange: 30 * time.Second,
+			want:        6,
+		},
+		{
+			name:        "within target",
+			state:       worker.PoolState{Workers: 4, Busy: 4},
+			wait:        700 * time.Millisecond,
+			sinceChange: time.Hour,
+			want:        4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New(slog.New(slog.DiscardHandler), &fakePool{}, testOptions)
+
+			now := time.Now()
+			a.lastChange = now.Add(-tt.sinceChange)
+
+			got, reason := a.decide(now, tt.state, tt.wait)
+			if got != tt.want {
+				t.Errorf("decide() = %d (%s), want %d", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestTickResizesPool(t *testing.T) {
+	pool := &fakePool{state: worker.PoolState{Workers: 2, Busy: 2, Queued: 20}}
+	a := New(slog.New(slog.DiscardHandler), pool, testOptions)
+
+	now := time.Now()
+	a.tick(now)
+	if got := pool.state.Workers; got != 4 {
+		t.Fatalf("workers after congested tick = %d, want 4", got)
+	}
+
+	// The scale up cooldown has not passed since the last resize.
+	a.tick(now.Add(testOptions.Interval))
+	if got := pool.state.Workers; got != 4 {
+		t.Fatalf("workers during cooldown = %d, want 4", got)
+	}
+
+	a.tick(now.Add(testOptions.ScaleUpCooldown))
+	if got := pool.state.Workers; got != 6 {
+		t.Fatalf("workers after cooldown = %d, want 6", got)
+	}
+
+	pool.state.Busy = 0
+	pool.state.Queued = 0
+	a.tick(now.Add(testOptions.ScaleUpCooldown + testOptions.ScaleDownCooldown))
+	if got := pool.state.Workers; got != 4 {
+		t.Fatalf("workers after idle tick = %d, want 4", got)
+	}
+
+	state := a.State()
+	if state.ScaleUps != 2 || state.ScaleDowns != 1 || state.Target != 4 {
+		t.Errorf("state = %+v, want 2 scale ups, 1 scale down and target 4", state)
+	}
+}
+
+func TestObservedWait(t *testing.T) {
+	a := New(slog.New(slog.DiscardHandler), &fakePool{}, testOptions)
+	a.prev = worker.PoolState{Queued: 3, Dequeued: 10, QueueWaitTotal: 10 * time.Second}
+
+	cur := worker.PoolState{Queued: 3, Dequeued: 14, QueueWaitTotal: 18 * time.Second}
+	if got := a.observedWait(cur); got != 2*time.Second {
+		t.Errorf("observedWait with dequeued tasks = %v, want 2s", got)
+	}
+
+	cur = worker.PoolState{Queued: 3, Dequeued: 10, QueueWaitTotal: 10 * time.Second}
+	if got := a.observedWait(cur); got != testOptions.Interval {
+		t.Errorf("observedWait with a stuck queue = %v, want the interval", got)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/events"
 	"github.com/passwordhash/task-manager-api/internal/worker"
 )
 
@@ -19,35 +20,66 @@ func init() {
 	flag.DurationVar(&ioDuration, "io-duration", 10*time.Second, "Duration to simulate I/O operation in the executor")
 }
 
+// progressSteps is the number of progress events of a simulated operation.
+const progressSteps = 10
+
 type simulateIOExecutor struct {
-	log *slog.Logger
+	log       *slog.Logger
+	publisher events.Publisher
 }
 
-func New() *simulateIOExecutor {
-	return &simulateIOExecutor{}
+// New returns the executor. It reports the progress and log lines
+// of the simulated operations to publisher.
+func New(publisher events.Publisher) *simulateIOExecutor {
+	return &simulateIOExecutor{publisher: publisher}
 }
 
 func (e *simulateIOExecutor) Execute(ctx context.Context, task *domain.Task) (*worker.ExecuteResult, error) {
 	var execRes worker.ExecuteResult
 
-	done := make(chan struct{})
-	go func() {
-		time.Sleep(ioDuration)
-		result := map[string]any{
-			"message":  "I/O operation completed",
-			"bytes":    1024,
-			"duration": ioDuration.String(),
-			"task":     task.UUID,
+	e.logLine(task, "I/O operation started")
+
+	// A ticker panics on a non-positive interval.
+	ticker := time.NewTicker(max(ioDuration/progressSteps, time.Nanosecond))
+	defer ticker.Stop()
+
+	for step := 1; step <= progressSteps; step++ {
+		select {
+		case <-ctx.Done():
+			e.logLine(task, "I/O operation interrupted: "+ctx.Err().Error())
+			return &execRes, ctx.Err()
+		case <-ticker.C:
 		}
-		execRes.FinishedAt = time.Now()
-		execRes.Result = result
-		close(done)
-	}()
-
-	select {
-	case <-ctx.Done():
-		return &execRes, ctx.Err()
-	case <-done:
-		return &execRes, nil
+
+		e.publisher.Publish(events.Event{
+			Type:      events.TypeProgress,
+			Namespace: task.Namespace,
+			TaskUUID:  task.UUID,
+			Time:      time.Now(),
+			Progress:  step * 100 / progressSteps,
+		})
+	}
+
+	execRes.FinishedAt = time.Now()
+	execRes.Result = map[string]any{
+		"message":  "I/O operation completed",
+		"bytes":    1024,
+		"duration": ioDuration.String(),
+		"task":     task.UUID,
+		"type":     task.Type,
 	}
+
+	e.logLine(task, "I/O operation completed")
+
+	return &execRes, nil
+}
+
+func (e *simulateIOExecutor) logLine(task *domain.Task, message string) {
+	e.publisher.Publish(events.Event{
+		Type:      events.TypeLog,
+		Namespace: task.Namespace,
+		TaskUUID:  task.UUID,
+		Time:      time.Now(),
+		Message:   message,
+	})
 }
@@ -2,11 +2,25 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/passwordhash/task-manager-api/internal/domain"
 )
 
+var (
+	// ErrInvalidSize is returned when a pool is resized to less than one worker.
+	ErrInvalidSize = errors.New("pool size must be positive")
+
+	// ErrTenantQueueFull is returned by Submit when the tenant of the task
+	// already has the maximum number of tasks waiting in the queue.
+	ErrTenantQueueFull = errors.New("tenant queue limit exceeded")
+
+	// ErrTenantRunningFull is returned by Submit when the tenant of the task
+	// already runs the maximum number of tasks.
+	ErrTenantRunningFull = errors.New("tenant running limit exceeded")
+)
+
 // TaskPool defines the interface for a pool of workers
 // that can execute tasks concurrently.
 type TaskPool interface {
@@ -14,14 +28,90 @@ type TaskPool interface {
 	Start(ctx context.Context)
 
 	// Submit adds a task to the pool for execution.
+	// Returns [ErrTenantQueueFull] if the tenant of the task
+	// has too many queued tasks or [ErrTenantRunningFull]
+	// if it runs too many tasks.
 	Submit(ctx context.Context, task *domain.Task) error
 
 	// Cancel stops a specific task by its ID.
 	Cancel(ctx context.Context, taskID string) error
 
+	// SetPriority moves a queued task to its place for the new priority,
+	// see [domain.Task.Priority]. It reports whether the task was queued.
+	SetPriority(taskID string, priority int) bool
+
+	// SetRunAt holds a queued task in the queue until runAt,
+	// see [domain.Task.RunAt]. It reports whether the task was queued.
+	SetRunAt(taskID string, runAt time.Time) bool
+
 	// Stop gracefully stops the pool pool, waiting for all tasks to complete
 	// or the context to be done.
 	Stop(ctx context.Context) error
+
+	// Pause stops workers from taking new tasks from the queue.
+	// Tasks that are already running are not affected and
+	// new tasks are still accepted by Submit. Pausing a paused pool is a no-op.
+	Pause()
+
+	// Resume lets workers take tasks from the queue again.
+	// Resuming a running pool is a no-op.
+	Resume()
+
+	// Resize changes the number of workers. New workers start immediately,
+	// surplus workers exit after finishing their current task.
+	// Returns [ErrInvalidSize] if workers is less than one.
+	Resize(workers int) error
+
+	// State returns a snapshot of the pool state.
+	State() PoolState
+}
+
+// PoolState is a snapshot of the [TaskPool] state.
+type PoolState struct {
+	Paused  bool
+	Workers int
+	Busy    int
+	Queued  int
+	// Resizes is the number of times the pool was resized since creation.
+	Resizes int
+
+	// Dequeued is the number of tasks taken from the queue by workers
+	// and QueueWaitTotal is the total time they spent waiting in it.
+	// Both only grow, so the average wait over a period is the ratio
+	// of their increments.
+	Dequeued       uint64
+	QueueWaitTotal time.Duration
+
+	// TypeLimits is the maximum number of concurrently running tasks
+	// per task type, types without a limit are omitted.
+	TypeLimits map[string]int
+	// RunningByType is the number of running tasks per task type.
+	RunningByType map[string]int
+
+	// QueuedByTenant and RunningByTenant are the numbers of queued and
+	// running tasks per tenant, tenants without tasks are omitted.
+	QueuedByTenant  map[string]int
+	RunningByTenant map[string]int
+
+	// RunningByNamespace is the number of running tasks per namespace,
+	// namespaces without running tasks are omitted.
+	RunningByNamespace map[string]int
+}
+
+// DefaultGroup is the name of the worker group that executes tasks
+// of types not routed to any other group.
+const DefaultGroup = "default"
+
+// PoolGroups routes tasks to named worker pools by task type.
+type PoolGroups interface {
+	// ForType returns the pool that executes tasks of the given type.
+	ForType(taskType string) TaskPool
+
+	// Get returns the pool of the group with the given name.
+	Get(name string) (pool TaskPool, ok bool)
+
+	// Names returns names of all groups, [DefaultGroup] first.
+	Names() []string
 }
 
 type ExecuteResult struct {
@@ -0,0 +1,73 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+var (
+	ErrDuplicateGroup = errors.New("duplicate worker group")
+	ErrDuplicateRoute = errors.New("task type is routed to several worker groups")
+)
+
+// Group is a named worker pool dedicated to the listed task types.
+type Group struct {
+	Name  string
+	Pool  worker.TaskPool
+	Types []string
+}
+
+type groups struct {
+	names  []string
+	pools  map[string]worker.TaskPool
+	byType map[string]string
+}
+
+// NewGroups returns [worker.PoolGroups] that routes tasks of the listed
+// types to their dedicated groups and all other tasks to defaultPool.
+// It returns [ErrDuplicateGroup] if two groups share a name and
+// [ErrDuplicateRoute] if a task type is listed in several groups.
+func NewGroups(defaultPool worker.TaskPool, dedicated ...Group) (worker.PoolGroups, error) {
+	const op = "pool.NewGroups"
+
+	g := &groups{
+		names:  []string{worker.DefaultGroup},
+		pools:  map[string]worker.TaskPool{worker.DefaultGroup: defaultPool},
+		byType: make(map[string]string),
+	}
+
+	for _, group := range dedicated {
+		if _, exists := g.pools[group.Name]; exists {
+			return nil, fmt.Errorf("%s: %q: %w", op, group.Name, ErrDuplicateGroup)
+		}
+		g.names = append(g.names, group.Name)
+		g.pools[group.Name] = group.Pool
+
+		for _, taskType := range group.Types {
+			if other, exists := g.byType[taskType]; exists {
+				return nil, fmt.Errorf("%s: %q in %q and %q: %w", op, taskType, other, group.Name, ErrDuplicateRoute)
+			}
+			g.byType[taskType] = group.Name
+		}
+	}
+
+	return g, nil
+}
+
+func (g *groups) ForType(taskType string) worker.TaskPool {
+	if name, ok := g.byType[taskType]; ok {
+		return g.pools[name]
+	}
+	return g.pools[worker.DefaultGroup]
+}
+
+func (g *groups) Get(name string) (worker.TaskPool, bool) {
+	p, ok := g.pools[name]
+	return p, ok
+}
+
+func (g *groups) Names() []string {
+	return append([]string(nil), g.names...)
+}
@@ -0,0 +1,75 @@
+package pool
+
+// Limits configures how queued tasks of a pool are scheduled.
+type Limits struct {
+	// Types is the maximum number of concurrently running tasks
+	// per task type. Types without a limit are not restricted.
+	Types map[string]int
+
+	// Tenants configures fair sharing of the pool between tenants.
+	Tenants TenantLimits
+
+	// Namespaces limits the number of concurrently running tasks
+	// per namespace.
+	Namespaces NamespaceLimits
+}
+
+// TenantLimit is the scheduling weight and the limits of a tenant.
+// Zero limits mean no limit. Submit rejects a task of a tenant with
+// MaxQueued tasks in the queue or MaxRunning running tasks. Tasks queued
+// before the tenant reached MaxRunning wait in the queue until a running
+// one finishes.
+type TenantLimit struct {
+	// Weight is the share of the pool the tenant gets relative to
+	// other tenants with queued tasks.
+	Weight     int
+	MaxQueued  int
+	MaxRunning int
+}
+
+// TenantLimits holds limits applied to every tenant and
+// per-tenant overrides of them.
+type TenantLimits struct {
+	Default   TenantLimit
+	Overrides map[string]TenantLimit
+}
+
+// For returns the limits of the tenant. Zero fields of an override
+// fall back to the default ones.
+func (l TenantLimits) For(tenant string) TenantLimit {
+	limit := l.Default
+
+	if override, ok := l.Overrides[tenant]; ok {
+		if override.Weight > 0 {
+			limit.Weight = override.Weight
+		}
+		if override.MaxQueued > 0 {
+			limit.MaxQueued = override.MaxQueued
+		}
+		if override.MaxRunning > 0 {
+			limit.MaxRunning = override.MaxRunning
+		}
+	}
+
+	if limit.Weight < 1 {
+		limit.Weight = 1
+	}
+
+	return limit
+}
+
+// NamespaceLimits holds the maximum number of concurrently running
+// tasks applied to every namespace and per-namespace overrides of it.
+// Zero means no limit.
+type NamespaceLimits struct {
+	MaxRunning int
+	Overrides  map[string]int
+}
+
+// For returns the maximum number of running tasks of the namespace.
+func (l NamespaceLimits) For(namespace string) int {
+	if limit, ok := l.Overrides[namespace]; ok && limit > 0 {
+		return limit
+	}
+	return l.MaxRunning
+}
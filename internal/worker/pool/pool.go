@@ -9,46 +9,96 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/passwordhash/task-manager-api/internal/domain"
 	"github.com/passwordhash/task-manager-api/internal/storage"
+	"github.com/passwordhash/task-manager-api/internal/tracing"
 	"github.com/passwordhash/task-manager-api/internal/worker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/passwordhash/task-manager-api/internal/worker/pool")
+
 type taskWrapper struct {
-	task *domain.Task
-	ctx  context.Context
+	task       *domain.Task
+	ctx        context.Context
+	enqueuedAt time.Time
+
+	// priority and seq order the queue of the tenant of the task,
+	// seq is the order of push. runAt holds the task in the queue until
+	// then. They are guarded by the mutex of the queue.
+	priority int
+	seq      uint64
+	runAt    time.Time
 }
 
 type pool struct {
 	log       *slog.Logger
 	wg        sync.WaitGroup
-	workers   int
-	taskQueue chan *taskWrapper
+	taskQueue *queue
 
 	executor    worker.TaskExecutor
 	taskStorage storage.Task
 
 	mu         sync.Mutex
 	cancelFunc map[string]context.CancelFunc
+
+	busy atomic.Int64
+
+	// dequeued and waitTotal accumulate the number of tasks taken
+	// from the queue and the total time they spent waiting in it.
+	dequeued  atomic.Uint64
+	waitTotal atomic.Int64
+
+	// stateMu guards the pause state and the set of workers.
+	// While the pool is running pauseCh is open and resumeCh is closed,
+	// while it is paused it is the other way around,
+	// so workers can wait for the next transition in a select.
+	stateMu  sync.Mutex
+	paused   bool
+	pauseCh  chan struct{}
+	resumeCh chan struct{}
+	stopCh   chan struct{}
+
+	// workers is the desired number of workers. Each running worker
+	// has a quit channel in quitCh, closing it makes the worker exit
+	// after it finishes its current task.
+	workers  int
+	nextID   int
+	quitCh   map[int]chan struct{}
+	startCtx context.Context
+	resizes  int
 }
 
 func New(
-    log *slog.Logger,
-    workers int,
-    queueSize int,
-    executor worker.TaskExecutor,
-    taskStorage storage.Task,
+	log *slog.Logger,
+	workers int,
+	queueSize int,
+	limits Limits,
+	executor worker.TaskExecutor,
+	taskStorage storage.Task,
 ) worker.TaskPool {
+	resumeCh := make(chan struct{})
+	close(resumeCh)
+
 	return &pool{
 		log:         log,
 		workers:     workers,
-		taskQueue:   make(chan *taskWrapper, queueSize),
+		taskQueue:   newQueue(queueSize, limits),
 		executor:    executor,
 		taskStorage: taskStorage,
 		cancelFunc:  make(map[string]context.CancelFunc),
+		pauseCh:     make(chan struct{}),
+		resumeCh:    resumeCh,
+		stopCh:      make(chan struct{}),
+		quitCh:      make(map[int]chan struct{}),
 	}
 }
 
@@ -57,13 +107,71 @@ func (p *pool) Start(ctx context.Context) {
 
 	log := p.log.With(slog.String("op", op))
 
-	for i := 0; i < p.workers; i++ {
-		i := i // but we use go 1.24))
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	p.startCtx = ctx
+	p.spawn(p.workers)
+
+	log.Info("Worker pool started", slog.Int("workers", p.workers))
+}
+
+func (p *pool) Resize(workers int) error {
+	const op = "pool.Resize"
+
+	log := p.log.With(slog.String("op", op))
+
+	if workers < 1 {
+		return fmt.Errorf("%s: %w", op, worker.ErrInvalidSize)
+	}
+
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	from := p.workers
+	if from == workers {
+		return nil
+	}
+
+	// Before Start only the desired number of workers is changed.
+	if p.startCtx != nil {
+		if workers > from {
+			p.spawn(workers - from)
+		} else {
+			p.retire(from - workers)
+		}
+	}
+
+	p.workers = workers
+	p.resizes++
+
+	log.Info("Worker pool resized", slog.Int("from", from), slog.Int("to", workers))
+
+	return nil
+}
+
+// spawn starts n new workers. Must be called with stateMu held.
+func (p *pool) spawn(n int) {
+	for range n {
+		id := p.nextID
+		p.nextID++
+
+		quit := make(chan struct{})
+		p.quitCh[id] = quit
+
 		p.wg.Add(1)
-		go p.worker(ctx, i)
+		go p.worker(p.startCtx, id, quit)
 	}
+}
 
-	log.Info("Worker pool started", slog.Int("workers", p.workers))
+// retire signals n most recently started workers to exit once their
+// current task is finished. Must be called with stateMu held.
+func (p *pool) retire(n int) {
+	ids := slices.Sorted(maps.Keys(p.quitCh))
+	for _, id := range ids[len(ids)-n:] {
+		close(p.quitCh[id])
+		delete(p.quitCh, id)
+	}
 }
 
 func (p *pool) Submit(ctx context.Context, task *domain.Task) error {
@@ -81,18 +189,26 @@ func (p *pool) Submit(ctx context.Context, task *domain.Task) error {
 	p.mu.Unlock()
 
 	tw := &taskWrapper{
-		task: task,
-		ctx:  taskCtx,
+		task:       task,
+		ctx:        taskCtx,
+		enqueuedAt: time.Now(),
+		priority:   task.Priority,
+		runAt:      task.RunAt,
 	}
 
-	select {
-	case p.taskQueue <- tw:
-		log.Debug("Task submitted to the queue")
-		return nil
-	case <-ctx.Done():
-		log.Error("Failed to submit task to the queue", slog.String("error", ctx.Err().Error()))
-		return ctx.Err()
+	if err := p.taskQueue.push(ctx, tw); err != nil {
+		log.Error("Failed to submit task to the queue", slog.String("error", err.Error()))
+
+		p.mu.Lock()
+		delete(p.cancelFunc, task.UUID)
+		p.mu.Unlock()
+		taskCancel()
+
+		return fmt.Errorf("%s: %w", op, err)
 	}
+
+	log.Debug("Task submitted to the queue")
+	return nil
 }
 
 func (p *pool) Cancel(_ context.Context, taskUUID string) error {
@@ -117,12 +233,39 @@ func (p *pool) Cancel(_ context.Context, taskUUID string) error {
 	return nil
 }
 
+func (p *pool) SetPriority(taskUUID string, priority int) bool {
+	moved := p.taskQueue.setPriority(taskUUID, priority)
+
+	p.log.Debug("Task priority changed",
+		slog.String("op", "pool.SetPriority"),
+		slog.String("task_uuid", taskUUID),
+		slog.Int("priority", priority),
+		slog.Bool("queued", moved),
+	)
+
+	return moved
+}
+
+func (p *pool) SetRunAt(taskUUID string, runAt time.Time) bool {
+	moved := p.taskQueue.setRunAt(taskUUID, runAt)
+
+	p.log.Debug("Task start time changed",
+		slog.String("op", "pool.SetRunAt"),
+		slog.String("task_uuid", taskUUID),
+		slog.Time("run_at", runAt),
+		slog.Bool("queued", moved),
+	)
+
+	return moved
+}
+
 func (p *pool) Stop(ctx context.Context) error {
 	const op = "pool.Stop"
 
 	log := p.log.With(slog.String("op", op))
 
-	close(p.taskQueue)
+	close(p.stopCh)
+	p.taskQueue.close()
 
 	done := make(chan struct{})
 	go func() {
@@ -140,7 +283,76 @@ func (p *pool) Stop(ctx context.Context) error {
 	}
 }
 
-func (p *pool) worker(ctx context.Context, id int) {
+func (p *pool) Pause() {
+	const op = "pool.Pause"
+
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	if p.paused {
+		return
+	}
+
+	p.paused = true
+	p.resumeCh = make(chan struct{})
+	close(p.pauseCh)
+
+	p.log.Info("Worker pool paused", slog.String("op", op))
+}
+
+func (p *pool) Resume() {
+	const op = "pool.Resume"
+
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	if !p.paused {
+		return
+	}
+
+	p.paused = false
+	p.pauseCh = make(chan struct{})
+	close(p.resumeCh)
+
+	p.log.Info("Worker pool resumed", slog.String("op", op))
+}
+
+func (p *pool) State() worker.PoolState {
+	queuedByTenant, runningByTenant := p.taskQueue.tenantStats()
+
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	return worker.PoolState{
+		Paused:  p.paused,
+		Workers: p.workers,
+		Busy:    int(p.busy.Load()),
+		Queued:  p.taskQueue.len(),
+		Resizes: p.resizes,
+
+		Dequeued:       p.dequeued.Load(),
+		QueueWaitTotal: time.Duration(p.waitTotal.Load()),
+
+		TypeLimits:    maps.Clone(p.taskQueue.limits.Types),
+		RunningByType: p.taskQueue.runningByType(),
+
+		QueuedByTenant:  queuedByTenant,
+		RunningByTenant: runningByTenant,
+
+		RunningByNamespace: p.taskQueue.runningByNamespace(),
+	}
+}
+
+// gate returns the current pause state with the channels
+// that are closed on the next pause and resume respectively.
+func (p *pool) gate() (paused bool, pauseCh, resumeCh <-chan struct{}) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	return p.paused, p.pauseCh, p.resumeCh
+}
+
+func (p *pool) worker(ctx context.Context, id int, quit <-chan struct{}) {
 	defer p.wg.Done()
 
 	const op = "pool.worker"
@@ -151,55 +363,161 @@ func (p *pool) worker(ctx context.Context, id int) {
 
 	for {
 		select {
-		case tw, ok := <-p.taskQueue:
-			if !ok {
-				log.Debug("Task queue closed, stopping pool")
+		case <-quit:
+			log.Debug("Worker retired")
+			return
+		default:
+		}
+
+		paused, pauseCh, resumeCh := p.gate()
+		if paused {
+			log.Debug("Worker paused")
+			select {
+			case <-resumeCh:
+				log.Debug("Worker resumed")
+				continue
+			case <-quit:
+				log.Debug("Worker retired")
+				return
+			case <-p.stopCh:
+				log.Debug("Pool stopped while paused, stopping worker")
+				return
+			case <-ctx.Done():
+				log.Debug("Worker stopped")
 				return
 			}
+		}
 
-			wlog := log.With(slog.String("task_uuid", tw.task.UUID))
+		tw, changed, ok := p.taskQueue.pop()
+		if !ok {
+			log.Debug("Task queue closed, stopping pool")
+			return
+		}
+		if tw == nil {
+			select {
+			case <-changed:
+			case <-pauseCh:
+			case <-quit:
+				log.Debug("Worker retired")
+				return
+			case <-ctx.Done():
+				log.Debug("Worker stopped")
+				return
+			}
+			continue
+		}
 
-			wlog.Debug("Received task for execution")
+		p.execute(ctx, log, tw)
+		p.taskQueue.release(tw)
+	}
+}
 
-			if err := p.taskStorage.Update(ctx, tw.task.UUID, storage.TaskUpdate{
-				Status:    domain.StatusRunning,
-				UpdatedAt: time.Now(),
-			}); err != nil {
-				// Maybe we should use some retry mechanism here?
-				wlog.Error("Failed to update task status to running", slog.String("error", err.Error()))
-				continue
-			}
+// execute runs a task taken from the queue and stores its outcome.
+func (p *pool) execute(ctx context.Context, log *slog.Logger, tw *taskWrapper) {
+	wlog := log.With(
+		slog.String("task_uuid", tw.task.UUID),
+		slog.String("namespace", tw.task.Namespace),
+		slog.String("task_type", tw.task.Type),
+		slog.String("tenant", tw.task.Tenant),
+		slog.String("request_id", tw.task.RequestID),
+	)
+
+	dequeuedAt := time.Now()
+	wait := dequeuedAt.Sub(tw.enqueuedAt)
+	p.dequeued.Add(1)
+	p.waitTotal.Add(int64(wait))
+
+	wlog.Debug("Received task for execution", slog.Duration("queue_wait", wait))
+
+	// The request that created the task is over by now. The time spent
+	// in the queue continues its trace, the execution starts a new one
+	// linked to it.
+	attrs := trace.WithAttributes(
+		attribute.String("task.uuid", tw.task.UUID),
+		attribute.String("task.namespace", tw.task.Namespace),
+		attribute.String("task.type", tw.task.Type),
+		attribute.String("task.tenant", tw.task.Tenant),
+	)
+	creator := tracing.Extract(tw.task.TraceContext)
+
+	_, waitSpan := tracer.Start(
+		trace.ContextWithRemoteSpanContext(context.Background(), creator),
+		"pool.queue_wait",
+		trace.WithTimestamp(tw.enqueuedAt),
+		attrs,
+	)
+	waitSpan.End(trace.WithTimestamp(dequeuedAt))
+
+	ctx, span := tracer.Start(ctx, "pool.execute",
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.Link{SpanContext: creator}),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		attrs,
+	)
+	defer span.End()
+	// The task context is canceled by Cancel, the span goes along with it.
+	taskCtx := trace.ContextWithSpan(tw.ctx, span)
+
+	if err := p.taskStorage.Update(ctx, tw.task.Namespace, tw.task.UUID, storage.TaskUpdate{
+		Status:         domain.StatusRunning,
+		ExpectedStatus: domain.StatusPending,
+		UpdatedAt:      time.Now(),
+		Actor:          domain.ActorSystem,
+		Reason:         "taken from the queue by a worker",
+	}); errors.Is(err, storage.ErrConflict) {
+		// The task was canceled while it waited in the queue.
+		wlog.Debug("Task is no longer pending, skipping it", slog.String("error", err.Error()))
+		return
+	} else if err != nil {
+		// Maybe we should use some retry mechanism here?
+		wlog.Error("Failed to update task status to running", slog.String("error", err.Error()))
+		tracing.Fail(span, err)
+		return
+	}
 
-			var status domain.TaskStatus
-			execRes, err := p.executor.Execute(tw.ctx, tw.task)
-			if err != nil && errors.Is(err, context.Canceled) {
-				wlog.Debug("Task execution canceled by context")
-				status = domain.StatusCanceled
-			} else if err != nil && !errors.Is(err, context.Canceled) {
-				wlog.Error("Failed to execute task", slog.String("error", err.Error()))
-				status = domain.StatusFailed
-			} else {
-				wlog.Debug("Task executed successfully")
-				status = domain.StatusCompleted
-			}
+	var status domain.TaskStatus
+	var reason string
+	p.busy.Add(1)
+	execCtx, execSpan := tracer.Start(taskCtx, "executor.Execute")
+	execRes, err := p.executor.Execute(execCtx, tw.task)
+	tracing.End(execSpan, err)
+	p.busy.Add(-1)
+	if err != nil && errors.Is(err, context.Canceled) {
+		wlog.Debug("Task execution canceled by context")
+		status = domain.StatusCanceled
+		reason = "canceled"
+	} else if err != nil && !errors.Is(err, context.Canceled) {
+		wlog.Error("Failed to execute task", slog.String("error", err.Error()))
+		status = domain.StatusFailed
+		reason = err.Error()
+		tracing.Fail(span, err)
+	} else {
+		wlog.Debug("Task executed successfully")
+		status = domain.StatusCompleted
+		reason = "executed successfully"
+	}
 
-			if updateErr := p.taskStorage.Update(
-				tw.ctx,
-				tw.task.UUID,
-				storage.TaskUpdate{
-					Status:    status,
-					UpdatedAt: time.Now(),
-					StartedAt: tw.task.StartedAt,
-					Result:    execRes.Result,
-					Error:     err,
-				}); updateErr != nil {
-				// Maybe we should use some retry mechanism here?
-				wlog.Error("Failed to update task status after execution", slog.String("error", err.Error()))
-				continue
-			}
-		case <-ctx.Done():
-			log.Debug("Worker stopped")
-			return
-		}
+	span.SetAttributes(attribute.String("task.status", string(status)))
+
+	if updateErr := p.taskStorage.Update(
+		taskCtx,
+		tw.task.Namespace,
+		tw.task.UUID,
+		storage.TaskUpdate{
+			Status:         status,
+			ExpectedStatus: domain.StatusRunning,
+			UpdatedAt:      time.Now(),
+			StartedAt:      tw.task.StartedAt,
+			Result:         execRes.Result,
+			Error:          err,
+			Actor:          domain.ActorSystem,
+			Reason:         reason,
+		}); errors.Is(updateErr, storage.ErrConflict) {
+		// Cancel records the cancellation before stopping the execution,
+		// the outcome of a canceled task is dropped.
+		wlog.Debug("Task is no longer running, dropping its outcome", slog.String("error", updateErr.Error()))
+	} else if updateErr != nil {
+		// Maybe we should use some retry mechanism here?
+		wlog.Error("Failed to update task status after execution", slog.String("error", updateErr.Error()))
 	}
 }
@@ -0,0 +1,382 @@
+package pool
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+var errQueueClosed = errors.New("task queue is closed")
+
+// tenantQueue holds queued tasks of one tenant, the highest priority
+// first and in FIFO order within a priority, see [compareQueued].
+type tenantQueue struct {
+	items   []*taskWrapper
+	running int
+	// pass is the virtual time of the tenant's next turn.
+	// Every dispatched task advances it by 1/weight, so tenants
+	// with larger weights get proportionally more turns.
+	pass float64
+}
+
+// queue is a bounded queue of tasks shared fairly between tenants.
+// Each tenant has its own queue ordered by priority and workers
+// are given tasks of the tenant with the smallest pass (stride
+// scheduling), so a tenant flooding the queue cannot starve the others.
+// Tasks whose type or namespace already runs at its concurrency limit,
+// whose tenant runs at its limit, or whose start time has not come yet
+// are skipped and do not block tasks behind them.
+type queue struct {
+	mu      sync.Mutex
+	size    int
+	count   int
+	limits  Limits
+	running map[string]int
+	// runningNS is the number of running tasks per namespace.
+	runningNS map[string]int
+	tenants   map[string]*tenantQueue
+	// vtime is the pass of the last dispatched task. Tenants that
+	// become active start from it, so they can't claim turns for the
+	// time they had nothing queued.
+	vtime  float64
+	closed bool
+	// seq numbers pushed tasks.
+	seq uint64
+	// timer broadcasts a change at wakeAt, the earliest start time
+	// of the tasks held in the queue, so waiting workers can take them.
+	timer  *time.Timer
+	wakeAt time.Time
+
+	// changed is closed and replaced on every change that may let
+	// a waiting push or pop proceed.
+	changed chan struct{}
+}
+
+func newQueue(size int, limits Limits) *queue {
+	limits.Types = maps.Clone(limits.Types)
+	limits.Namespaces.Overrides = maps.Clone(limits.Namespaces.Overrides)
+
+	return &queue{
+		size:      size,
+		limits:    limits,
+		running:   make(map[string]int),
+		runningNS: make(map[string]int),
+		tenants:   make(map[string]*tenantQueue),
+		changed:   make(chan struct{}),
+	}
+}
+
+// push adds tw to the queue of its tenant, waiting for a free slot
+// until ctx is done. It returns [worker.ErrTenantQueueFull] or
+// [worker.ErrTenantRunningFull] right away if the tenant already has
+// the maximum number of queued or running tasks.
+func (q *queue) push(ctx context.Context, tw *taskWrapper) error {
+	tenant := tw.task.Tenant
+
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return errQueueClosed
+		}
+
+		tq := q.tenant(tenant)
+		limit := q.limits.Tenants.For(tenant)
+		if limit.MaxQueued > 0 && len(tq.items) >= limit.MaxQueued {
+			q.forget(tenant)
+			q.mu.Unlock()
+			return worker.ErrTenantQueueFull
+		}
+		if limit.MaxRunning > 0 && tq.running >= limit.MaxRunning {
+			q.forget(tenant)
+			q.mu.Unlock()
+			return worker.ErrTenantRunningFull
+		}
+
+		if q.count < q.size {
+			if len(tq.items) == 0 && tq.running == 0 {
+				tq.pass = max(tq.pass, q.vtime)
+			}
+			q.seq++
+			tw.seq = q.seq
+			tq.insert(tw)
+			q.count++
+			q.broadcast()
+			q.mu.Unlock()
+			return nil
+		}
+
+		q.forget(tenant)
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// pop removes the next task to execute and takes the slots of its type,
+// namespace and tenant, which must be given back with release. If no task can be
+// executed now, tw is nil and changed is closed on the next change
+// of the queue. ok is false once the queue is closed and empty.
+func (q *queue) pop() (tw *taskWrapper, changed <-chan struct{}, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed && q.count == 0 {
+		return nil, nil, false
+	}
+
+	now := time.Now()
+	var next time.Time
+
+	for _, tenant := range q.byPass() {
+		tq := q.tenants[tenant]
+		tenantLimit := q.limits.Tenants.For(tenant)
+		if tenantLimit.MaxRunning > 0 && tq.running >= tenantLimit.MaxRunning {
+			continue
+		}
+
+		for i, item := range tq.items {
+			if item.runAt.After(now) {
+				if next.IsZero() || item.runAt.Before(next) {
+					next = item.runAt
+				}
+				continue
+			}
+			taskType := item.task.Type
+			if limit := q.limits.Types[taskType]; limit > 0 && q.running[taskType] >= limit {
+				continue
+			}
+			namespace := item.task.Namespace
+			if limit := q.limits.Namespaces.For(namespace); limit > 0 && q.runningNS[namespace] >= limit {
+				continue
+			}
+
+			tq.items = append(tq.items[:i], tq.items[i+1:]...)
+			tq.running++
+			q.count--
+			q.running[taskType]++
+			q.runningNS[namespace]++
+
+			q.vtime = tq.pass
+			tq.pass += 1 / float64(tenantLimit.Weight)
+
+			q.broadcast()
+			return item, nil, true
+		}
+	}
+
+	q.wakeUpAt(next)
+	return nil, q.changed, true
+}
+
+// wakeUpAt makes the timer broadcast a change at t, unless it already
+// fires earlier. A zero t is ignored. Must be called with mu held.
+func (q *queue) wakeUpAt(t time.Time) {
+	if t.IsZero() || (!q.wakeAt.IsZero() && !t.Before(q.wakeAt)) {
+		return
+	}
+
+	if q.timer != nil {
+		q.timer.Stop()
+	}
+	q.wakeAt = t
+	q.timer = time.AfterFunc(time.Until(t), func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		q.wakeAt = time.Time{}
+		q.broadcast()
+	})
+}
+
+// setPriority changes the priority of a queued task and moves it
+// to its new place. It reports whether the task was found in the queue.
+func (q *queue) setPriority(taskUUID string, priority int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, tq := range q.tenants {
+		i := slices.IndexFunc(tq.items, func(tw *taskWrapper) bool {
+			return tw.task.UUID == taskUUID
+		})
+		if i < 0 {
+			continue
+		}
+
+		tw := tq.items[i]
+		tq.items = slices.Delete(tq.items, i, i+1)
+		tw.priority = priority
+		tq.insert(tw)
+
+		q.broadcast()
+		return true
+	}
+
+	return false
+}
+
+// setRunAt changes the time a queued task may start at. It reports
+// whether the task was found in the queue.
+func (q *queue) setRunAt(taskUUID string, runAt time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, tq := range q.tenants {
+		i := slices.IndexFunc(tq.items, func(tw *taskWrapper) bool {
+			return tw.task.UUID == taskUUID
+		})
+		if i < 0 {
+			continue
+		}
+
+		tq.items[i].runAt = runAt
+
+		q.broadcast()
+		return true
+	}
+
+	return false
+}
+
+// release gives back the slots taken by pop for tw.
+func (q *queue) release(tw *taskWrapper) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	taskType := tw.task.Type
+	q.running[taskType]--
+	if q.running[taskType] <= 0 {
+		delete(q.running, taskType)
+	}
+
+	namespace := tw.task.Namespace
+	q.runningNS[namespace]--
+	if q.runningNS[namespace] <= 0 {
+		delete(q.runningNS, namespace)
+	}
+
+	if tq, ok := q.tenants[tw.task.Tenant]; ok {
+		tq.running--
+		q.forget(tw.task.Tenant)
+	}
+
+	q.broadcast()
+}
+
+// close stops accepting new tasks. Queued tasks can still be popped.
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.broadcast()
+}
+
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.count
+}
+
+// runningByType returns the number of running tasks per type.
+func (q *queue) runningByType() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return maps.Clone(q.running)
+}
+
+// runningByNamespace returns the number of running tasks per namespace.
+func (q *queue) runningByNamespace() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return maps.Clone(q.runningNS)
+}
+
+// tenantStats returns the number of queued and running tasks per tenant.
+func (q *queue) tenantStats() (queued, running map[string]int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queued = make(map[string]int, len(q.tenants))
+	running = make(map[string]int, len(q.tenants))
+	for tenant, tq := range q.tenants {
+		queued[tenant] = len(tq.items)
+		running[tenant] = tq.running
+	}
+
+	return queued, running
+}
+
+// insert puts tw at its place in the queue of the tenant.
+func (tq *tenantQueue) insert(tw *taskWrapper) {
+	i, _ := slices.BinarySearchFunc(tq.items, tw, compareQueued)
+	tq.items = slices.Insert(tq.items, i, tw)
+}
+
+// compareQueued orders queued tasks by priority, the highest first,
+// and tasks of the same priority in the order they were pushed.
+func compareQueued(a, b *taskWrapper) int {
+	if c := cmp.Compare(b.priority, a.priority); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
+// tenant returns the queue of the tenant, creating it if needed.
+// Must be called with mu held.
+func (q *queue) tenant(name string) *tenantQueue {
+	tq, ok := q.tenants[name]
+	if !ok {
+		tq = &tenantQueue{pass: q.vtime}
+		q.tenants[name] = tq
+	}
+	return tq
+}
+
+// forget drops the queue of a tenant with nothing queued or running.
+// Must be called with mu held.
+func (q *queue) forget(name string) {
+	if tq, ok := q.tenants[name]; ok && len(tq.items) == 0 && tq.running == 0 {
+		delete(q.tenants, name)
+	}
+}
+
+// byPass returns tenants with queued tasks, the smallest pass first.
+// Must be called with mu held.
+func (q *queue) byPass() []string {
+	tenants := make([]string, 0, len(q.tenants))
+	for name, tq := range q.tenants {
+		if len(tq.items) > 0 {
+			tenants = append(tenants, name)
+		}
+	}
+
+	slices.SortFunc(tenants, func(a, b string) int {
+		if c := cmp.Compare(q.tenants[a].pass, q.tenants[b].pass); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	return tenants
+}
+
+// broadcast wakes up everyone waiting for a change. Must be called with mu held.
+func (q *queue) broadcast() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
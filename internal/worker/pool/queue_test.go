@@ -0,0 +1,149 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/passwordhash/task-manager-api/internal/domain"
+	"github.com/passwordhash/task-manager-api/internal/worker"
+)
+
+func TestQueueOrdersByPriority(t *testing.T) {
+	q := newQueue(10, Limits{})
+
+	push := func(uuid string, priority int) {
+		tw := &taskWrapper{task: &domain.Task{UUID: uuid, Tenant: "tenant"}, priority: priority}
+		if err := q.push(context.Background(), tw); err != nil {
+			t.Fatalf("push %s: %v", uuid, err)
+		}
+	}
+	push("low", -5)
+	push("first", 0)
+	push("second", 0)
+	push("high", 5)
+
+	if !q.setPriority("second", 10) {
+		t.Fatal("setPriority didn't find a queued task")
+	}
+	if q.setPriority("missing", 10) {
+		t.Error("setPriority found a task that isn't queued")
+	}
+
+	var order []string
+	for q.len() > 0 {
+		tw, _, _ := q.pop()
+		order = append(order, tw.task.UUID)
+		q.release(tw)
+	}
+
+	if want := []string{"second", "high", "first", "low"}; !slices.Equal(order, want) {
+		t.Errorf("tasks popped in order %v, want %v", order, want)
+	}
+}
+
+func TestQueueRejectsTenantOverLimits(t *testing.T) {
+	q := newQueue(10, Limits{Tenants: TenantLimits{
+		Default: TenantLimit{MaxQueued: 2, MaxRunning: 1},
+	}})
+
+	push := func(uuid string) error {
+		return q.push(context.Background(), &taskWrapper{task: &domain.Task{UUID: uuid, Tenant: "tenant"}})
+	}
+	for _, uuid := range []string{"first", "second"} {
+		if err := push(uuid); err != nil {
+			t.Fatalf("push %s: %v", uuid, err)
+		}
+	}
+	if err := push("third"); !errors.Is(err, worker.ErrTenantQueueFull) {
+		t.Fatalf("push over the queued limit returned %v, want %v", err, worker.ErrTenantQueueFull)
+	}
+
+	first, _, _ := q.pop()
+	if first == nil {
+		t.Fatal("pop() didn't return the first task")
+	}
+	if err := push("third"); !errors.Is(err, worker.ErrTenantRunningFull) {
+		t.Fatalf("push at the running limit returned %v, want %v", err, worker.ErrTenantRunningFull)
+	}
+	if err := q.push(context.Background(), &taskWrapper{task: &domain.Task{UUID: "other", Tenant: "other"}}); err != nil {
+		t.Fatalf("push of another tenant: %v", err)
+	}
+
+	// The task queued before the limit was reached waits for a free slot,
+	// the task of the other tenant doesn't wait behind it.
+	if tw, _, _ := q.pop(); tw == nil || tw.task.UUID != "other" {
+		t.Fatalf("pop() at the running limit = %v, want the task of the other tenant", tw)
+	}
+	tw, changed, ok := q.pop()
+	if tw != nil || changed == nil || !ok {
+		t.Fatalf("pop() at the running limit = %v, want to wait", tw)
+	}
+
+	q.release(first)
+	if tw, _, _ := q.pop(); tw == nil || tw.task.UUID != "second" {
+		t.Fatalf("pop() after release = %v, want the second task", tw)
+	}
+}
+
+func TestQueueHoldsTaskUntilRunAt(t *testing.T) {
+	q := newQueue(10, Limits{})
+
+	runAt := time.Now().Add(50 * time.Millisecond)
+	for _, tw := range []*taskWrapper{
+		{task: &domain.Task{UUID: "later", Tenant: "tenant"}, runAt: runAt},
+		{task: &domain.Task{UUID: "now", Tenant: "tenant"}},
+	} {
+		if err := q.push(context.Background(), tw); err != nil {
+			t.Fatalf("push %s: %v", tw.task.UUID, err)
+		}
+	}
+
+	// The held task does not block the one behind it.
+	if tw, _, _ := q.pop(); tw == nil || tw.task.UUID != "now" {
+		t.Fatalf("pop() = %v, want the task without a start time", tw)
+	}
+
+	tw, changed, _ := q.pop()
+	if tw != nil {
+		t.Fatalf("pop() before the start time = %v, want to wait", tw.task.UUID)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("queue didn't wake up at the start time")
+	}
+	tw, _, _ = q.pop()
+	if tw == nil || tw.task.UUID != "later" {
+		t.Fatalf("pop() at the start time = %v, want the held task", tw)
+	}
+	if now := time.Now(); now.Before(runAt) {
+		t.Errorf("task popped %v before its start time", runAt.Sub(now))
+	}
+}
+
+func TestQueueSetRunAt(t *testing.T) {
+	q := newQueue(10, Limits{})
+
+	tw := &taskWrapper{task: &domain.Task{UUID: "task", Tenant: "tenant"}}
+	if err := q.push(context.Background(), tw); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+
+	if !q.setRunAt("task", time.Now().Add(time.Hour)) {
+		t.Fatal("setRunAt didn't find a queued task")
+	}
+	if got, _, _ := q.pop(); got != nil {
+		t.Fatal("pop() returned a task held for an hour")
+	}
+
+	if !q.setRunAt("task", time.Time{}) {
+		t.Fatal("setRunAt didn't find a queued task")
+	}
+	if got, _, _ := q.pop(); got == nil {
+		t.Error("pop() didn't return the task after its start time was removed")
+	}
+}
@@ -0,0 +1,309 @@
+package client
+
+// Package client is the Go client of the task manager HTTP API.
+//
+//	c, err := client.New("http://localhost:8080", client.WithAPIKey(key))
+//	uuid, err := c.Create(ctx, client.CreateRequest{Type: "report"})
+//	task, err := c.Wait(ctx, uuid)
+//
+// Requests are retried on network errors, rate limiting and server
+// errors. Requests that change tasks carry an idempotency key,
+// so retrying them never creates or changes a task twice.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyHeader         = "X-API-Key"
+	tenantHeader         = "X-Tenant-ID"
+	idempotencyKeyHeader = "Idempotency-Key"
+	userAgent            = "task-manager-go-client"
+)
+
+// RetryPolicy configures retries of failed requests.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of a request including the first one,
+	// values less than two disable retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, it doubles
+	// with every retry up to MaxBackoff. A Retry-After header
+	// of the response takes precedence.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of clients created without [WithRetryPolicy].
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  200 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	apiKey     string
+	token      string
+	namespace  string
+	retry      RetryPolicy
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client, [http.DefaultClient] by default.
+// Its timeout must allow for the event streams of Watch and Wait.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithAPIKey authenticates requests with the API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithBearerToken authenticates requests with the JWT.
+func WithBearerToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithNamespace makes the client manage tasks of the namespace
+// instead of the default one.
+func WithNamespace(namespace string) Option {
+	return func(c *Client) { c.namespace = namespace }
+}
+
+// WithRetryPolicy replaces [DefaultRetryPolicy].
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+// New returns a client of the API served at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	const op = "client.New"
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%s: base URL must be an http or https URL", op)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// tasksPath returns the path of the tasks collection of the namespace of the client.
+func (c *Client) tasksPath() string {
+	if c.namespace == "" || c.namespace == "default" {
+		return "/api/v1/tasks"
+	}
+	return "/api/v1/namespaces/" + url.PathEscape(c.namespace) + "/tasks"
+}
+
+// request describes a call of the API.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   any
+	// idempotencyKey is sent with requests that change state,
+	// a random one is generated if it is empty.
+	idempotencyKey string
+	accept         string
+}
+
+// do sends the request, retrying it according to the retry policy,
+// and decodes a successful JSON response into out if it is not nil.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// send sends the request, retrying it according to the retry policy,
+// and returns a successful response. Error responses are returned as *[APIError].
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+	}
+	if req.method != http.MethodGet && req.idempotencyKey == "" {
+		// The same key is sent with every attempt.
+		req.idempotencyKey = uuid.NewString()
+	}
+
+	attempts := max(c.retry.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req, body)
+
+		var retryAfter time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+		case resp.StatusCode < http.StatusBadRequest:
+			return resp, nil
+		default:
+			apiErr := decodeError(resp)
+			if !retryable(apiErr) {
+				return nil, apiErr
+			}
+			err = apiErr
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+
+		if attempt >= attempts {
+			return nil, err
+		}
+
+		timer := time.NewTimer(max(retryAfter, c.backoff(attempt)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req request, body []byte) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	accept := req.accept
+	if accept == "" {
+		accept = "application/json"
+	}
+	httpReq.Header.Set("Accept", accept)
+	httpReq.Header.Set("User-Agent", userAgent)
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set(idempotencyKeyHeader, req.idempotencyKey)
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set(apiKeyHeader, c.apiKey)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// backoff returns the delay before the retry following the attempt, with jitter.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.retry.MinBackoff << (attempt - 1)
+	if delay <= 0 || (c.retry.MaxBackoff > 0 && delay > c.retry.MaxBackoff) {
+		delay = c.retry.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryable reports whether the request may succeed if it is sent again.
+func retryable(err *APIError) bool {
+	switch err.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusConflict:
+		// The first attempt with the key is still being served.
+		return err.Code == "idempotency_key_in_use"
+	default:
+		return false
+	}
+}
+
+// problem is an error response of the API in the RFC 7807 format.
+type problem struct {
+	Title     string       `json:"title"`
+	Detail    string       `json:"detail"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id"`
+	Errors    []FieldError `json:"errors"`
+}
+
+// decodeError reads the error response and closes its body.
+func decodeError(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var p problem
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&p); err != nil {
+		// Not a response of the API, e.g. of a proxy in front of it.
+		apiErr.Title = http.StatusText(resp.StatusCode)
+		return apiErr
+	}
+
+	apiErr.Code = p.Code
+	apiErr.Title = p.Title
+	apiErr.Detail = p.Detail
+	apiErr.RequestID = p.RequestID
+	apiErr.Fields = p.Errors
+	return apiErr
+}
+
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
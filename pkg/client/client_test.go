@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/passwordhash/task-manager-api/internal/api/middleware"
+	"github.com/passwordhash/task-manager-api/internal/api/v1/ws"
+	httpapp "github.com/passwordhash/task-manager-api/internal/app/http"
+	"github.com/passwordhash/task-manager-api/internal/auth"
+	"github.com/passwordhash/task-manager-api/internal/events"
+	"github.com/passwordhash/task-manager-api/internal/service/task"
+	"github.com/passwordhash/task-manager-api/internal/storage/inmemory"
+	"github.com/passwordhash/task-manager-api/internal/storage/notifying"
+	"github.com/passwordhash/task-manager-api/internal/worker/executor"
+	"github.com/passwordhash/task-manager-api/internal/worker/pool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const testAPIKey = "secret"
+
+var fastRetries = RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+// newTestServer returns the handler of the HTTP API backed by the in-memory
+// storage, accepting [testAPIKey]. The requests are rate limited by limiter
+// if it is not nil. The worker pool is not started, so tasks stay pending.
+func newTestServer(t *testing.T, limiter *middleware.RateLimiter) http.Handler {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	log := slog.New(slog.DiscardHandler)
+	broker := events.NewBroker()
+	storage := notifying.NewTaskStorage(inmemory.NewTaskStorage(), broker)
+
+	pools, err := pool.NewGroups(pool.New(log, 1, 100, pool.Limits{}, executor.New(broker), storage))
+	if err != nil {
+		t.Fatalf("create worker groups: %v", err)
+	}
+	keys, err := auth.NewAPIKeys([]auth.APIKey{
+		{Name: "test", SHA256: auth.HashAPIKey(testAPIKey), Scopes: []string{string(auth.ScopeAdmin)}},
+	})
+	if err != nil {
+		t.Fatalf("create API keys: %v", err)
+	}
+
+	app := httpapp.New(
+		log,
+		pools,
+		task.NewSimulatedTaskService(log, pools, storage, task.NamespaceQuotas{}),
+		broker,
+		prometheus.NewRegistry(),
+		keys,
+		nil,
+		limiter,
+		limiter,
+		middleware.NewIdempotencyKeys(time.Hour),
+		nil,
+		0,
+		0,
+		0,
+		ws.Options{},
+	)
+	return app.Handler()
+}
+
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts = append([]Option{WithRetryPolicy(fastRetries), WithAPIKey(testAPIKey)}, opts...)
+	c, err := New(srv.URL, opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+// countRequests passes requests on to next and counts them.
+func countRequests(next http.Handler, count *int) http.Handler {
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*count++
+		mu.Unlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestRetryKeepsIdempotencyKey(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	// The response to the first attempt is lost on its way to the client,
+	// though the server has created the task.
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		attempt := len(keys)
+		mu.Unlock()
+
+		if attempt == 1 {
+			server.ServeHTTP(httptest.NewRecorder(), r)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+
+	ctx := context.Background()
+	taskUUID, err := c.Create(ctx, CreateRequest{Type: "report"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("attempts sent idempotency keys %q, want the same key twice", keys)
+	}
+
+	page, err := c.List(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(page.Tasks) != 1 || page.Tasks[0].UUID != taskUUID || page.Tasks[0].Type != "report" {
+		t.Errorf("List returned %+v, want only the created task %s", page.Tasks, taskUUID)
+	}
+}
+
+func TestNoRetryOfClientErrors(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, countRequests(newTestServer(t, nil), &attempts))
+
+	ctx := context.Background()
+	taskUUID, err := c.Create(ctx, CreateRequest{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := c.Cancel(ctx, taskUUID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	attempts = 0
+	err = c.Cancel(ctx, taskUUID)
+	if !errors.Is(err, ErrCantCancel) {
+		t.Fatalf("second Cancel returned %v, want ErrCantCancel", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict || apiErr.RequestID == "" || apiErr.Detail == "" {
+		t.Errorf("Cancel returned %#v, want the decoded problem", err)
+	}
+	if attempts != 1 {
+		t.Errorf("request was sent %d times, want once", attempts)
+	}
+}
+
+func TestRateLimitedRequest(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.RateLimit{Rate: 0.001, Burst: 1})
+	attempts := 0
+	// The server asks to retry much later than the test runs,
+	// so the client must give up after its only attempt.
+	c := newTestClient(t, countRequests(newTestServer(t, limiter), &attempts),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+	ctx := context.Background()
+	if _, err := c.Get(ctx, "7f6c4a57-1c5a-4b8e-9d55-6b0a3c9b8d21"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get returned %v, want ErrNotFound", err)
+	}
+
+	_, err := c.Get(ctx, "7f6c4a57-1c5a-4b8e-9d55-6b0a3c9b8d21")
+	if !errors.Is(err, ErrTooManyRequests) {
+		t.Fatalf("Get over the limit returned %v, want ErrTooManyRequests", err)
+	}
+	if attempts != 2 {
+		t.Errorf("requests were sent %d times, want 2", attempts)
+	}
+}
+
+func TestWatchFollowsTask(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil), WithNamespace("team"))
+
+	ctx := context.Background()
+	taskUUID, err := c.Create(ctx, CreateRequest{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	stream, err := c.Watch(ctx, taskUUID)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer stream.Close()
+
+	if err := c.Cancel(ctx, taskUUID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	var got []Event
+	for stream.Next() {
+		got = append(got, stream.Event())
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(got), got)
+	}
+	if got[0].Status != StatusPending || got[1].Status != StatusCanceled {
+		t.Errorf("got statuses %s and %s, want %s and %s", got[0].Status, got[1].Status, StatusPending, StatusCanceled)
+	}
+	if got[0].Namespace != "team" || got[0].TaskUUID != taskUUID {
+		t.Errorf("first event is for task %s/%s, want team/%s", got[0].Namespace, got[0].TaskUUID, taskUUID)
+	}
+	if got[1].Version <= got[0].Version {
+		t.Errorf("versions of the events are %d and %d, want them increasing", got[0].Version, got[1].Version)
+	}
+}
+
+func TestUpdateClearsLabelsAndDescription(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil))
+
+	ctx := context.Background()
+	taskUUID, err := c.Create(ctx, CreateRequest{Labels: map[string]string{"env": "prod"}, Description: "nightly"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	empty := ""
+	task, err := c.Update(ctx, taskUUID, UpdateRequest{ClearLabels: true, Description: &empty})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(task.Labels) != 0 || task.Description != "" {
+		t.Errorf("updated task has labels %v and description %q, want none", task.Labels, task.Description)
+	}
+}
+
+func TestUpdateRunAt(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil))
+
+	ctx := context.Background()
+	taskUUID, err := c.Create(ctx, CreateRequest{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	runAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	task, err := c.Update(ctx, taskUUID, UpdateRequest{RunAt: &runAt})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !task.RunAt.Equal(runAt) {
+		t.Errorf("updated task runs at %v, want %v", task.RunAt, runAt)
+	}
+
+	task, err = c.Update(ctx, taskUUID, UpdateRequest{RunAt: &time.Time{}})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !task.RunAt.IsZero() {
+		t.Errorf("task runs at %v after the start time was removed, want none", task.RunAt)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if _, err := c.Update(ctx, taskUUID, UpdateRequest{RunAt: &past}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Update with a past start time returned %v, want ErrValidation", err)
+	}
+}
+
+func TestNewRejectsInvalidURL(t *testing.T) {
+	if _, err := New("localhost:8080"); err == nil {
+		t.Error("New accepted a URL without a scheme")
+	}
+}
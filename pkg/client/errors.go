@@ -0,0 +1,107 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors matched by [APIError] with [errors.Is], they mirror
+// the error codes of the API.
+var (
+	// ErrNotFound is returned when the task or worker group does not exist
+	// or is not visible to the client.
+	ErrNotFound = errors.New("not found")
+
+	// ErrCantCancel is returned when the task has already finished.
+	ErrCantCancel = errors.New("task cannot be canceled")
+
+	// ErrCantRetry is returned when the task has not reached a terminal status yet.
+	ErrCantRetry = errors.New("task cannot be retried")
+
+	// ErrCantUpdate is returned when the priority of a task
+	// that is no longer pending is changed.
+	ErrCantUpdate = errors.New("task cannot be updated")
+
+	// ErrCantDelete is returned when the task has not reached a terminal status yet.
+	ErrCantDelete = errors.New("task cannot be deleted")
+
+	// ErrStatusConflict is returned when the status of the task changed
+	// concurrently with the request, repeating it may succeed.
+	ErrStatusConflict = errors.New("task status changed concurrently")
+
+	// ErrPreconditionFailed is returned when a change conditional on
+	// the version of the task is rejected because the task has changed.
+	ErrPreconditionFailed = errors.New("task has changed")
+
+	// ErrValidation is returned when the request is rejected as invalid,
+	// [APIError.Fields] lists the invalid fields.
+	ErrValidation = errors.New("request validation failed")
+
+	// ErrUnauthorized is returned when the credentials are missing or invalid.
+	ErrUnauthorized = errors.New("authentication required")
+
+	// ErrForbidden is returned when the credentials lack a scope
+	// or do not grant access to the namespace.
+	ErrForbidden = errors.New("access denied")
+
+	// ErrQuotaExceeded is returned when the namespace stores too many tasks.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// ErrTooManyRequests is returned when the client is rate limited
+	// or its tenant has too many queued tasks, after retries run out.
+	ErrTooManyRequests = errors.New("too many requests")
+
+	// ErrIdempotencyKeyReused is returned when the idempotency key
+	// was already used for another request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for another request")
+)
+
+var codeErrors = map[string]error{
+	"not_found":                  ErrNotFound,
+	"cant_be_canceled":           ErrCantCancel,
+	"cant_be_retried":            ErrCantRetry,
+	"cant_be_updated":            ErrCantUpdate,
+	"cant_be_deleted":            ErrCantDelete,
+	"status_conflict":            ErrStatusConflict,
+	"precondition_failed":        ErrPreconditionFailed,
+	"validation_failed":          ErrValidation,
+	"invalid_request_parameters": ErrValidation,
+	"unauthorized":               ErrUnauthorized,
+	"forbidden":                  ErrForbidden,
+	"quota_exceeded":             ErrQuotaExceeded,
+	"too_many_requests":          ErrTooManyRequests,
+	"idempotency_key_reused":     ErrIdempotencyKeyReused,
+}
+
+// APIError is an error response of the API.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Code is the stable error code, see the error catalog of the API.
+	Code   string
+	Title  string
+	Detail string
+	// RequestID identifies the request in the logs of the server.
+	RequestID string
+	Fields    []FieldError
+}
+
+// FieldError describes an invalid field of a request.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("task manager API: %d %s", e.StatusCode, e.Code)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Is matches the error against the errors of the package by its code.
+func (e *APIError) Is(target error) bool {
+	err, ok := codeErrors[e.Code]
+	return ok && err == target
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// PoolState is the state of the worker pool of a group.
+type PoolState struct {
+	Group       string `json:"group"`
+	Paused      bool   `json:"paused"`
+	Workers     int    `json:"workers"`
+	BusyWorkers int    `json:"busy_workers"`
+	Queued      int    `json:"queued"`
+
+	TypeLimits      map[string]int `json:"type_limits"`
+	RunningByType   map[string]int `json:"running_by_type"`
+	QueuedByTenant  map[string]int `json:"queued_by_tenant"`
+	RunningByTenant map[string]int `json:"running_by_tenant"`
+}
+
+// Pools returns the states of the pools of all worker groups.
+// The pool methods require credentials with the admin scope.
+func (c *Client) Pools(ctx context.Context) ([]PoolState, error) {
+	const op = "client.Pools"
+
+	var resp struct {
+		Groups []PoolState `json:"groups"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/admin/groups"}, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return resp.Groups, nil
+}
+
+// Pool returns the state of the pool of the group, of the default group if it is empty.
+func (c *Client) Pool(ctx context.Context, group string) (*PoolState, error) {
+	const op = "client.Pool"
+
+	var state PoolState
+	if err := c.do(ctx, request{method: http.MethodGet, path: poolPath(group)}, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &state, nil
+}
+
+// PausePool stops the pool of the group from starting queued tasks,
+// running tasks are not interrupted.
+func (c *Client) PausePool(ctx context.Context, group string) (*PoolState, error) {
+	const op = "client.PausePool"
+
+	var state PoolState
+	if err := c.do(ctx, request{method: http.MethodPost, path: poolPath(group) + "/pause"}, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &state, nil
+}
+
+// ResumePool makes the paused pool of the group start queued tasks again.
+func (c *Client) ResumePool(ctx context.Context, group string) (*PoolState, error) {
+	const op = "client.ResumePool"
+
+	var state PoolState
+	if err := c.do(ctx, request{method: http.MethodPost, path: poolPath(group) + "/resume"}, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &state, nil
+}
+
+// ResizePool changes the number of workers of the pool of the group.
+func (c *Client) ResizePool(ctx context.Context, group string, workers int) (*PoolState, error) {
+	const op = "client.ResizePool"
+
+	body := struct {
+		Workers int `json:"workers"`
+	}{Workers: workers}
+
+	var state PoolState
+	if err := c.do(ctx, request{method: http.MethodPut, path: poolPath(group), body: body}, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &state, nil
+}
+
+func poolPath(group string) string {
+	if group == "" {
+		return "/admin/pool"
+	}
+	return "/admin/groups/" + url.PathEscape(group)
+}
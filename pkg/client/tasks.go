@@ -0,0 +1,488 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// IsTerminal reports whether the task will not be executed anymore.
+func (s Status) IsTerminal() bool {
+	switch s {
+	case StatusCompleted, StatusFailed, StatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Task is a task of the API. Tasks returned by List only have
+// UUID, Type, Tenant, Status, CreatedAt and Labels set.
+type Task struct {
+	UUID      string
+	Namespace string
+	Type      string
+	Tenant    string
+	Owner     string
+	Status    Status
+	CreatedAt time.Time
+	// StartedAt is the time a worker took the task, zero if none did.
+	StartedAt time.Time
+	// Duration is the time the task has been running.
+	Duration time.Duration
+
+	Result any
+	Error  string
+
+	// RetryOf is the UUID of the task this one re-runs.
+	RetryOf string
+	// Retries lists UUIDs of the re-runs of this task.
+	Retries []string
+	// RequestID is the ID of the request that created the task.
+	RequestID string
+	// Version grows with every change of the task.
+	Version uint64
+
+	Labels      map[string]string
+	Description string
+	// Priority orders pending tasks of a tenant, higher first.
+	Priority int
+	// RunAt is the time the task is held in the queue until, zero if none.
+	RunAt time.Time
+}
+
+// CreateRequest describes a task to create.
+type CreateRequest struct {
+	// Type routes the task to its worker group, "default" if empty.
+	Type    string
+	Payload any
+	// Tenant owns the task when authentication is disabled, otherwise
+	// the task belongs to the tenant of the credentials.
+	Tenant string
+	// IdempotencyKey makes the request safe to repeat, even across
+	// restarts of the caller: a request with a key the server has
+	// already seen returns the task created by the first one.
+	// A random key is used for the retries of the call if it is empty.
+	IdempotencyKey string
+	// Priority orders pending tasks of the tenant, higher first,
+	// from -100 to 100.
+	Priority int
+	// Labels group tasks to select them, see [ListOptions.Selector].
+	Labels      map[string]string
+	Description string
+}
+
+// Create creates a task and returns its UUID.
+func (c *Client) Create(ctx context.Context, req CreateRequest) (string, error) {
+	const op = "client.Create"
+
+	body := struct {
+		Type        string            `json:"type,omitempty"`
+		Payload     any               `json:"payload,omitempty"`
+		Priority    int               `json:"priority,omitempty"`
+		Labels      map[string]string `json:"labels,omitempty"`
+		Description string            `json:"description,omitempty"`
+	}{Type: req.Type, Payload: req.Payload, Priority: req.Priority, Labels: req.Labels, Description: req.Description}
+
+	var resp struct {
+		TaskUUID string `json:"task_uuid"`
+	}
+
+	r := request{method: http.MethodPost, path: c.tasksPath() + "/", body: body, idempotencyKey: req.IdempotencyKey}
+	if req.Tenant != "" {
+		r.header = http.Header{tenantHeader: {req.Tenant}}
+	}
+	if err := c.do(ctx, r, &resp); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.TaskUUID, nil
+}
+
+// Get returns the task with the UUID.
+func (c *Client) Get(ctx context.Context, uuid string) (*Task, error) {
+	const op = "client.Get"
+
+	var resp taskResponse
+	if err := c.do(ctx, request{method: http.MethodGet, path: c.taskPath(uuid) + "/status"}, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.task(), nil
+}
+
+// UpdateRequest describes changes of a task, nil fields are kept.
+type UpdateRequest struct {
+	// Labels are merged into the labels of the task,
+	// a label with a nil value is removed.
+	Labels map[string]*string
+	// ClearLabels removes all labels of the task, Labels are ignored then.
+	ClearLabels bool
+	// Description replaces the description of the task,
+	// an empty one removes it.
+	Description *string
+	// Priority can only be changed while the task is pending,
+	// otherwise Update returns [ErrCantUpdate].
+	Priority *int
+	// RunAt holds a pending task in the queue until then, a zero time
+	// lets it run right away. It must be in the future and can only
+	// be changed like Priority.
+	RunAt *time.Time
+	// Version makes the update conditional like [Client.CancelVersion].
+	Version uint64
+}
+
+// Update changes labels, description or priority of the task
+// and returns the updated task.
+func (c *Client) Update(ctx context.Context, uuid string, req UpdateRequest) (*Task, error) {
+	const op = "client.Update"
+
+	body := struct {
+		// Labels holds null to remove all labels.
+		Labels      any     `json:"labels,omitempty"`
+		Description *string `json:"description,omitempty"`
+		Priority    *int    `json:"priority,omitempty"`
+		// RunAt holds null to remove the start time.
+		RunAt any `json:"run_at,omitempty"`
+	}{Description: req.Description, Priority: req.Priority}
+	switch {
+	case req.ClearLabels:
+		body.Labels = json.RawMessage("null")
+	case req.Labels != nil:
+		body.Labels = req.Labels
+	}
+	if req.RunAt != nil {
+		if req.RunAt.IsZero() {
+			body.RunAt = json.RawMessage("null")
+		} else {
+			body.RunAt = req.RunAt.Format(time.RFC3339Nano)
+		}
+	}
+
+	var resp taskResponse
+	r := request{method: http.MethodPatch, path: c.taskPath(uuid), body: body, header: ifMatch(req.Version)}
+	if err := c.do(ctx, r, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.task(), nil
+}
+
+// taskResponse is a task as returned by the API.
+type taskResponse struct {
+	UUID        string            `json:"uuid"`
+	Namespace   string            `json:"namespace"`
+	Type        string            `json:"type"`
+	Tenant      string            `json:"tenant"`
+	Owner       string            `json:"owner"`
+	Status      Status            `json:"status"`
+	CreatedAt   string            `json:"created_at"`
+	StartedAt   string            `json:"started_at"`
+	Duration    string            `json:"duration"`
+	Result      any               `json:"result"`
+	Error       string            `json:"error"`
+	RetryOf     string            `json:"retry_of"`
+	Retries     []string          `json:"retries"`
+	RequestID   string            `json:"request_id"`
+	Version     uint64            `json:"version"`
+	Labels      map[string]string `json:"labels"`
+	Description string            `json:"description"`
+	Priority    int               `json:"priority"`
+	RunAt       string            `json:"run_at"`
+}
+
+func (resp *taskResponse) task() *Task {
+	createdAt, _ := time.Parse(time.RFC3339, resp.CreatedAt)
+	runAt, _ := time.Parse(time.RFC3339, resp.RunAt)
+	startedAt, _ := time.Parse(time.RFC3339Nano, resp.StartedAt)
+	// The server only reports the duration of finished tasks.
+	duration, _ := time.ParseDuration(resp.Duration)
+	if resp.Status == StatusRunning && !startedAt.IsZero() {
+		duration = time.Since(startedAt)
+	}
+
+	return &Task{
+		UUID:        resp.UUID,
+		Namespace:   resp.Namespace,
+		Type:        resp.Type,
+		Tenant:      resp.Tenant,
+		Owner:       resp.Owner,
+		Status:      resp.Status,
+		CreatedAt:   createdAt,
+		StartedAt:   startedAt,
+		Duration:    duration,
+		Result:      resp.Result,
+		Error:       resp.Error,
+		RetryOf:     resp.RetryOf,
+		Retries:     resp.Retries,
+		RequestID:   resp.RequestID,
+		Version:     resp.Version,
+		Labels:      resp.Labels,
+		Description: resp.Description,
+		Priority:    resp.Priority,
+		RunAt:       runAt,
+	}
+}
+
+// ListOptions filters and pages the tasks of List.
+type ListOptions struct {
+	// Limit is the maximum number of tasks of the page, all tasks if zero.
+	Limit int
+	// PageToken is [TaskPage.NextPageToken] of the previous page.
+	PageToken string
+	// Selector keeps only the tasks whose labels match the label
+	// selector, e.g. "env=prod,batch in (a,b),!draft".
+	Selector string
+}
+
+// TaskPage is a page of tasks ordered by creation time.
+type TaskPage struct {
+	Tasks []Task
+	// NextPageToken is passed in [ListOptions] to get the next page,
+	// it is empty on the last page.
+	NextPageToken string
+}
+
+// List returns a page of the tasks of the namespace.
+func (c *Client) List(ctx context.Context, opts ListOptions) (*TaskPage, error) {
+	const op = "client.List"
+
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.PageToken != "" {
+		query.Set("page_token", opts.PageToken)
+	}
+	if opts.Selector != "" {
+		query.Set("selector", opts.Selector)
+	}
+
+	var resp struct {
+		Tasks []struct {
+			UUID      string            `json:"uuid"`
+			Type      string            `json:"type"`
+			Tenant    string            `json:"tenant"`
+			Status    Status            `json:"status"`
+			CreatedAt string            `json:"created_at"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"tasks"`
+		NextPageToken string `json:"next_page_token"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: c.tasksPath() + "/", query: query}, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page := &TaskPage{
+		Tasks:         make([]Task, 0, len(resp.Tasks)),
+		NextPageToken: resp.NextPageToken,
+	}
+	for _, t := range resp.Tasks {
+		createdAt, _ := time.Parse(time.RFC3339, t.CreatedAt)
+		page.Tasks = append(page.Tasks, Task{
+			UUID:      t.UUID,
+			Type:      t.Type,
+			Tenant:    t.Tenant,
+			Status:    t.Status,
+			CreatedAt: createdAt,
+			Labels:    t.Labels,
+		})
+	}
+
+	return page, nil
+}
+
+// Cancel cancels a pending or running task.
+func (c *Client) Cancel(ctx context.Context, uuid string) error {
+	return c.CancelVersion(ctx, uuid, 0)
+}
+
+// CancelVersion cancels a pending or running task only if it still has
+// the version, see [Task.Version]. It returns [ErrPreconditionFailed]
+// if the task has changed. A zero version cancels any version.
+func (c *Client) CancelVersion(ctx context.Context, uuid string, version uint64) error {
+	const op = "client.Cancel"
+
+	r := request{method: http.MethodPost, path: c.taskPath(uuid) + "/cancel", header: ifMatch(version)}
+	if err := c.do(ctx, r, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// CancelAll cancels the pending and running tasks whose labels match
+// the selector, see [ListOptions.Selector], and returns their UUIDs.
+func (c *Client) CancelAll(ctx context.Context, selector string) ([]string, error) {
+	const op = "client.CancelAll"
+
+	var resp struct {
+		TaskUUIDs []string `json:"task_uuids"`
+	}
+	r := request{method: http.MethodPost, path: c.tasksPath() + "/cancel", query: url.Values{"selector": {selector}}}
+	if err := c.do(ctx, r, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return resp.TaskUUIDs, nil
+}
+
+// Delete deletes a task in a terminal status.
+func (c *Client) Delete(ctx context.Context, uuid string) error {
+	return c.DeleteVersion(ctx, uuid, 0)
+}
+
+// DeleteVersion deletes a task in a terminal status only if it still has
+// the version, like [Client.CancelVersion].
+func (c *Client) DeleteVersion(ctx context.Context, uuid string, version uint64) error {
+	const op = "client.Delete"
+
+	r := request{method: http.MethodDelete, path: c.taskPath(uuid), header: ifMatch(version)}
+	if err := c.do(ctx, r, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteAll deletes the tasks in a terminal status whose labels match
+// the selector, see [ListOptions.Selector], and returns their UUIDs.
+func (c *Client) DeleteAll(ctx context.Context, selector string) ([]string, error) {
+	const op = "client.DeleteAll"
+
+	var resp struct {
+		TaskUUIDs []string `json:"task_uuids"`
+	}
+	r := request{method: http.MethodDelete, path: c.tasksPath() + "/", query: url.Values{"selector": {selector}}}
+	if err := c.do(ctx, r, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return resp.TaskUUIDs, nil
+}
+
+// ifMatch returns the header making a change conditional on the version,
+// none for a zero version.
+func ifMatch(version uint64) http.Header {
+	if version == 0 {
+		return nil
+	}
+	return http.Header{"If-Match": {`"` + strconv.FormatUint(version, 10) + `"`}}
+}
+
+// Wait waits until the task reaches a terminal status and returns it.
+// It follows the events of the task and reconnects if the stream breaks.
+func (c *Client) Wait(ctx context.Context, uuid string) (*Task, error) {
+	const op = "client.Wait"
+
+	for attempt := 1; ; attempt++ {
+		terminal, err := c.waitTerminal(ctx, uuid)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if terminal {
+			task, err := c.Get(ctx, uuid)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			return task, nil
+		}
+
+		// The stream ended early, e.g. the server is restarting.
+		timer := time.NewTimer(c.backoff(min(attempt, 8)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// waitTerminal follows the events of the task until the stream ends
+// and reports whether the task reached a terminal status.
+func (c *Client) waitTerminal(ctx context.Context, uuid string) (bool, error) {
+	stream, err := c.Watch(ctx, uuid)
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+
+	for stream.Next() {
+		event := stream.Event()
+		if event.Type == EventStatus && event.Status.IsTerminal() {
+			return true, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	return false, nil
+}
+
+func (c *Client) taskPath(uuid string) string {
+	return c.tasksPath() + "/" + url.PathEscape(uuid)
+}
+
+// Transition is a change of the status of a task. Edits of labels,
+// description or priority are recorded with From equal to To.
+type Transition struct {
+	// From is empty for the transition creating the task.
+	From Status
+	To   Status
+	At   time.Time
+	// Actor is the API client that caused the transition, "system" for
+	// transitions made by the server and "anonymous" for clients when
+	// authentication is disabled.
+	Actor  string
+	Reason string
+}
+
+// History is the status history of a task.
+type History struct {
+	Transitions []Transition
+	// QueueWait is the time the task waited in the queue,
+	// ExecutionTime is the time it has been running.
+	QueueWait     time.Duration
+	ExecutionTime time.Duration
+}
+
+// History returns the status history of the task.
+func (c *Client) History(ctx context.Context, uuid string) (*History, error) {
+	const op = "client.History"
+
+	var resp struct {
+		History []struct {
+			From   Status    `json:"from"`
+			To     Status    `json:"to"`
+			At     time.Time `json:"at"`
+			Actor  string    `json:"actor"`
+			Reason string    `json:"reason"`
+		} `json:"history"`
+		QueueWait     string `json:"queue_wait"`
+		ExecutionTime string `json:"execution_time"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: c.taskPath(uuid) + "/history"}, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	history := &History{Transitions: make([]Transition, 0, len(resp.History))}
+	for _, t := range resp.History {
+		history.Transitions = append(history.Transitions, Transition(t))
+	}
+	history.QueueWait, _ = time.ParseDuration(resp.QueueWait)
+	history.ExecutionTime, _ = time.ParseDuration(resp.ExecutionTime)
+
+	return history, nil
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type EventType string
+
+const (
+	// EventStatus reports a change of the status of the task.
+	EventStatus EventType = "status"
+	// EventProgress reports the done share of a running task.
+	EventProgress EventType = "progress"
+	// EventLog carries a log line of a running task.
+	EventLog EventType = "log"
+)
+
+// Event is an event of a task.
+type Event struct {
+	Type      EventType `json:"type"`
+	Namespace string    `json:"namespace"`
+	TaskUUID  string    `json:"task_uuid"`
+	Time      time.Time `json:"time"`
+	// Status is set for status events.
+	Status Status `json:"status"`
+	// Version is the version of the task with Status, set for status
+	// events. An event with a version not above the one of a task
+	// read before is stale.
+	Version uint64 `json:"version"`
+	// Progress is the done share of the task in percent, set for progress events.
+	Progress int `json:"progress"`
+	// Message is set for log events.
+	Message string `json:"message"`
+}
+
+// Watch follows the events of the task. The stream starts with a status
+// event of the current status and ends after the status event of a terminal
+// status, or earlier if the client falls behind or the server shuts down.
+// The stream must be closed.
+func (c *Client) Watch(ctx context.Context, uuid string) (*Stream, error) {
+	const op = "client.Watch"
+
+	resp, err := c.send(ctx, request{
+		method: http.MethodGet,
+		path:   c.taskPath(uuid) + "/events",
+		accept: "text/event-stream",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Stream{body: resp.Body, scanner: bufio.NewScanner(resp.Body)}, nil
+}
+
+// Stream is a stream of events of a task, it is not safe for concurrent use.
+//
+//	for stream.Next() {
+//		event := stream.Event()
+//	}
+//	if err := stream.Err(); err != nil {
+//	}
+type Stream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	event   Event
+	err     error
+}
+
+// Next waits for the next event and reports whether there is one.
+// It returns false when the stream ends or fails.
+func (s *Stream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	var eventType, data string
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		switch {
+		case line == "":
+			if data == "" {
+				continue
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				s.err = fmt.Errorf("decode event: %w", err)
+				return false
+			}
+			if eventType != "" {
+				event.Type = EventType(eventType)
+			}
+			s.event = event
+			return true
+		case strings.HasPrefix(line, ":"):
+			// A comment keeping the stream alive.
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data != "" {
+				data += "\n"
+			}
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+	s.err = s.scanner.Err()
+
+	return false
+}
+
+// Event returns the event read by the last call of Next.
+func (s *Stream) Event() Event {
+	return s.event
+}
+
+// Err returns the error that ended the stream, nil if the server ended it.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Close stops following the events.
+func (s *Stream) Close() error {
+	return s.body.Close()
+}
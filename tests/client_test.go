@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/passwordhash/task-manager-api/pkg/client"
+)
+
+func newClient(t *testing.T) *client.Client {
+	t.Helper()
+
+	c, err := client.New(u.String())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c
+}
+
+func TestClientWaitForCompletedTask(t *testing.T) {
+	c := newClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	taskUUID, err := c.Create(ctx, client.CreateRequest{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	task, err := c.Wait(ctx, taskUUID)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if task.UUID != taskUUID || task.Status != client.StatusCompleted {
+		t.Errorf("Wait returned task %s with status %s, want %s with status completed", task.UUID, task.Status, taskUUID)
+	}
+}
+
+func TestClientWatchCanceledTask(t *testing.T) {
+	c := newClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	taskUUID, err := c.Create(ctx, client.CreateRequest{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	stream, err := c.Watch(ctx, taskUUID)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer stream.Close()
+
+	if !stream.Next() {
+		t.Fatalf("stream ended before the first event: %v", stream.Err())
+	}
+	if first := stream.Event(); first.Type != client.EventStatus || first.TaskUUID != taskUUID {
+		t.Fatalf("first event is %+v, want the status of the task", first)
+	}
+
+	if err := c.Cancel(ctx, taskUUID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	var last client.Event
+	for stream.Next() {
+		last = stream.Event()
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	if last.Type != client.EventStatus || last.Status != client.StatusCanceled {
+		t.Errorf("last event is %+v, want the canceled status", last)
+	}
+}
+
+func TestClientListPages(t *testing.T) {
+	c := newClient(t)
+	ctx := context.Background()
+
+	created := make(map[string]bool)
+	for range 3 {
+		taskUUID, err := c.Create(ctx, client.CreateRequest{})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		created[taskUUID] = true
+	}
+	t.Cleanup(func() {
+		for taskUUID := range created {
+			_ = c.Cancel(ctx, taskUUID)
+		}
+	})
+
+	seen := make(map[string]bool)
+	var prev time.Time
+	opts := client.ListOptions{Limit: 2}
+	for {
+		page, err := c.List(ctx, opts)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(page.Tasks) > opts.Limit {
+			t.Fatalf("page has %d tasks, limit is %d", len(page.Tasks), opts.Limit)
+		}
+		for _, task := range page.Tasks {
+			if seen[task.UUID] {
+				t.Fatalf("task %s is listed twice", task.UUID)
+			}
+			if task.CreatedAt.Before(prev) {
+				t.Fatalf("task %s is listed out of creation order", task.UUID)
+			}
+			seen[task.UUID] = true
+			prev = task.CreatedAt
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = page.NextPageToken
+	}
+
+	for taskUUID := range created {
+		if !seen[taskUUID] {
+			t.Errorf("task %s is not listed", taskUUID)
+		}
+	}
+}
+
+func TestClientLabels(t *testing.T) {
+	c := newClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	batch := uuid.NewString()
+	taskUUID, err := c.Create(ctx, client.CreateRequest{Labels: map[string]string{"batch": batch}, Description: "labeled"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	page, err := c.List(ctx, client.ListOptions{Selector: "batch=" + batch})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(page.Tasks) != 1 || page.Tasks[0].UUID != taskUUID || page.Tasks[0].Labels["batch"] != batch {
+		t.Errorf("List by selector returned %+v, want only task %s", page.Tasks, taskUUID)
+	}
+
+	canceled, err := c.CancelAll(ctx, "batch="+batch)
+	if err != nil {
+		t.Fatalf("CancelAll: %v", err)
+	}
+	if len(canceled) != 1 || canceled[0] != taskUUID {
+		t.Errorf("CancelAll canceled %v, want [%s]", canceled, taskUUID)
+	}
+
+	deleted, err := c.DeleteAll(ctx, "batch="+batch)
+	if err != nil {
+		t.Fatalf("DeleteAll: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != taskUUID {
+		t.Errorf("DeleteAll deleted %v, want [%s]", deleted, taskUUID)
+	}
+}
+
+func TestClientIdempotentCreate(t *testing.T) {
+	c := newClient(t)
+	ctx := context.Background()
+
+	req := client.CreateRequest{IdempotencyKey: uuid.NewString()}
+	first, err := c.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Cancel(ctx, first) })
+
+	second, err := c.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("Create with the same key: %v", err)
+	}
+	if second != first {
+		t.Errorf("repeated Create returned task %s, want %s", second, first)
+	}
+
+	req.Type = "other"
+	_, err = c.Create(ctx, req)
+	if !errors.Is(err, client.ErrIdempotencyKeyReused) {
+		t.Errorf("Create reusing the key for another body returned %v, want ErrIdempotencyKeyReused", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	c := newClient(t)
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, uuid.NewString())
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Get of a missing task returned %v, want ErrNotFound", err)
+	}
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 || apiErr.RequestID == "" {
+		t.Errorf("Get of a missing task returned %#v, want an APIError with status 404 and request ID", err)
+	}
+
+	taskUUID, err := c.Create(ctx, client.CreateRequest{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	task, err := c.Get(ctx, taskUUID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if err := c.CancelVersion(ctx, taskUUID, task.Version+1); !errors.Is(err, client.ErrPreconditionFailed) {
+		t.Errorf("Cancel of another version returned %v, want ErrPreconditionFailed", err)
+	}
+	if err := c.Cancel(ctx, taskUUID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if err := c.Cancel(ctx, taskUUID); !errors.Is(err, client.ErrCantCancel) {
+		t.Errorf("second Cancel returned %v, want ErrCantCancel", err)
+	}
+	if err := c.Delete(ctx, taskUUID); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestDashboardServed(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resp := e.GET("/dashboard/").
+		Expect().Status(http.StatusOK)
+	resp.HasContentType("text/html")
+	resp.Body().Contains("app.js")
+
+	e.GET("/dashboard/app.js").
+		Expect().Status(http.StatusOK).
+		Body().Contains("/events")
+}
+
+func TestDashboardMissingFile(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/dashboard/missing.js").
+		Expect().Status(http.StatusNotFound)
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+func TestNotFoundProblem(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := uuid.NewString()
+
+	resp := e.GET("/api/v1/tasks/"+taskUUID+"/status").WithHeader("X-Request-ID", "problem-test").
+		Expect().Status(http.StatusNotFound)
+	problem(resp).
+		HasValue("type", "urn:task-manager:problem:not_found").
+		HasValue("code", "not_found").
+		HasValue("status", http.StatusNotFound).
+		HasValue("instance", "/api/v1/tasks/"+taskUUID+"/status").
+		HasValue("request_id", "problem-test").
+		ContainsKey("title")
+}
+
+func TestUnknownRouteProblem(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	problem(e.GET("/api/v1/unknown").Expect().Status(http.StatusNotFound)).
+		HasValue("code", "route_not_found")
+}
+
+func TestValidationProblem(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resp := e.POST("/api/v1/tasks/").WithJSON(map[string]any{"type": strings.Repeat("x", 65)}).
+		Expect().Status(http.StatusBadRequest)
+	errs := problem(resp).HasValue("code", "validation_failed").
+		Value("errors").Array()
+	errs.Length().IsEqual(1)
+	errs.Value(0).Object().HasValue("field", "type").ContainsKey("reason")
+
+	resp = e.POST("/api/v1/tasks/").WithJSON(map[string]any{"type": 42}).
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "type")
+}
+
+// problem returns the RFC 7807 body of an error response.
+func problem(resp *httpexpect.Response) *httpexpect.Object {
+	return resp.JSON(httpexpect.ContentOpts{MediaType: "application/problem+json"}).Object()
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestStatusETag(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	resp := statusTask(e, taskUUID).Expect().Status(http.StatusOK)
+	version := resp.JSON().Object().Value("version").Number().Gt(0).Raw()
+	tag := resp.Header("ETag").NotEmpty().Raw()
+	if want := strconv.Quote(strconv.FormatUint(uint64(version), 10)); tag != want {
+		t.Errorf("ETag is %s, want %s", tag, want)
+	}
+
+	statusTask(e, taskUUID).WithHeader("If-None-Match", tag).
+		Expect().Status(http.StatusNotModified).
+		Header("ETag").IsEqual(tag)
+	statusTask(e, taskUUID).WithHeader("If-None-Match", `"0", W/`+tag).
+		Expect().Status(http.StatusNotModified)
+
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	changed := statusTask(e, taskUUID).WithHeader("If-None-Match", tag).
+		Expect().Status(http.StatusOK)
+	changed.Header("ETag").NotEqual(tag)
+	changed.JSON().Object().Value("version").Number().Gt(version)
+}
+
+func TestStatusOfRunningTaskOnlyChangesWithETag(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+	waitHistory(e, taskUUID, "running")
+
+	first := statusTask(e, taskUUID).Expect().Status(http.StatusOK)
+	time.Sleep(100 * time.Millisecond)
+	second := statusTask(e, taskUUID).Expect().Status(http.StatusOK)
+
+	// A 304 for the tag stands for exactly the body sent with it.
+	second.Header("ETag").IsEqual(first.Header("ETag").Raw())
+	second.Body().IsEqual(first.Body().Raw())
+	second.JSON().Object().
+		HasValue("status", "running").
+		NotContainsKey("duration").
+		Value("started_at").String().AsDateTime(time.RFC3339Nano)
+}
+
+func TestCancelWithIfMatch(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	// A task is stored with version 1, starting it changes the version.
+	waitHistory(e, taskUUID, "running")
+
+	resp := cancelTask(e, taskUUID).WithHeader("If-Match", `"1"`).
+		Expect().Status(http.StatusPreconditionFailed)
+	problem(resp).HasValue("code", "precondition_failed")
+
+	current := statusTask(e, taskUUID).Expect().Header("ETag").Raw()
+	cancelTask(e, taskUUID).WithHeader("If-Match", current).
+		Expect().Status(http.StatusOK)
+}
+
+func TestDeleteWithIfMatch(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+	current := statusTask(e, taskUUID).Expect().Header("ETag").Raw()
+
+	// Re-running the task links the retry to it, which changes it.
+	retryTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	deleteTask(e, taskUUID).WithHeader("If-Match", current).
+		Expect().Status(http.StatusPreconditionFailed)
+
+	deleteTask(e, taskUUID).WithHeader("If-Match", "*").
+		Expect().Status(http.StatusOK)
+}
+
+func TestInvalidIfMatch(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	for _, header := range []string{"1", `W/"1"`, `"1", "2"`, `"abc"`, `"0"`} {
+		resp := cancelTask(e, taskUUID).WithHeader("If-Match", header).
+			Expect().Status(http.StatusBadRequest)
+		problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "If-Match")
+	}
+}
@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	taskv1 "github.com/passwordhash/task-manager-api/api/task/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newGRPCClient(t *testing.T) taskv1.TaskServiceClient {
+	t.Helper()
+
+	conn, err := grpc.NewClient("localhost:"+cfg.GRPCPort, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect to gRPC server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return taskv1.NewTaskServiceClient(conn)
+}
+
+func TestGRPCCreateAndGetTask(t *testing.T) {
+	client := newGRPCClient(t)
+	ctx := context.Background()
+
+	payload, _ := structpb.NewValue(map[string]any{"report": "daily"})
+	created, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{
+		Payload:     payload,
+		Priority:    10,
+		Labels:      map[string]string{"env": "prod"},
+		Description: "nightly report",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = client.CancelTask(ctx, &taskv1.CancelTaskRequest{Uuid: created.GetTaskUuid()})
+	})
+
+	got, err := client.GetTask(ctx, &taskv1.GetTaskRequest{Uuid: created.GetTaskUuid()})
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.GetTask().GetUuid() != created.GetTaskUuid() {
+		t.Errorf("got task %s, want %s", got.GetTask().GetUuid(), created.GetTaskUuid())
+	}
+	if got.GetTask().GetNamespace() != "default" {
+		t.Errorf("got namespace %q, want default", got.GetTask().GetNamespace())
+	}
+	if report := got.GetTask().GetPayload().GetStructValue().GetFields()["report"].GetStringValue(); report != "daily" {
+		t.Errorf("got payload report %q, want daily", report)
+	}
+	if task := got.GetTask(); task.GetPriority() != 10 || task.GetLabels()["env"] != "prod" || task.GetDescription() != "nightly report" {
+		t.Errorf("got priority %d, labels %v and description %q, want the created ones",
+			task.GetPriority(), task.GetLabels(), task.GetDescription())
+	}
+}
+
+func TestGRPCListTasksByStatus(t *testing.T) {
+	client := newGRPCClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := client.CancelTask(ctx, &taskv1.CancelTaskRequest{Uuid: created.GetTaskUuid()}); err != nil {
+		t.Fatalf("CancelTask: %v", err)
+	}
+
+	list, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{Status: taskv1.TaskStatus_TASK_STATUS_CANCELED})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+
+	var found bool
+	for _, task := range list.GetTasks() {
+		if task.GetStatus() != taskv1.TaskStatus_TASK_STATUS_CANCELED {
+			t.Errorf("task %s has status %s", task.GetUuid(), task.GetStatus())
+		}
+		found = found || task.GetUuid() == created.GetTaskUuid()
+	}
+	if !found {
+		t.Errorf("canceled task %s is not listed", created.GetTaskUuid())
+	}
+}
+
+func TestGRPCCancelAndDeleteTask(t *testing.T) {
+	client := newGRPCClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	_, err = client.DeleteTask(ctx, &taskv1.DeleteTaskRequest{Uuid: created.GetTaskUuid()})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	if _, err := client.CancelTask(ctx, &taskv1.CancelTaskRequest{Uuid: created.GetTaskUuid()}); err != nil {
+		t.Fatalf("CancelTask: %v", err)
+	}
+	_, err = client.CancelTask(ctx, &taskv1.CancelTaskRequest{Uuid: created.GetTaskUuid()})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	if _, err := client.DeleteTask(ctx, &taskv1.DeleteTaskRequest{Uuid: created.GetTaskUuid()}); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	_, err = client.GetTask(ctx, &taskv1.GetTaskRequest{Uuid: created.GetTaskUuid()})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestGRPCInvalidArguments(t *testing.T) {
+	client := newGRPCClient(t)
+	ctx := context.Background()
+
+	_, err := client.GetTask(ctx, &taskv1.GetTaskRequest{Uuid: "not-a-uuid"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.GetTask(ctx, &taskv1.GetTaskRequest{Namespace: "Not_Valid", Uuid: uuid.NewString()})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.CancelTask(ctx, &taskv1.CancelTaskRequest{Uuid: uuid.NewString()})
+	assertCode(t, err, codes.NotFound)
+
+	_, err = client.CreateTask(ctx, &taskv1.CreateTaskRequest{Priority: 101})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.CreateTask(ctx, &taskv1.CreateTaskRequest{Labels: map[string]string{"-env": "prod"}})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestGRPCWatchTask(t *testing.T) {
+	client := newGRPCClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	created, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	stream, err := client.WatchTask(ctx, &taskv1.WatchTaskRequest{Uuid: created.GetTaskUuid()})
+	if err != nil {
+		t.Fatalf("WatchTask: %v", err)
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if first.GetTask().GetUuid() != created.GetTaskUuid() {
+		t.Fatalf("got task %s, want %s", first.GetTask().GetUuid(), created.GetTaskUuid())
+	}
+
+	if _, err := client.CancelTask(ctx, &taskv1.CancelTaskRequest{Uuid: created.GetTaskUuid()}); err != nil {
+		t.Fatalf("CancelTask: %v", err)
+	}
+
+	var last *taskv1.Task
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		last = resp.GetTask()
+	}
+
+	if last.GetStatus() != taskv1.TaskStatus_TASK_STATUS_CANCELED {
+		t.Errorf("stream ended with status %s, want canceled", last.GetStatus())
+	}
+}
+
+func assertCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+
+	if got := status.Code(err); got != want {
+		t.Errorf("got code %s, want %s (error: %v)", got, want, err)
+	}
+}
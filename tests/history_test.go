@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+func TestHistoryOfCanceledTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	// Cancel the task once a worker runs it, a queued task is canceled
+	// without ever running.
+	waitHistory(e, taskUUID, "running")
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	history := waitHistory(e, taskUUID, "canceled")
+	history.Value("task_uuid").IsEqual(taskUUID)
+
+	transitions := history.Value("history").Array()
+	transitions.Length().IsEqual(3)
+
+	created := transitions.Value(0).Object()
+	created.NotContainsKey("from")
+	created.HasValue("to", "pending").HasValue("actor", "anonymous").HasValue("reason", "created")
+
+	transitions.Value(1).Object().
+		HasValue("from", "pending").HasValue("to", "running").HasValue("actor", "system")
+	transitions.Value(2).Object().
+		HasValue("from", "running").HasValue("to", "canceled").HasValue("actor", "anonymous")
+}
+
+func TestHistoryOfCompletedTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+
+	history := waitHistory(e, taskUUID, "completed")
+	history.Value("history").Array().Value(2).Object().
+		HasValue("from", "running").HasValue("to", "completed").HasValue("actor", "system")
+
+	executionTime, err := time.ParseDuration(history.Value("execution_time").String().Raw())
+	if err != nil {
+		t.Fatalf("execution_time is not a duration: %v", err)
+	}
+	if executionTime < cfg.IoDuration {
+		t.Errorf("execution_time is %v, want at least %v", executionTime, cfg.IoDuration)
+	}
+}
+
+func TestHistoryOfRetriedTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	var retryResp retryTaskResp
+	retryTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Decode(&retryResp)
+	t.Cleanup(func() {
+		cancelTask(e, retryResp.TaskUUID).Expect()
+	})
+
+	historyTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("status", "canceled").
+		Value("history").Array().Last().Object().
+		HasValue("from", "canceled").HasValue("to", "canceled").
+		HasValue("actor", "anonymous").
+		HasValue("reason", "retried as "+retryResp.TaskUUID)
+}
+
+func TestHistoryOfNonExistentTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	historyTask(e, uuid.NewString()).
+		Expect().Status(http.StatusNotFound)
+}
+
+// waitHistory polls the history of the task until it reaches the status.
+func waitHistory(e *httpexpect.Expect, taskUUID string, status string) *httpexpect.Object {
+	deadline := time.Now().Add(5 * cfg.IoDuration)
+	for {
+		history := historyTask(e, taskUUID).
+			Expect().Status(http.StatusOK).JSON().Object()
+		if history.Value("status").String().Raw() == status || time.Now().After(deadline) {
+			history.HasValue("status", status)
+			return history
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func historyTask(e *httpexpect.Expect, taskUUID string) *httpexpect.Request {
+	return e.GET("/api/v1/tasks/" + taskUUID + "/history")
+}
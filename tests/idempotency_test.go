@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+func TestIdempotentCreateReplaysResponse(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+	key := uuid.NewString()
+
+	var first createTaskResp
+	resp := e.POST("/api/v1/tasks/").WithHeader("Idempotency-Key", key).
+		Expect().Status(http.StatusOK)
+	resp.Header("Idempotent-Replayed").IsEmpty()
+	resp.JSON().Decode(&first)
+	t.Cleanup(func() {
+		cancelTask(e, first.TaskUUID).Expect()
+	})
+
+	resp = e.POST("/api/v1/tasks/").WithHeader("Idempotency-Key", key).
+		Expect().Status(http.StatusOK)
+	resp.Header("Idempotent-Replayed").IsEqual("true")
+	resp.JSON().Object().HasValue("task_uuid", first.TaskUUID)
+}
+
+func TestIdempotencyKeyReusedForAnotherRequest(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+	key := uuid.NewString()
+
+	var created createTaskResp
+	e.POST("/api/v1/tasks/").WithHeader("Idempotency-Key", key).
+		Expect().Status(http.StatusOK).JSON().Decode(&created)
+	t.Cleanup(func() {
+		cancelTask(e, created.TaskUUID).Expect()
+	})
+
+	resp := e.POST("/api/v1/tasks/").WithHeader("Idempotency-Key", key).
+		WithJSON(map[string]any{"type": "other"}).
+		Expect().Status(http.StatusUnprocessableEntity)
+	problem(resp).HasValue("code", "idempotency_key_reused")
+}
+
+func TestIdempotencyKeyReusedForAnotherSelector(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+	key := uuid.NewString()
+	batch := uuid.NewString()
+
+	e.POST("/api/v1/tasks/cancel").WithHeader("Idempotency-Key", key).
+		WithQuery("selector", "batch="+batch).
+		Expect().Status(http.StatusOK)
+
+	resp := e.POST("/api/v1/tasks/cancel").WithHeader("Idempotency-Key", key).
+		WithQuery("selector", "batch="+batch+",env=prod").
+		Expect().Status(http.StatusUnprocessableEntity)
+	problem(resp).HasValue("code", "idempotency_key_reused")
+}
+
+func TestListWithMalformedPageToken(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resp := e.GET("/api/v1/tasks/").WithQuery("page_token", "not a token").
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "page_token")
+}
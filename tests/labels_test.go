@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+func TestCreateWithLabels(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	labels := map[string]any{"env": "prod", "example.com/release": "v1.2"}
+	taskUUID := createLabeledTask(e, labels, "Monthly report")
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("labels", labels).
+		HasValue("description", "Monthly report")
+}
+
+func TestCreateWithInvalidLabels(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	for _, labels := range []map[string]any{
+		{"": "prod"},
+		{"env": "not valid"},
+		{"EXAMPLE.com/env": "prod"},
+	} {
+		resp := e.POST("/api/v1/tasks/").WithJSON(map[string]any{"labels": labels}).
+			Expect().Status(http.StatusBadRequest)
+		problem(resp).HasValue("code", "validation_failed")
+	}
+}
+
+func TestListBySelector(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// A unique batch keeps tasks of other tests out of the selection.
+	batch := uuid.NewString()
+	prod := createLabeledTask(e, map[string]any{"batch": batch, "env": "prod"}, "")
+	dev := createLabeledTask(e, map[string]any{"batch": batch, "env": "dev"}, "")
+	draft := createLabeledTask(e, map[string]any{"batch": batch, "env": "prod", "draft": ""}, "")
+	t.Cleanup(func() {
+		e.POST("/api/v1/tasks/cancel").WithQuery("selector", "batch="+batch).Expect()
+	})
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{selector: "batch=" + batch, want: []string{prod, dev, draft}},
+		{selector: "batch=" + batch + ",env=prod,!draft", want: []string{prod}},
+		{selector: "batch=" + batch + ",env in (dev,staging)", want: []string{dev}},
+		{selector: "batch=" + batch + ",env!=dev", want: []string{prod, draft}},
+	}
+	for _, tt := range tests {
+		tasks := e.GET("/api/v1/tasks/").WithQuery("selector", tt.selector).
+			Expect().Status(http.StatusOK).JSON().Object().
+			Value("tasks").Array()
+		tasks.Length().IsEqual(len(tt.want))
+		for i, taskUUID := range tt.want {
+			tasks.Value(i).Object().HasValue("uuid", taskUUID).ContainsKey("labels")
+		}
+	}
+
+	resp := e.GET("/api/v1/tasks/").WithQuery("selector", "env in (prod").
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "selector")
+}
+
+func TestBulkCancelAndDelete(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	batch := uuid.NewString()
+	first := createLabeledTask(e, map[string]any{"batch": batch}, "")
+	second := createLabeledTask(e, map[string]any{"batch": batch}, "")
+	other := createLabeledTask(e, map[string]any{"batch": batch, "keep": "true"}, "")
+	t.Cleanup(func() {
+		cancelTask(e, other).Expect()
+	})
+
+	// Nothing has finished yet.
+	e.DELETE("/api/v1/tasks/").WithQuery("selector", "batch="+batch).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("task_uuids").Array().IsEmpty()
+
+	e.POST("/api/v1/tasks/cancel").WithQuery("selector", "batch="+batch+",!keep").
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("task_uuids").Array().ContainsOnly(first, second)
+	statusTask(e, other).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("status").NotEqual("canceled")
+
+	e.DELETE("/api/v1/tasks/").WithQuery("selector", "batch="+batch).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("task_uuids").Array().ContainsOnly(first, second)
+	statusTask(e, first).
+		Expect().Status(http.StatusNotFound)
+}
+
+func TestBulkRequiresSelector(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resp := e.POST("/api/v1/tasks/cancel").
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "selector")
+
+	e.DELETE("/api/v1/tasks/").
+		Expect().Status(http.StatusBadRequest)
+
+	// A blank selector would match every task.
+	for _, selector := range []string{" ", "  ,  "} {
+		resp = e.POST("/api/v1/tasks/cancel").WithQuery("selector", selector).
+			Expect().Status(http.StatusBadRequest)
+		problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "selector")
+
+		e.DELETE("/api/v1/tasks/").WithQuery("selector", selector).
+			Expect().Status(http.StatusBadRequest)
+	}
+}
+
+func createLabeledTask(e *httpexpect.Expect, labels map[string]any, description string) string {
+	return e.POST("/api/v1/tasks/").
+		WithJSON(map[string]any{"labels": labels, "description": description}).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("task_uuid").String().Raw()
+}
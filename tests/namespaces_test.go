@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+// These tests rely on the namespace quotas from configs/local.yml.
+
+func TestNamespaceIsolation(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createNamespacedTask(e, "team-a")
+	t.Cleanup(func() {
+		namespacePath(e, http.MethodPost, "team-a", "/"+taskUUID+"/cancel").Expect()
+	})
+
+	namespacePath(e, http.MethodGet, "team-a", "/"+taskUUID+"/status").
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("namespace", "team-a")
+	namespacePath(e, http.MethodGet, "team-a", "/").
+		Expect().Status(http.StatusOK).JSON().Object().Value("tasks").Array().
+		Filter(func(_ int, value *httpexpect.Value) bool {
+			return value.Object().Value("uuid").String().Raw() == taskUUID
+		}).Length().IsEqual(1)
+
+	// Tasks of other namespaces do not exist for this one.
+	namespacePath(e, http.MethodGet, "team-b", "/"+taskUUID+"/status").
+		Expect().Status(http.StatusNotFound)
+	namespacePath(e, http.MethodPost, "team-b", "/"+taskUUID+"/cancel").
+		Expect().Status(http.StatusNotFound)
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusNotFound)
+}
+
+func TestDefaultNamespaceRoutes(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	namespacePath(e, http.MethodGet, "default", "/"+taskUUID+"/status").
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("namespace", "default")
+}
+
+func TestInvalidNamespace(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	namespacePath(e, http.MethodGet, "Not_Valid", "/").
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestNamespaceTaskQuota(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// The limited namespace stores up to three tasks.
+	var tasks []string
+	t.Cleanup(func() {
+		for _, taskUUID := range tasks {
+			namespacePath(e, http.MethodPost, "limited", "/"+taskUUID+"/cancel").Expect()
+		}
+	})
+
+	for range 4 {
+		resp := namespacePath(e, http.MethodPost, "limited", "/").Expect()
+		if resp.Raw().StatusCode == http.StatusForbidden {
+			problem(resp).HasValue("code", "quota_exceeded")
+			return
+		}
+
+		var createResp createTaskResp
+		resp.Status(http.StatusOK).JSON().Decode(&createResp)
+		tasks = append(tasks, createResp.TaskUUID)
+	}
+
+	t.Fatal("namespace quota is not enforced")
+}
+
+func TestNamespaceRunningLimit(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// The serial namespace runs one task at a time.
+	first := createNamespacedTask(e, "serial")
+	second := createNamespacedTask(e, "serial")
+	t.Cleanup(func() {
+		for _, taskUUID := range []string{first, second} {
+			namespacePath(e, http.MethodPost, "serial", "/"+taskUUID+"/cancel").Expect()
+		}
+	})
+
+	namespacePath(e, http.MethodGet, "serial", "/"+first+"/status").
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "running")
+	namespacePath(e, http.MethodGet, "serial", "/"+second+"/status").
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "pending")
+}
+
+func createNamespacedTask(e *httpexpect.Expect, namespace string) string {
+	var createResp createTaskResp
+
+	namespacePath(e, http.MethodPost, namespace, "/").
+		Expect().Status(http.StatusOK).JSON().Object().
+		ContainsKey("task_uuid").Decode(&createResp)
+
+	return createResp.TaskUUID
+}
+
+func namespacePath(e *httpexpect.Expect, method, namespace, path string) *httpexpect.Request {
+	return e.Request(method, "/api/v1/namespaces/"+namespace+"/tasks"+path)
+}
@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	doc := e.GET("/openapi.json").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	doc.Value("openapi").String().HasPrefix("3.")
+	doc.Value("paths").Object().ContainsKey("/api/v1/tasks/{uuid}/status")
+	doc.Path("$.components.schemas.StatusResponse.properties").Object().
+		ContainsKey("status").
+		ContainsKey("namespace")
+}
+
+func TestSwaggerUI(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/docs/").
+		Expect().
+		Status(http.StatusOK).
+		ContentType("text/html")
+
+	e.GET("/docs/swagger-initializer.js").
+		Expect().
+		Status(http.StatusOK).
+		Body().Contains("/openapi.json")
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+func TestPatchLabelsAndDescription(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	patchTask(e, taskUUID).
+		WithJSON(map[string]any{
+			"labels":      map[string]any{"env": "prod", "example.com/batch": "nightly"},
+			"description": "Nightly report",
+		}).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("labels", map[string]any{"env": "prod", "example.com/batch": "nightly"}).
+		HasValue("description", "Nightly report")
+
+	resp := patchTask(e, taskUUID).
+		WithJSON(map[string]any{"labels": map[string]any{"example.com/batch": nil}}).
+		Expect().Status(http.StatusOK)
+	resp.Header("ETag").NotEmpty()
+	resp.JSON().Object().
+		HasValue("labels", map[string]any{"env": "prod"}).
+		HasValue("description", "Nightly report")
+
+	transitions := historyTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("history").Array()
+	edit := transitions.Value(len(transitions.Iter()) - 1).Object()
+	edit.Value("from").IsEqual(edit.Value("to").Raw())
+	edit.HasValue("reason", "label example.com/batch removed").HasValue("actor", "anonymous")
+}
+
+func TestPatchNullResetsFields(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	patchTask(e, taskUUID).
+		WithJSON(map[string]any{
+			"labels":      map[string]any{"env": "prod", "team": "billing"},
+			"description": "Nightly report",
+		}).
+		Expect().Status(http.StatusOK)
+
+	resp := patchTask(e, taskUUID).
+		WithJSON(map[string]any{"labels": nil, "description": nil}).
+		Expect().Status(http.StatusOK).JSON().Object()
+	resp.NotContainsKey("labels")
+	resp.NotContainsKey("description")
+
+	transitions := historyTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("history").Array()
+	transitions.Value(len(transitions.Iter())-1).Object().
+		HasValue("reason", "labels removed, description removed")
+}
+
+func TestPatchPriority(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	pausePool(e).
+		Expect().Status(http.StatusOK)
+	t.Cleanup(func() {
+		resumePool(e).Expect()
+	})
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	patchTask(e, taskUUID).
+		WithJSON(map[string]any{"priority": 50}).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("status", "pending").HasValue("priority", 50)
+
+	resumePool(e).
+		Expect().Status(http.StatusOK)
+	waitHistory(e, taskUUID, "running")
+
+	resp := patchTask(e, taskUUID).
+		WithJSON(map[string]any{"priority": 0}).
+		Expect().Status(http.StatusConflict)
+	problem(resp).HasValue("code", "cant_be_updated")
+
+	// Labels can still be edited.
+	patchTask(e, taskUUID).
+		WithJSON(map[string]any{"labels": map[string]any{"env": "prod"}}).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("priority", 50)
+}
+
+func TestPatchRunAt(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	pausePool(e).
+		Expect().Status(http.StatusOK)
+	t.Cleanup(func() {
+		resumePool(e).Expect()
+	})
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	// The highest priority keeps tasks left by other tests from delaying it.
+	runAt := time.Now().Add(time.Second).UTC().Truncate(time.Second).Add(time.Second)
+	patchTask(e, taskUUID).
+		WithJSON(map[string]any{"run_at": runAt.Format(time.RFC3339), "priority": 100}).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("status", "pending").HasValue("run_at", runAt.Format(time.RFC3339))
+
+	transitions := historyTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("history").Array()
+	reason := transitions.Value(len(transitions.Iter()) - 1).Object().Value("reason").String().Raw()
+	if !strings.HasSuffix(reason, "start time set to "+runAt.Format(time.RFC3339)) {
+		t.Errorf("edit is recorded with reason %q, want the start time", reason)
+	}
+
+	// Free workers don't take the task before its start time.
+	resumePool(e).
+		Expect().Status(http.StatusOK)
+	time.Sleep(time.Until(runAt) - 300*time.Millisecond)
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("status", "pending")
+
+	waitHistory(e, taskUUID, "running")
+	if now := time.Now(); now.Before(runAt) {
+		t.Errorf("task started %v before its start time", runAt.Sub(now))
+	}
+
+	resp := patchTask(e, taskUUID).
+		WithJSON(map[string]any{"run_at": time.Now().Add(time.Hour).Format(time.RFC3339)}).
+		Expect().Status(http.StatusConflict)
+	problem(resp).HasValue("code", "cant_be_updated")
+}
+
+func TestPatchWithIfMatch(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	current := statusTask(e, taskUUID).Expect().Header("ETag").Raw()
+	resp := patchTask(e, taskUUID).WithHeader("If-Match", current).
+		WithJSON(map[string]any{"description": "first"}).
+		Expect().Status(http.StatusOK)
+	resp.Header("ETag").NotEqual(current)
+
+	resp = patchTask(e, taskUUID).WithHeader("If-Match", current).
+		WithJSON(map[string]any{"description": "second"}).
+		Expect().Status(http.StatusPreconditionFailed)
+	problem(resp).HasValue("code", "precondition_failed")
+
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("description", "first")
+}
+
+func TestPatchValidation(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	bodies := []map[string]any{
+		{},
+		{"labels": map[string]any{"-env": "prod"}},
+		{"labels": map[string]any{"Example.com/env": "prod"}},
+		{"labels": map[string]any{"env": "not valid"}},
+		{"priority": 101},
+		{"run_at": "2000-01-01T00:00:00Z"},
+		{"run_at": "tomorrow"},
+		{"status": "completed"},
+	}
+	for _, body := range bodies {
+		resp := patchTask(e, taskUUID).WithJSON(body).
+			Expect().Status(http.StatusBadRequest)
+		problem(resp).Value("errors").Array().NotEmpty()
+	}
+
+	patchTask(e, uuid.NewString()).
+		WithJSON(map[string]any{"description": "missing"}).
+		Expect().Status(http.StatusNotFound)
+}
+
+func patchTask(e *httpexpect.Expect, taskUUID string) *httpexpect.Request {
+	return e.PATCH("/api/v1/tasks/" + taskUUID)
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestPauseAndResumePool(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	pausePool(e).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("paused", true)
+	t.Cleanup(func() {
+		resumePool(e).Expect()
+	})
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "pending")
+	e.GET("/readyz").
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("status", "paused").
+		Value("pool").Object().HasValue("paused", true)
+
+	resumePool(e).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("paused", false)
+
+	time.Sleep(100 * time.Millisecond)
+
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "running")
+	e.GET("/readyz").
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "ok")
+}
+
+func TestPoolMetrics(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/metrics").
+		Expect().Status(http.StatusOK).Body().
+		Contains("task_manager_pool_paused").
+		Contains("task_manager_pool_queue_length")
+}
+
+func TestResizePool(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resizePool(e, cfg.Workers+2).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("workers", cfg.Workers+2)
+	t.Cleanup(func() {
+		resizePool(e, cfg.Workers).Expect()
+	})
+
+	tasks := make([]string, cfg.Workers+2)
+	for i := range tasks {
+		tasks[i] = createTask(e)
+	}
+	t.Cleanup(func() {
+		for _, taskUUID := range tasks {
+			cancelTask(e, taskUUID).Expect()
+		}
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	for _, taskUUID := range tasks {
+		statusTask(e, taskUUID).
+			Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "running")
+	}
+	e.GET("/admin/pool").
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("busy_workers", cfg.Workers+2)
+	e.GET("/metrics").
+		Expect().Status(http.StatusOK).Body().Contains("task_manager_pool_resizes_total")
+}
+
+func TestResizePoolInvalidSize(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resizePool(e, 0).
+		Expect().Status(http.StatusBadRequest)
+}
+
+func pausePool(e *httpexpect.Expect) *httpexpect.Request {
+	return e.POST("/admin/pool/pause")
+}
+
+func resumePool(e *httpexpect.Expect) *httpexpect.Request {
+	return e.POST("/admin/pool/resume")
+}
+
+func resizePool(e *httpexpect.Expect, workers int) *httpexpect.Request {
+	return e.PUT("/admin/pool").WithJSON(map[string]int{"workers": workers})
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+// These tests rely on the rate limits and the trusted loopback proxy
+// from configs/local.yml. Requests carry their own X-Forwarded-For
+// address, so they have a bucket of their own and don't throttle
+// the other tests.
+
+func TestRateLimitHeaders(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/api/v1/tasks/").WithHeader("X-Forwarded-For", "203.0.113.1").
+		Expect().Status(http.StatusOK).
+		Header("X-RateLimit-Limit").IsEqual("200")
+	e.GET("/api/v1/tasks/").WithHeader("X-Forwarded-For", "203.0.113.1").
+		Expect().Status(http.StatusOK).
+		Header("X-RateLimit-Remaining").AsNumber().Lt(200)
+}
+
+func TestReadRateLimitExceeded(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	const client = "203.0.113.2"
+	statusPath := "/api/v1/tasks/" + uuid.NewString() + "/status"
+
+	for i := range 300 {
+		resp := e.GET(statusPath).WithHeader("X-Forwarded-For", client).Expect()
+		if resp.Raw().StatusCode != http.StatusTooManyRequests {
+			resp.Status(http.StatusNotFound)
+			continue
+		}
+
+		if i < 200 {
+			t.Fatalf("request %d rejected within the burst", i)
+		}
+		resp.Header("Retry-After").AsNumber().Ge(1)
+		resp.Header("X-RateLimit-Remaining").IsEqual("0")
+		problem(resp).HasValue("code", "too_many_requests")
+
+		// The create limit is separate from the read one.
+		e.POST("/api/v1/tasks/"+uuid.NewString()+"/cancel").WithHeader("X-Forwarded-For", client).
+			Expect().Status(http.StatusNotFound).
+			Header("X-RateLimit-Limit").IsEqual(strconv.Itoa(100))
+		return
+	}
+
+	t.Fatal("read rate limit is not enforced")
+}
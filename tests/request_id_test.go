@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestRequestIDGenerated(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/api/v1/tasks/").
+		Expect().Status(http.StatusOK).
+		Header("X-Request-ID").NotEmpty()
+}
+
+func TestRequestIDPropagatedToTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	const requestID = "test-request-id-42"
+
+	var createResp createTaskResp
+	resp := e.POST("/api/v1/tasks/").WithHeader("X-Request-ID", requestID).
+		Expect().Status(http.StatusOK)
+	resp.Header("X-Request-ID").IsEqual(requestID)
+	resp.JSON().Object().Decode(&createResp)
+	t.Cleanup(func() {
+		cancelTask(e, createResp.TaskUUID).Expect()
+	})
+
+	statusTask(e, createResp.TaskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("request_id", requestID)
+}
+
+func TestInvalidRequestIDReplaced(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	tooLong := strings.Repeat("x", 129)
+
+	e.GET("/api/v1/tasks/").WithHeader("X-Request-ID", tooLong).
+		Expect().Status(http.StatusOK).
+		Header("X-Request-ID").NotEqual(tooLong).NotEmpty()
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestCanceledTaskIsNotOverwritten(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	waitHistory(e, taskUUID, "running")
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	// The worker notices the cancellation after the status is recorded,
+	// give it time to try storing another outcome.
+	time.Sleep(cfg.IoDuration + 200*time.Millisecond)
+
+	history := historyTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object()
+	history.HasValue("status", "canceled")
+	transitions := history.Value("history").Array()
+	transitions.Length().IsEqual(3)
+	transitions.Value(2).Object().
+		HasValue("from", "running").HasValue("to", "canceled").HasValue("reason", "canceled on request")
+}
+
+func TestCanceledQueuedTaskNeverRuns(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	pausePool(e).
+		Expect().Status(http.StatusOK)
+	t.Cleanup(func() {
+		resumePool(e).Expect()
+	})
+
+	taskUUID := createTask(e)
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	resumePool(e).
+		Expect().Status(http.StatusOK)
+	time.Sleep(200 * time.Millisecond)
+
+	history := historyTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object()
+	history.HasValue("status", "canceled").HasValue("execution_time", "0s")
+	transitions := history.Value("history").Array()
+	transitions.Length().IsEqual(2)
+	transitions.Value(1).Object().
+		HasValue("from", "pending").HasValue("to", "canceled").HasValue("actor", "anonymous")
+}
+
+func TestCancelFinishedTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	waitHistory(e, taskUUID, "completed")
+
+	resp := cancelTask(e, taskUUID).
+		Expect().Status(http.StatusConflict)
+	problem(resp).HasValue("code", "cant_be_canceled")
+
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "completed")
+}
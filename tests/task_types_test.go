@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+// These tests rely on the worker groups and type limits from configs/local.yml.
+
+func TestCreateTaskWithType(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTypedTask(e, "report")
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("type", "report")
+	e.GET("/admin/groups/reports").
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("type_limits", map[string]int{"report": 1})
+}
+
+func TestTypeConcurrencyLimit(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	heavy := []string{
+		createTypedTask(e, "heavy"),
+		createTypedTask(e, "heavy"),
+		createTypedTask(e, "heavy"),
+	}
+	light := createTask(e)
+	t.Cleanup(func() {
+		for _, taskUUID := range append(heavy, light) {
+			cancelTask(e, taskUUID).Expect()
+		}
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	// The third heavy task waits for a slot of its type,
+	// while the default task behind it takes the free worker.
+	statusTask(e, heavy[2]).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "pending")
+	statusTask(e, light).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "running")
+	e.GET("/admin/pool").
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("running_by_type", map[string]int{"heavy": 2, "default": 1})
+}
+
+func TestUnknownWorkerGroup(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	e.GET("/admin/groups/unknown").
+		Expect().Status(http.StatusNotFound)
+}
+
+func createTypedTask(e *httpexpect.Expect, taskType string) string {
+	var createResp createTaskResp
+
+	e.POST("/api/v1/tasks/").WithJSON(map[string]any{
+		"type":    taskType,
+		"payload": map[string]string{"source": "tests"},
+	}).
+		Expect().Status(http.StatusOK).JSON().Object().
+		ContainsKey("task_uuid").Decode(&createResp)
+
+	return createResp.TaskUUID
+}
@@ -7,11 +7,13 @@ import (
 	"time"
 
 	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type testConfig struct {
 	Port       string        `env:"PORT" env-default:"8080"`
+	GRPCPort   string        `env:"GRPC_PORT" env-default:"50051"`
 	IoDuration time.Duration `env:"TASK_DURATION" env-required:"true"`
 	Workers    int           `env:"WORKERS" env-required:"true"`
 }
@@ -43,7 +45,7 @@ func TestCreateTask(t *testing.T) {
 	statusTask(e, taskUUID).
 		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "running")
 	t.Cleanup(func() {
-		cancelTask(e, taskUUID)
+		cancelTask(e, taskUUID).Expect()
 	})
 }
 
@@ -96,7 +98,7 @@ func TestCancelCanceledTask(t *testing.T) {
 func TestCancelNonExistentTask(t *testing.T) {
 	e := httpexpect.Default(t, u.String())
 
-	cancelTask(e, "non-existing-uuid").
+	cancelTask(e, uuid.NewString()).
 		Expect().Status(http.StatusNotFound)
 }
 
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+func TestDeleteCanceledTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	deleteTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusNotFound)
+}
+
+func TestDeleteRunningTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	resp := deleteTask(e, taskUUID).
+		Expect().Status(http.StatusConflict)
+	problem(resp).HasValue("code", "cant_be_deleted")
+}
+
+func TestDeleteNonExistentTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	deleteTask(e, uuid.NewString()).
+		Expect().Status(http.StatusNotFound)
+}
+
+func TestDeleteRetriedTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	var retryResp retryTaskResp
+	retryTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Decode(&retryResp)
+	cancelTask(e, retryResp.TaskUUID).
+		Expect().Status(http.StatusOK)
+
+	deleteTask(e, retryResp.TaskUUID).
+		Expect().Status(http.StatusOK)
+
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		NotContainsKey("retries")
+}
+
+func deleteTask(e *httpexpect.Expect, taskUUID string) *httpexpect.Request {
+	return e.DELETE("/api/v1/tasks/" + taskUUID)
+}
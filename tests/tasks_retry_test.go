@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+type retryTaskResp struct {
+	TaskUUID string `json:"task_uuid"`
+	RetryOf  string `json:"retry_of"`
+}
+
+func TestRetryCanceledTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	var retryResp retryTaskResp
+	retryTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("retry_of", taskUUID).Decode(&retryResp)
+	t.Cleanup(func() {
+		cancelTask(e, retryResp.TaskUUID).Expect()
+	})
+
+	statusTask(e, retryResp.TaskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("retry_of", taskUUID)
+	statusTask(e, taskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("status", "canceled").
+		Value("retries").Array().ContainsOnly(retryResp.TaskUUID)
+}
+
+func TestRetryRunningTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	retryTask(e, taskUUID).
+		Expect().Status(http.StatusConflict)
+}
+
+func TestRetryNonExistentTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	retryTask(e, uuid.NewString()).
+		Expect().Status(http.StatusNotFound)
+}
+
+func retryTask(e *httpexpect.Expect, taskUUID string) *httpexpect.Request {
+	return e.POST("/api/v1/tasks/" + taskUUID + "/retry")
+}
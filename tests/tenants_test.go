@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+// These tests rely on the tenant limits from configs/local.yml.
+
+func TestTenantQueueLimit(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// The batch tenant queues up to five tasks. Nothing runs
+	// while the pool is paused, so only the queued limit applies.
+	pausePool(e).Expect().Status(http.StatusOK)
+	var tasks []string
+	t.Cleanup(func() {
+		for _, taskUUID := range tasks {
+			cancelTask(e, taskUUID).Expect()
+		}
+		resumePool(e).Expect()
+	})
+	for range 5 {
+		tasks = append(tasks, createTenantTask(e, "batch"))
+	}
+
+	expectRejectedTenantTask(e, "batch")
+
+	statusTask(e, tasks[0]).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("tenant", "batch").
+		HasValue("status", "pending")
+	e.GET("/metrics").
+		Expect().Status(http.StatusOK).Body().
+		Contains(`task_manager_pool_tenant_queue_length{group="default",tenant="batch"} 5`)
+}
+
+func TestTenantRunningLimit(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// The batch tenant runs one task at a time.
+	running := createTenantTask(e, "batch")
+	t.Cleanup(func() {
+		cancelTask(e, running).Expect()
+	})
+	waitHistory(e, running, "running")
+
+	expectRejectedTenantTask(e, "batch")
+
+	// Other tenants are not limited by it.
+	other := createTenantTask(e, "other")
+	cancelTask(e, other).Expect().Status(http.StatusOK)
+}
+
+// expectRejectedTenantTask checks that a task of the tenant is rejected
+// with 429 and not kept.
+func expectRejectedTenantTask(e *httpexpect.Expect, tenant string) {
+	rejected := uuid.NewString()
+	resp := e.POST("/api/v1/tasks/").WithHeader("X-Tenant-ID", tenant).
+		WithJSON(map[string]any{"labels": map[string]string{"rejected": rejected}}).
+		Expect().Status(http.StatusTooManyRequests)
+	problem(resp).HasValue("code", "too_many_requests")
+
+	e.GET("/api/v1/tasks/").WithQuery("selector", "rejected="+rejected).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("tasks").Array().IsEmpty()
+}
+
+func TestFairSchedulingAcrossTenants(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	pausePool(e).Expect().Status(http.StatusOK)
+	t.Cleanup(func() {
+		resumePool(e).Expect()
+	})
+
+	var noisy []string
+	for range cfg.Workers * 2 {
+		noisy = append(noisy, createTenantTask(e, "noisy"))
+	}
+	quiet := createTenantTask(e, "quiet")
+	t.Cleanup(func() {
+		for _, taskUUID := range append(noisy, quiet) {
+			cancelTask(e, taskUUID).Expect()
+		}
+	})
+
+	resumePool(e).Expect().Status(http.StatusOK)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// The quiet tenant does not wait behind all tasks of the noisy one.
+	statusTask(e, quiet).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "running")
+	statusTask(e, noisy[len(noisy)-1]).
+		Expect().Status(http.StatusOK).JSON().Object().HasValue("status", "pending")
+}
+
+func createTenantTask(e *httpexpect.Expect, tenant string) string {
+	var createResp createTaskResp
+
+	e.POST("/api/v1/tasks/").WithHeader("X-Tenant-ID", tenant).
+		Expect().Status(http.StatusOK).JSON().Object().
+		ContainsKey("task_uuid").Decode(&createResp)
+
+	return createResp.TaskUUID
+}
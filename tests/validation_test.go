@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestInvalidTaskUUID(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	for _, req := range []*httpexpect.Request{
+		statusTask(e, "not-a-uuid"),
+		cancelTask(e, "not-a-uuid"),
+		e.POST("/api/v1/tasks/not-a-uuid/retry"),
+	} {
+		resp := req.Expect().Status(http.StatusBadRequest)
+		problem(resp).HasValue("code", "validation_failed").
+			Value("errors").Array().Value(0).Object().
+			HasValue("field", "uuid").
+			HasValue("reason", "must be a valid UUID")
+	}
+}
+
+func TestUnknownBodyField(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resp := e.POST("/api/v1/tasks/").WithJSON(map[string]any{"type": "report", "deadline": 1}).
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).Value("errors").Array().Value(0).Object().
+		HasValue("field", "deadline").
+		HasValue("reason", "is not allowed")
+
+	resp = e.PUT("/admin/pool").WithJSON(map[string]any{"workers": 2, "extra": true}).
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "extra")
+}
+
+func TestMalformedBody(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	for _, body := range []string{`{"type":`, `{} {}`, `[]`} {
+		resp := e.POST("/api/v1/tasks/").WithHeader("Content-Type", "application/json").WithText(body).
+			Expect().Status(http.StatusBadRequest)
+		problem(resp).HasValue("code", "validation_failed").
+			Value("errors").Array().Value(0).Object().HasValue("field", "body")
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	payload := strings.Repeat("x", 1<<20)
+	resp := e.POST("/api/v1/tasks/").WithJSON(map[string]any{"payload": payload}).
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "body")
+}
+
+func TestInvalidQuery(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resp := e.GET("/api/v1/tasks/").WithQuery("limit", "1001").
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).Value("errors").Array().Value(0).Object().HasValue("field", "limit")
+}
@@ -0,0 +1,239 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// wsMessage holds the fields of every message the server sends.
+type wsMessage struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	TaskUUID  string `json:"task_uuid"`
+	Status    string `json:"status"`
+	Version   uint64 `json:"version"`
+	Progress  *int   `json:"progress"`
+	Message   string `json:"message"`
+	Code      string `json:"code"`
+	Detail    string `json:"detail"`
+}
+
+func dialWS(t *testing.T) *websocket.Conn {
+	t.Helper()
+
+	wsURL := url.URL{Scheme: "ws", Host: u.Host, Path: "/api/v1/ws"}
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL.String(), nil)
+	if err != nil {
+		t.Fatalf("failed to connect to WebSocket endpoint: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func sendWS(t *testing.T, conn *websocket.Conn, msg map[string]any) {
+	t.Helper()
+
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatalf("failed to send message: %v", err)
+	}
+}
+
+// readWS returns the next message accepted by match,
+// skipping the others, and fails the test after timeout.
+func readWS(t *testing.T, conn *websocket.Conn, timeout time.Duration, match func(wsMessage) bool) wsMessage {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		if match(msg) {
+			return msg
+		}
+	}
+}
+
+func reply(id string) func(wsMessage) bool {
+	return func(msg wsMessage) bool { return msg.ID == id }
+}
+
+func TestWSCreateAndFollowTask(t *testing.T) {
+	conn := dialWS(t)
+
+	sendWS(t, conn, map[string]any{"id": "1", "type": "create", "payload": map[string]any{"report": "daily"}})
+	created := readWS(t, conn, time.Second, reply("1"))
+	if created.Type != "ack" || created.TaskUUID == "" {
+		t.Fatalf("got reply %+v, want ack with task uuid", created)
+	}
+
+	sendWS(t, conn, map[string]any{"id": "2", "type": "subscribe", "uuid": created.TaskUUID})
+	subscribed := readWS(t, conn, time.Second, reply("2"))
+	if subscribed.Type != "ack" || subscribed.Status == "" || subscribed.Version == 0 {
+		t.Fatalf("got reply %+v, want ack with task status and version", subscribed)
+	}
+
+	var progress, logs int
+	completed := readWS(t, conn, cfg.IoDuration*10, func(msg wsMessage) bool {
+		if msg.TaskUUID != created.TaskUUID {
+			return false
+		}
+		switch msg.Type {
+		case "progress":
+			progress++
+		case "log":
+			logs++
+		}
+		return msg.Type == "status" && msg.Status == "completed"
+	})
+
+	if completed.Version <= subscribed.Version {
+		t.Errorf("completed event has version %d, want above %d of the subscription", completed.Version, subscribed.Version)
+	}
+
+	if progress == 0 {
+		t.Error("no progress events received")
+	}
+	if logs == 0 {
+		t.Error("no log events received")
+	}
+}
+
+func TestWSCancelSubscribedTask(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+	conn := dialWS(t)
+
+	taskUUID := createTask(e)
+
+	sendWS(t, conn, map[string]any{"id": "sub", "type": "subscribe", "uuid": taskUUID})
+	readWS(t, conn, time.Second, reply("sub"))
+
+	// The canceled status is stored before the reply is sent,
+	// so the event may come first.
+	sendWS(t, conn, map[string]any{"id": "cancel", "type": "cancel", "uuid": taskUUID})
+	var acked, canceled bool
+	readWS(t, conn, time.Second, func(msg wsMessage) bool {
+		if reply("cancel")(msg) {
+			if msg.Type != "ack" {
+				t.Fatalf("got reply %+v, want ack", msg)
+			}
+			acked = true
+		}
+		if msg.Type == "status" && msg.TaskUUID == taskUUID && msg.Status == "canceled" {
+			canceled = true
+		}
+		return acked && canceled
+	})
+
+	sendWS(t, conn, map[string]any{"id": "again", "type": "cancel", "uuid": taskUUID})
+	again := readWS(t, conn, time.Second, reply("again"))
+	if again.Type != "error" || again.Code != "cant_be_canceled" {
+		t.Errorf("got reply %+v, want cant_be_canceled error", again)
+	}
+}
+
+func TestWSUnsubscribe(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+	conn := dialWS(t)
+
+	taskUUID := createTask(e)
+	t.Cleanup(func() {
+		cancelTask(e, taskUUID).Expect()
+	})
+
+	sendWS(t, conn, map[string]any{"id": "sub", "type": "subscribe", "uuid": taskUUID})
+	readWS(t, conn, time.Second, reply("sub"))
+	sendWS(t, conn, map[string]any{"id": "unsub", "type": "unsubscribe", "uuid": taskUUID})
+	readWS(t, conn, time.Second, reply("unsub"))
+
+	cancelTask(e, taskUUID).
+		Expect().Status(http.StatusOK)
+
+	// The connection keeps answering, but no longer sends events of the task.
+	sendWS(t, conn, map[string]any{"id": "probe", "type": "unsubscribe", "uuid": taskUUID})
+	readWS(t, conn, time.Second, func(msg wsMessage) bool {
+		if msg.TaskUUID == taskUUID && msg.Type != "ack" {
+			t.Errorf("got event %+v after unsubscribing", msg)
+		}
+		return msg.ID == "probe"
+	})
+}
+
+func TestWSCreateWithLabelsAndPriority(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+	conn := dialWS(t)
+
+	sendWS(t, conn, map[string]any{
+		"id":          "1",
+		"type":        "create",
+		"priority":    10,
+		"labels":      map[string]any{"env": "prod"},
+		"description": "nightly report",
+	})
+	created := readWS(t, conn, time.Second, reply("1"))
+	if created.Type != "ack" || created.TaskUUID == "" {
+		t.Fatalf("got reply %+v, want ack with task uuid", created)
+	}
+	t.Cleanup(func() {
+		cancelTask(e, created.TaskUUID).Expect()
+	})
+
+	statusTask(e, created.TaskUUID).
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("priority", 10).
+		HasValue("labels", map[string]string{"env": "prod"}).
+		HasValue("description", "nightly report")
+}
+
+func TestWSInvalidMessages(t *testing.T) {
+	conn := dialWS(t)
+
+	tests := []struct {
+		name string
+		msg  map[string]any
+		code string
+	}{
+		{"unknown task", map[string]any{"type": "subscribe", "uuid": uuid.NewString()}, "not_found"},
+		{"invalid uuid", map[string]any{"type": "cancel", "uuid": "not-a-uuid"}, "validation_failed"},
+		{"invalid namespace", map[string]any{"type": "subscribe", "uuid": uuid.NewString(), "namespace": "Bad_NS"}, "validation_failed"},
+		{"unknown type", map[string]any{"type": "explode"}, "validation_failed"},
+		{"priority out of range", map[string]any{"type": "create", "priority": 101}, "validation_failed"},
+		{"invalid label", map[string]any{"type": "create", "labels": map[string]any{"-env": "prod"}}, "validation_failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.msg["id"] = tt.name
+			sendWS(t, conn, tt.msg)
+
+			got := readWS(t, conn, time.Second, reply(tt.name))
+			if got.Type != "error" || got.Code != tt.code {
+				t.Errorf("got reply %+v, want %s error", got, tt.code)
+			}
+		})
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+		t.Fatalf("failed to send message: %v", err)
+	}
+	got := readWS(t, conn, time.Second, func(wsMessage) bool { return true })
+	if got.Type != "error" || got.Code != "validation_failed" {
+		t.Errorf("got reply %+v, want validation_failed error", got)
+	}
+}
+
+func TestWSRequiresUpgrade(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	resp := e.GET("/api/v1/ws").
+		Expect().Status(http.StatusBadRequest)
+	problem(resp).HasValue("code", "invalid_request_parameters")
+}